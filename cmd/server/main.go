@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 
 	"github.com/shibaleo/go-mcp-dev/internal/auth"
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
 	"github.com/shibaleo/go-mcp-dev/internal/mcp"
 	"github.com/shibaleo/go-mcp-dev/internal/modules"
 	"github.com/shibaleo/go-mcp-dev/internal/modules/airtable"
@@ -21,25 +24,30 @@ func main() {
 	// Initialize Loki client
 	observability.Init()
 
-	// Register modules
-	modules.Register(supabase.Module())
-	modules.Register(notion.Module())
-	modules.Register(github.Module())
-	modules.Register(jira.Module())
-	modules.Register(confluence.Module())
-	modules.Register(airtable.Module())
+	// Register modules and refuse to start on an inconsistent registry
+	registry := modules.NewRegistry()
+	if err := registerModules(registry); err != nil {
+		log.Fatalf("Invalid module registry:\n%v", err)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	handler := mcp.NewHandler()
+	exposure, err := mcp.ParseExposureMode(os.Getenv("TOOL_EXPOSURE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handler := mcp.NewHandler(registry, mcp.WithExposureMode(exposure))
 
 	authMiddleware := auth.NewMiddleware(os.Getenv("INTERNAL_SECRET"))
 
 	http.HandleFunc("/health", healthHandler)
 	http.Handle("/mcp", authMiddleware(handler))
+	http.Handle("/admin/sessions", authMiddleware(handler.SessionsHandler()))
+	http.Handle("/admin/cache", authMiddleware(http.HandlerFunc(cacheStatsHandler)))
 
 	log.Printf("Starting MCP server on :%s", port)
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
@@ -47,7 +55,37 @@ func main() {
 	}
 }
 
+// registerModules adds every module the server exposes to the registry
+func registerModules(registry *modules.Registry) error {
+	var errs []error
+	for _, module := range []modules.ModuleDefinition{
+		supabase.Module(),
+		notion.Module(),
+		github.Module(),
+		jira.Module(),
+		confluence.Module(),
+		airtable.Module(),
+	} {
+		if err := registry.Register(module); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"status":"ok"}`))
 }
+
+// cacheStatsHandler reports the hits and misses of the upstream response caches
+func cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"caches": httpclient.AllCacheStats(),
+	})
+}
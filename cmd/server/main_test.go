@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+func TestRegisteredModulesAreConsistent(t *testing.T) {
+	registry := modules.NewRegistry()
+	if err := registerModules(registry); err != nil {
+		t.Fatalf("module registry is inconsistent:\n%v", err)
+	}
+
+	// The meta tools advertise exactly the registered modules
+	catalog := registry.MetaTools()[1].Description
+	for _, module := range registry.Modules() {
+		if !strings.Contains(catalog, "- "+module.Name+": ") {
+			t.Errorf("call_module_tool description does not list %s", module.Name)
+		}
+	}
+}
+
+func TestCacheStatsListUpstreamCaches(t *testing.T) {
+	rec := httptest.NewRecorder()
+	cacheStatsHandler(rec, httptest.NewRequest(http.MethodGet, "/admin/cache", nil))
+
+	var body struct {
+		Caches map[string]httpclient.CacheStats `json:"caches"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	for _, upstream := range []string{"github", "notion", "jira", "airtable"} {
+		if _, ok := body.Caches[upstream]; !ok {
+			t.Errorf("no cache stats for %s: %s", upstream, rec.Body.String())
+		}
+	}
+}
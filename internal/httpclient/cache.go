@@ -0,0 +1,229 @@
+package httpclient
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachePolicy configures the response cache of a client
+type CachePolicy struct {
+	// TTL is how long a response is served without asking the server; after
+	// it, responses with an ETag or Last-Modified are revalidated
+	TTL time.Duration
+	// MaxEntries bounds the cache; the least recently used entry goes first
+	MaxEntries int
+}
+
+// DefaultCachePolicy suits metadata that rarely changes within a session
+var DefaultCachePolicy = CachePolicy{TTL: time.Minute, MaxEntries: 256}
+
+// CacheStats counts how a client's cache served requests
+type CacheStats struct {
+	// Hits were served from the cache without a request
+	Hits int64 `json:"hits"`
+	// Revalidated were confirmed unchanged by a 304
+	Revalidated int64 `json:"revalidated"`
+	// Misses were fetched in full
+	Misses int64 `json:"misses"`
+	// Invalidated entries were dropped after a write to their resource
+	Invalidated int64 `json:"invalidated"`
+	// Evicted entries were dropped to stay within MaxEntries
+	Evicted int64 `json:"evicted"`
+	Entries int   `json:"entries"`
+}
+
+// WithCache gives the client a response cache. Only GET requests made with
+// a context marked by Cacheable use it; successful writes through the
+// client invalidate the entries of the resource they hit, unless they are
+// marked Idempotent.
+func WithCache(policy CachePolicy) Option {
+	return func(c *Client) {
+		c.cache = newResponseCache(policy)
+	}
+}
+
+type cacheableKey struct{}
+
+// Cacheable marks GET requests made with the returned context as safe to
+// answer from the client's cache, e.g. reads of schemas or project lists
+func Cacheable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheableKey{}, true)
+}
+
+func isCacheable(ctx context.Context) bool {
+	marked, _ := ctx.Value(cacheableKey{}).(bool)
+	return marked
+}
+
+var caches = struct {
+	sync.Mutex
+	byUpstream map[string]*responseCache
+}{byUpstream: map[string]*responseCache{}}
+
+// AllCacheStats returns the stats of every client cache by upstream name
+func AllCacheStats() map[string]CacheStats {
+	caches.Lock()
+	defer caches.Unlock()
+	stats := make(map[string]CacheStats, len(caches.byUpstream))
+	for upstream, cache := range caches.byUpstream {
+		stats[upstream] = cache.stats()
+	}
+	return stats
+}
+
+type responseCache struct {
+	policy CachePolicy
+
+	mu      sync.Mutex
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+	// generation counts invalidations, so a read that raced with a write
+	// does not store what it fetched
+	generation uint64
+
+	hits, revalidated, misses, invalidated, evicted atomic.Int64
+}
+
+type cacheEntry struct {
+	key      string
+	url      *url.URL
+	body     []byte
+	header   http.Header
+	storedAt time.Time
+}
+
+func newResponseCache(policy CachePolicy) *responseCache {
+	return &responseCache{policy: policy, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// credential identifies the caller of a request without keeping the secret
+func credential(authorization string) string {
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (rc *responseCache) get(key string) (*cacheEntry, uint64, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	elem, ok := rc.entries[key]
+	if !ok {
+		return nil, rc.generation, false
+	}
+	rc.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry), rc.generation, true
+}
+
+func (rc *responseCache) put(entry *cacheEntry, generation uint64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if generation != rc.generation {
+		return
+	}
+	if elem, ok := rc.entries[entry.key]; ok {
+		elem.Value = entry
+		rc.order.MoveToFront(elem)
+		return
+	}
+	rc.entries[entry.key] = rc.order.PushFront(entry)
+	for rc.policy.MaxEntries > 0 && rc.order.Len() > rc.policy.MaxEntries {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+		rc.evicted.Add(1)
+	}
+}
+
+// invalidate drops the entries of the resource a write hit: the same path,
+// the resources below it, and the ones it is nested in, for any credential
+func (rc *responseCache) invalidate(target *url.URL) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.generation++
+	for key, elem := range rc.entries {
+		cached := elem.Value.(*cacheEntry).url
+		if cached.Host == target.Host && relatedPaths(cached.Path, target.Path) {
+			rc.order.Remove(elem)
+			delete(rc.entries, key)
+			rc.invalidated.Add(1)
+		}
+	}
+}
+
+func relatedPaths(a, b string) bool {
+	a, b = strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/")
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+func (rc *responseCache) stats() CacheStats {
+	rc.mu.Lock()
+	entries := rc.order.Len()
+	rc.mu.Unlock()
+	return CacheStats{
+		Hits:        rc.hits.Load(),
+		Revalidated: rc.revalidated.Load(),
+		Misses:      rc.misses.Load(),
+		Invalidated: rc.invalidated.Load(),
+		Evicted:     rc.evicted.Load(),
+		Entries:     entries,
+	}
+}
+
+// cachedGet answers a GET from the cache while it is fresh, revalidates it
+// with If-None-Match or If-Modified-Since once stale, and fetches it otherwise
+func (c *Client) cachedGet(ctx context.Context, rawURL string, headers map[string]string) ([]byte, http.Header, error) {
+	rc := c.cache
+	key := "GET " + rawURL + " " + credential(headers["Authorization"])
+	now := time.Now()
+
+	entry, generation, ok := rc.get(key)
+	if ok && now.Sub(entry.storedAt) < rc.policy.TTL {
+		rc.hits.Add(1)
+		return entry.body, entry.header, nil
+	}
+
+	conditional := headers
+	if ok {
+		conditional = make(map[string]string, len(headers)+2)
+		for k, v := range headers {
+			conditional[k] = v
+		}
+		if etag := entry.header.Get("ETag"); etag != "" {
+			conditional["If-None-Match"] = etag
+		}
+		if modified := entry.header.Get("Last-Modified"); modified != "" {
+			conditional["If-Modified-Since"] = modified
+		}
+	}
+
+	respBody, header, err := c.fetch(ctx, http.MethodGet, rawURL, conditional, nil)
+	var apiErr *APIError
+	if ok && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		// Entries are shared with concurrent readers, so the TTL restarts
+		// on a copy
+		refreshed := *entry
+		refreshed.storedAt = time.Now()
+		rc.put(&refreshed, generation)
+		rc.revalidated.Add(1)
+		return entry.body, entry.header, nil
+	}
+	rc.misses.Add(1)
+	if err != nil {
+		return nil, header, err
+	}
+
+	if !strings.Contains(header.Get("Cache-Control"), "no-store") {
+		if u, perr := url.Parse(rawURL); perr == nil {
+			rc.put(&cacheEntry{key: key, url: u, body: respBody, header: header, storedAt: time.Now()}, generation)
+		}
+	}
+	return respBody, header, nil
+}
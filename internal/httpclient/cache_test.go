@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// etagServer serves a versioned document with an ETag and answers
+// If-None-Match with 304; writes bump the version
+func etagServer(t *testing.T, fulls, notModified *int32) *httptest.Server {
+	t.Helper()
+	var version int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			atomic.AddInt32(&version, 1)
+			return
+		}
+		etag := `"v` + strconv.Itoa(int(atomic.LoadInt32(&version))) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(fulls, 1)
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"version":` + strconv.Itoa(int(atomic.LoadInt32(&version))) + `,"auth":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *Client, ctx context.Context, url string, token string) string {
+	t.Helper()
+	body, err := client.DoJSON(ctx, "GET", url, map[string]string{"Authorization": token}, nil)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	return string(body)
+}
+
+func TestCache_FreshHitsSkipTheServer(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}))
+	ctx := Cacheable(context.Background())
+
+	first := get(t, client, ctx, server.URL+"/repo", "a")
+	if second := get(t, client, ctx, server.URL+"/repo", "a"); second != first {
+		t.Errorf("cached body %q, want %q", second, first)
+	}
+	if fulls != 1 {
+		t.Errorf("server saw %d requests, want 1", fulls)
+	}
+	if stats := client.cache.stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCache_OnlyMarkedRequests(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}))
+
+	get(t, client, context.Background(), server.URL, "a")
+	get(t, client, context.Background(), server.URL, "a")
+	if fulls != 2 {
+		t.Errorf("server saw %d requests, want 2", fulls)
+	}
+}
+
+func TestCache_KeyedByCredential(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}))
+	ctx := Cacheable(context.Background())
+
+	get(t, client, ctx, server.URL, "a")
+	if body := get(t, client, ctx, server.URL, "b"); body != `{"version":1,"auth":"b"}` {
+		t.Errorf("token b got %s", body)
+	}
+	if fulls != 2 {
+		t.Errorf("server saw %d requests, want 2", fulls)
+	}
+}
+
+func TestCache_RevalidatesWithETag(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: 0, MaxEntries: 10}))
+	ctx := Cacheable(context.Background())
+
+	first := get(t, client, ctx, server.URL, "a")
+	if second := get(t, client, ctx, server.URL, "a"); second != first {
+		t.Errorf("revalidated body %q, want %q", second, first)
+	}
+	if fulls != 1 || notModified != 1 {
+		t.Errorf("full responses = %d, 304s = %d", fulls, notModified)
+	}
+	if stats := client.cache.stats(); stats.Revalidated != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCache_WritesInvalidateTheResource(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}))
+	ctx := Cacheable(context.Background())
+
+	get(t, client, ctx, server.URL+"/repos/o/r", "a")
+	get(t, client, ctx, server.URL+"/repos/o/other", "a")
+	if _, err := client.DoJSON(context.Background(), "POST", server.URL+"/repos/o/r/issues", nil, map[string]string{"title": "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := get(t, client, ctx, server.URL+"/repos/o/r", "a"); body != `{"version":2,"auth":"a"}` {
+		t.Errorf("after a write got %s", body)
+	}
+	if body := get(t, client, ctx, server.URL+"/repos/o/other", "a"); body != `{"version":1,"auth":"a"}` {
+		t.Errorf("unrelated resource was refetched: %s", body)
+	}
+	if stats := client.cache.stats(); stats.Invalidated != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCache_IdempotentPostsDoNotInvalidate(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}))
+	ctx := Cacheable(context.Background())
+
+	get(t, client, ctx, server.URL+"/v1/databases/db", "a")
+	if _, err := client.DoJSON(Idempotent(context.Background()), "POST", server.URL+"/v1/databases/db/query", nil, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	get(t, client, ctx, server.URL+"/v1/databases/db", "a")
+	if stats := client.cache.stats(); stats.Hits != 1 || stats.Invalidated != 0 {
+		t.Errorf("a query evicted its database: stats = %+v", stats)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	var fulls, notModified int32
+	server := etagServer(t, &fulls, &notModified)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 2}))
+	ctx := Cacheable(context.Background())
+
+	get(t, client, ctx, server.URL+"/a", "t")
+	get(t, client, ctx, server.URL+"/b", "t")
+	get(t, client, ctx, server.URL+"/a", "t") // a is now the most recent
+	get(t, client, ctx, server.URL+"/c", "t") // evicts b
+
+	fulls = 0
+	get(t, client, ctx, server.URL+"/a", "t")
+	get(t, client, ctx, server.URL+"/b", "t")
+	if fulls != 1 {
+		t.Errorf("expected only b to be refetched, server saw %d requests", fulls)
+	}
+	if stats := client.cache.stats(); stats.Evicted != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusNotFound)
+	client := New(WithCache(CachePolicy{TTL: time.Minute, MaxEntries: 10}), WithRetryPolicy(NoRetry))
+	ctx := Cacheable(context.Background())
+
+	if _, err := client.DoJSON(ctx, "GET", server.URL, nil, nil); err == nil {
+		t.Fatal("expected the 404")
+	}
+	get(t, client, ctx, server.URL, "")
+	if hits != 2 {
+		t.Errorf("server saw %d requests, want 2", hits)
+	}
+}
+
+func TestCache_StatsByUpstream(t *testing.T) {
+	New(WithUpstream(t.Name()), WithCache(DefaultCachePolicy))
+	if _, ok := AllCacheStats()[t.Name()]; !ok {
+		t.Error("cache not reported")
+	}
+}
@@ -2,25 +2,47 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 // Client is a shared HTTP client with common configuration
 type Client struct {
 	httpClient *http.Client
+	// streamClient has no overall timeout, so DoStream bodies can take as
+	// long as the caller's ctx allows
+	streamClient *http.Client
+	retry        RetryPolicy
+	upstream     string
+	cache        *responseCache
 }
 
-// New creates a new HTTP client with sensible defaults
-func New() *Client {
-	return &Client{
+// New creates a new HTTP client with sensible defaults: a 30s timeout per
+// attempt and DefaultRetryPolicy
+func New(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		retry: DefaultRetryPolicy,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.streamClient = &http.Client{Transport: headerTimeoutTransport(c.httpClient.Timeout)}
+	if c.cache != nil && c.upstream != "" {
+		caches.Lock()
+		caches.byUpstream[c.upstream] = c.cache
+		caches.Unlock()
+	}
+	return c
 }
 
 // APIError represents an error response from an external API
@@ -33,49 +55,176 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
 }
 
-// DoJSON performs an HTTP request and returns the response body
-func (c *Client) DoJSON(method, url string, headers map[string]string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
+// DoJSON performs an HTTP request and returns the response body.
+// The request is aborted when ctx is cancelled. Failed attempts are retried
+// according to the client's RetryPolicy.
+func (c *Client) DoJSON(ctx context.Context, method, url string, headers map[string]string, body interface{}) ([]byte, error) {
+	respBody, _, err := c.do(ctx, method, url, headers, body)
+	return respBody, err
+}
+
+// do is DoJSON that also returns the headers of the final response
+func (c *Client) do(ctx context.Context, method, rawURL string, headers map[string]string, body interface{}) ([]byte, http.Header, error) {
+	if c.cache != nil && method == http.MethodGet && isCacheable(ctx) {
+		return c.cachedGet(ctx, rawURL, headers)
+	}
+
+	respBody, header, err := c.fetch(ctx, method, rawURL, headers, body)
+	if err == nil {
+		c.invalidate(ctx, method, rawURL)
+	}
+	return respBody, header, err
+}
+
+// invalidate drops the cached entries of the resource a successful write
+// hit. Requests marked Idempotent are reads sent with another method, like
+// Notion's database queries, and leave the cache alone.
+func (c *Client) invalidate(ctx context.Context, method, rawURL string) {
+	if c.cache == nil || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return
+	}
+	if marked, _ := ctx.Value(idempotentKey{}).(bool); marked {
+		return
+	}
+	if target, err := url.Parse(rawURL); err == nil {
+		c.cache.invalidate(target)
+	}
+}
+
+// fetch makes a JSON request with retries
+func (c *Client) fetch(ctx context.Context, method, url string, headers map[string]string, body interface{}) ([]byte, http.Header, error) {
+	req := Request{Method: method, URL: url, Headers: headers}
 	if body != nil {
 		jsonBytes, err := json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonBytes)
+		req.Body = bytes.NewReader(jsonBytes)
+		req.ContentType = "application/json"
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	resp, err := c.read(ctx, req)
+	if resp == nil {
+		return nil, nil, err
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, resp.Header, err
 	}
+	return resp.Body, resp.Header, nil
+}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+// policy returns the retry policy for req: requests that are not idempotent,
+// or whose body cannot be sent again, get a single attempt
+func (c *Client) policy(ctx context.Context, req Request) RetryPolicy {
+	if !isIdempotent(ctx, req.Method) {
+		return NoRetry
 	}
+	if _, ok := req.Body.(io.Seeker); req.Body != nil && !ok {
+		return NoRetry
+	}
+	return c.retry
+}
 
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+// send makes req with retries. accept is given every response the server
+// answers with: it returns nil to hand the response over with its body
+// open, or an error after closing the body. resp is set whenever the server
+// answered.
+func (c *Client) send(ctx context.Context, hc *http.Client, policy RetryPolicy, req Request, accept func(*http.Response) error) (*http.Response, error) {
+	seeker, _ := req.Body.(io.Seeker)
+	var start int64
+	if seeker != nil {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, hc, req)
+		if err == nil {
+			err = accept(resp)
+		}
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrResponseTooLarge) {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if !retryable(resp) {
+				return resp, err
+			}
+			if wait, ok := serverDelay(resp.Header, time.Now()); ok {
+				delay = wait
+				if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+					return resp, err
+				}
+			}
+		}
+		if !fitsDeadline(ctx, delay) {
+			return resp, err
+		}
+
+		log.Printf("Retrying %s %s in %v (attempt %d/%d): %v", req.Method, req.URL, delay.Round(time.Millisecond), attempt+1, policy.MaxAttempts, err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if seeker != nil {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	respBody, err := io.ReadAll(resp.Body)
+// attempt makes one request and returns the response with its body unread
+func (c *Client) attempt(ctx context.Context, hc *http.Client, r Request) (*http.Response, error) {
+	body := r.Body
+	if _, ok := body.(io.Closer); ok {
+		// The caller owns the body, and retries send it again
+		body = io.NopCloser(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Body:       string(respBody),
+	for key, value := range r.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if r.ContentType != "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	limiter := limiterFor(c.upstream)
+	var limitKey string
+	if limiter != nil {
+		limitKey = limiter.key(req)
+		if err := limiter.wait(ctx, limitKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.upstream, err)
 		}
 	}
 
-	return respBody, nil
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if limiter != nil {
+		limiter.observe(limitKey, resp.Header, time.Now())
+	}
+	return resp, nil
+}
+
+// headerTimeoutTransport returns a transport that waits at most timeout for
+// the response headers
+func headerTimeoutTransport(timeout time.Duration) http.RoundTripper {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+	transport = transport.Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return transport
 }
 
 // PrettyJSON formats JSON response for display
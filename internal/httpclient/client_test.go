@@ -1,9 +1,12 @@
 package httpclient
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestDoJSON_Success(t *testing.T) {
@@ -23,7 +26,7 @@ func TestDoJSON_Success(t *testing.T) {
 	headers := map[string]string{"Authorization": "Bearer test-token"}
 	body := map[string]string{"key": "value"}
 
-	resp, err := client.DoJSON("POST", server.URL, headers, body)
+	resp, err := client.DoJSON(context.Background(), "POST", server.URL, headers, body)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -41,7 +44,7 @@ func TestDoJSON_APIError(t *testing.T) {
 	defer server.Close()
 
 	client := New()
-	_, err := client.DoJSON("GET", server.URL, nil, nil)
+	_, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil)
 
 	if err == nil {
 		t.Fatal("expected error, got nil")
@@ -68,7 +71,7 @@ func TestDoJSON_GetWithoutBody(t *testing.T) {
 	defer server.Close()
 
 	client := New()
-	resp, err := client.DoJSON("GET", server.URL, nil, nil)
+	resp, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -99,3 +102,30 @@ func TestPrettyJSON_InvalidJSON(t *testing.T) {
 		t.Errorf("expected original string, got: %s", result)
 	}
 }
+
+func TestDoJSON_ContextCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	client := New()
+	_, err := client.DoJSON(ctx, "GET", server.URL, nil, nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
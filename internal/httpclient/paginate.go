@@ -0,0 +1,273 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Safety caps of Paginate, whatever the caller asks for
+const (
+	MaxPagedItems = 1000
+	MaxPages      = 50
+)
+
+// PageRequest is the request for one page of a listing. Body is nil for
+// requests without one; the next page's cursor goes into the query string
+// then, and into Body otherwise.
+type PageRequest struct {
+	Method string
+	URL    string
+	Body   map[string]interface{}
+}
+
+// WithParam returns req with the pagination parameter name set to value
+func (req PageRequest) WithParam(name, value string) PageRequest {
+	if req.Body != nil {
+		body := make(map[string]interface{}, len(req.Body)+1)
+		for k, v := range req.Body {
+			body[k] = v
+		}
+		body[name] = value
+		req.Body = body
+		return req
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return req
+	}
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	req.URL = u.String()
+	return req
+}
+
+// Paginator describes how an API splits a listing into pages
+type Paginator struct {
+	// Items names the field that holds a page's items; empty when the page
+	// is a JSON array
+	Items string
+	// Next returns the request for the page after page, or false on the
+	// last page. page is nil for array pages.
+	Next func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool)
+	// Cursors names the fields of a page that point at the page after it.
+	// Paginate drops them from a page it cuts short, since resuming from
+	// them would skip the items it left out.
+	Cursors []string
+}
+
+var linkNext = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="next"`)
+
+// LinkHeader follows the rel="next" URL of the Link header, as GitHub sends it
+func LinkHeader(items string) Paginator {
+	return Paginator{Items: items, Next: func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool) {
+		match := linkNext.FindStringSubmatch(header.Get("Link"))
+		if match == nil {
+			return req, false
+		}
+		req.URL = match[1]
+		return req, true
+	}}
+}
+
+// NextCursor passes next_cursor back as start_cursor while has_more is set,
+// as Notion does
+func NextCursor(items string) Paginator {
+	return Paginator{Items: items, Next: func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool) {
+		var hasMore bool
+		var cursor string
+		json.Unmarshal(page["has_more"], &hasMore)
+		json.Unmarshal(page["next_cursor"], &cursor)
+		if !hasMore || cursor == "" {
+			return req, false
+		}
+		return req.WithParam("start_cursor", cursor), true
+	}, Cursors: []string{"next_cursor"}}
+}
+
+// OffsetToken passes the offset of a page back until a page has none, as
+// Airtable does
+func OffsetToken(items string) Paginator {
+	return Paginator{Items: items, Next: func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool) {
+		var offset string
+		json.Unmarshal(page["offset"], &offset)
+		if offset == "" {
+			return req, false
+		}
+		return req.WithParam("offset", offset), true
+	}, Cursors: []string{"offset"}}
+}
+
+// JiraPages follows nextPageToken where Jira sends one, as the JQL search
+// does, and otherwise advances startAt until isLast or total is reached
+func JiraPages(items string) Paginator {
+	return Paginator{Items: items, Next: func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool) {
+		var isLast bool
+		if json.Unmarshal(page["isLast"], &isLast) == nil && isLast {
+			return req, false
+		}
+		var token string
+		if json.Unmarshal(page["nextPageToken"], &token) == nil && token != "" {
+			return req.WithParam("nextPageToken", token), true
+		}
+		if _, ok := page["nextPageToken"]; ok || page["startAt"] == nil {
+			return req, false
+		}
+
+		var startAt, total int
+		var list []json.RawMessage
+		json.Unmarshal(page["startAt"], &startAt)
+		json.Unmarshal(page[items], &list)
+		next := startAt + len(list)
+		if len(list) == 0 || (json.Unmarshal(page["total"], &total) == nil && next >= total) {
+			return req, false
+		}
+		return req.WithParam("startAt", strconv.Itoa(next)), true
+	}, Cursors: []string{"nextPageToken", "startAt"}}
+}
+
+// LinksNext follows _links.next, as Confluence sends it. The link is
+// relative to _links.base, except when it already starts with the base's
+// path as the v2 API's links do.
+func LinksNext(items string) Paginator {
+	return Paginator{Items: items, Next: func(req PageRequest, header http.Header, page map[string]json.RawMessage) (PageRequest, bool) {
+		var links struct {
+			Next string `json:"next"`
+			Base string `json:"base"`
+		}
+		json.Unmarshal(page["_links"], &links)
+		if links.Next == "" {
+			return req, false
+		}
+		current, err := url.Parse(req.URL)
+		if err != nil {
+			return req, false
+		}
+		base, err := url.Parse(links.Base)
+		if err != nil || links.Base == "" {
+			base = &url.URL{Scheme: current.Scheme, Host: current.Host}
+		}
+		path := links.Next
+		if base.Path != "" && !strings.HasPrefix(path, base.Path+"/") {
+			path = strings.TrimSuffix(base.Path, "/") + path
+		}
+		next, err := current.Parse(path)
+		if err != nil {
+			return req, false
+		}
+		req.URL = next.String()
+		return req, true
+	}, Cursors: []string{"_links"}}
+}
+
+// Paging asks Paginate to collect several pages. Paginate records what it
+// fetched in Pages, Items and Truncated.
+type Paging struct {
+	// MaxItems caps the collected items; it is bounded by MaxPagedItems
+	MaxItems int
+	// Progress, when set, is called after every page
+	Progress func(items, pages int)
+
+	Pages     int
+	Items     int
+	Truncated bool
+}
+
+type pagingKey struct{}
+
+// WithPaging makes Paginate calls under the returned context collect pages
+// as paging says
+func WithPaging(ctx context.Context, paging *Paging) context.Context {
+	return context.WithValue(ctx, pagingKey{}, paging)
+}
+
+// Paginate fetches a listing. Without Paging in ctx it returns the first
+// page unchanged. With Paging it follows p until the listing ends or the
+// item cap is hit, and returns the items of all pages merged into the last
+// page, or into one array for array pages. When the item cap cuts a page
+// short, its Cursors are dropped.
+func (c *Client) Paginate(ctx context.Context, req PageRequest, headers map[string]string, p Paginator) ([]byte, error) {
+	paging, _ := ctx.Value(pagingKey{}).(*Paging)
+	if paging == nil {
+		return c.DoJSON(ctx, req.Method, req.URL, headers, req.requestBody())
+	}
+	limit := paging.MaxItems
+	if limit <= 0 || limit > MaxPagedItems {
+		limit = MaxPagedItems
+	}
+
+	var items []json.RawMessage
+	var last map[string]json.RawMessage
+	cut := false
+	for pages := 1; ; pages++ {
+		respBody, header, err := c.do(ctx, req.Method, req.URL, headers, req.requestBody())
+		if err != nil {
+			return nil, err
+		}
+		var pageItems []json.RawMessage
+		last = nil
+		if p.Items == "" {
+			err = json.Unmarshal(respBody, &pageItems)
+		} else if err = json.Unmarshal(respBody, &last); err == nil && last[p.Items] != nil {
+			err = json.Unmarshal(last[p.Items], &pageItems)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse page %d: %w", pages, err)
+		}
+
+		next, more := p.Next(req, header, last)
+		if len(items)+len(pageItems) > limit {
+			pageItems, more, cut = pageItems[:limit-len(items)], true, true
+		}
+		items = append(items, pageItems...)
+		paging.Pages++
+		paging.Items += len(pageItems)
+		if paging.Progress != nil {
+			paging.Progress(len(items), pages)
+		}
+
+		if !more {
+			break
+		}
+		if len(items) >= limit || pages >= MaxPages {
+			paging.Truncated = true
+			break
+		}
+		req = next
+	}
+
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	if p.Items == "" {
+		return json.Marshal(items)
+	}
+	merged, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	if last == nil {
+		last = map[string]json.RawMessage{}
+	}
+	if cut {
+		for _, field := range p.Cursors {
+			delete(last, field)
+		}
+	}
+	last[p.Items] = merged
+	return json.Marshal(last)
+}
+
+// requestBody avoids passing a nil map as a non-nil interface
+func (req PageRequest) requestBody() interface{} {
+	if req.Body == nil {
+		return nil
+	}
+	return req.Body
+}
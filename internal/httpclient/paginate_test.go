@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedServer serves items 1..total in pages of size through write, which
+// renders page n (from 0) holding items; last tells if it is the final page
+func pagedServer(t *testing.T, total, size int, write func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := pageIndex(r)
+		var items []int
+		for i := n*size + 1; i <= total && i <= (n+1)*size; i++ {
+			items = append(items, i)
+		}
+		write(w, r, n, items, (n+1)*size >= total)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// pageIndex reads the page a test request asks for from whichever
+// parameter the paginator under test sets
+func pageIndex(r *http.Request) int {
+	query := r.URL.Query()
+	for _, name := range []string{"page", "offset", "cursor", "nextPageToken"} {
+		if n, err := strconv.Atoi(query.Get(name)); err == nil {
+			return n
+		}
+	}
+	if startAt, err := strconv.Atoi(query.Get("startAt")); err == nil {
+		return startAt / 2
+	}
+	var body map[string]interface{}
+	if json.NewDecoder(r.Body).Decode(&body) == nil {
+		if cursor, ok := body["start_cursor"].(string); ok {
+			n, _ := strconv.Atoi(cursor)
+			return n
+		}
+	}
+	return 0
+}
+
+func collect(t *testing.T, maxItems int, req PageRequest, p Paginator) ([]int, *Paging) {
+	t.Helper()
+	paging := &Paging{MaxItems: maxItems}
+	body, err := New().Paginate(WithPaging(context.Background(), paging), req, nil, p)
+	if err != nil {
+		t.Fatalf("Paginate: %v", err)
+	}
+
+	var items []int
+	if p.Items == "" {
+		err = json.Unmarshal(body, &items)
+	} else {
+		var page map[string]json.RawMessage
+		if err = json.Unmarshal(body, &page); err == nil {
+			err = json.Unmarshal(page[p.Items], &items)
+		}
+	}
+	if err != nil {
+		t.Fatalf("merged body %s: %v", body, err)
+	}
+	return items, paging
+}
+
+func wantItems(t *testing.T, got []int, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("got %d items %v, want %d", len(got), got, n)
+	}
+	for i, item := range got {
+		if item != i+1 {
+			t.Fatalf("item %d = %d; items out of order: %v", i, item, got)
+		}
+	}
+}
+
+func TestPaginate_LinkHeader(t *testing.T) {
+	var server *httptest.Server
+	server = pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		if !last {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next", <%s?page=2>; rel="last"`, server.URL, n+1, server.URL))
+		}
+		json.NewEncoder(w).Encode(items)
+	})
+
+	items, paging := collect(t, 0, PageRequest{Method: "GET", URL: server.URL}, LinkHeader(""))
+	wantItems(t, items, 5)
+	if paging.Pages != 3 || paging.Items != 5 || paging.Truncated {
+		t.Errorf("paging = %+v", paging)
+	}
+}
+
+func TestPaginate_NextCursorInBody(t *testing.T) {
+	server := pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		if r.Method != "POST" {
+			t.Errorf("method = %s", r.Method)
+		}
+		var cursor interface{}
+		if !last {
+			cursor = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": items, "has_more": !last, "next_cursor": cursor})
+	})
+
+	req := PageRequest{Method: "POST", URL: server.URL, Body: map[string]interface{}{"page_size": 2}}
+	items, _ := collect(t, 0, req, NextCursor("results"))
+	wantItems(t, items, 5)
+	if _, ok := req.Body["start_cursor"]; ok {
+		t.Error("Paginate changed the caller's body")
+	}
+}
+
+func TestPaginate_OffsetToken(t *testing.T) {
+	server := pagedServer(t, 3, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		page := map[string]interface{}{"records": items}
+		if !last {
+			page["offset"] = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	items, _ := collect(t, 0, PageRequest{Method: "GET", URL: server.URL + "?pageSize=2"}, OffsetToken("records"))
+	wantItems(t, items, 3)
+}
+
+func TestPaginate_JiraStartAt(t *testing.T) {
+	server := pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"startAt": n * 2, "maxResults": 2, "total": 5, "comments": items})
+	})
+
+	items, _ := collect(t, 0, PageRequest{Method: "GET", URL: server.URL + "?startAt=0&maxResults=2"}, JiraPages("comments"))
+	wantItems(t, items, 5)
+}
+
+func TestPaginate_JiraNextPageToken(t *testing.T) {
+	server := pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		page := map[string]interface{}{"issues": items, "isLast": last}
+		if !last {
+			page["nextPageToken"] = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	items, _ := collect(t, 0, PageRequest{Method: "GET", URL: server.URL + "?jql=x"}, JiraPages("issues"))
+	wantItems(t, items, 5)
+}
+
+func TestPaginate_ConfluenceLinks(t *testing.T) {
+	for name, nextPath := range map[string]string{
+		"v2 (path includes /wiki)": "/wiki/api/v2/spaces",
+		"v1 (relative to base)":    "/rest/api/search",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var server *httptest.Server
+			server = pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+				if n > 0 && r.URL.Path != "/wiki/api/v2/spaces" && r.URL.Path != "/wiki/rest/api/search" {
+					t.Errorf("followed link to %s", r.URL.Path)
+				}
+				links := map[string]string{"base": server.URL + "/wiki"}
+				if !last {
+					links["next"] = fmt.Sprintf("%s?cursor=%d", nextPath, n+1)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"results": items, "_links": links})
+			})
+
+			items, _ := collect(t, 0, PageRequest{Method: "GET", URL: server.URL + "/wiki" + nextPath}, LinksNext("results"))
+			wantItems(t, items, 5)
+		})
+	}
+}
+
+func TestPaginate_MaxItemsCutsTheLastPage(t *testing.T) {
+	server := pagedServer(t, 10, 4, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		page := map[string]interface{}{"records": items}
+		if !last {
+			page["offset"] = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	items, paging := collect(t, 6, PageRequest{Method: "GET", URL: server.URL}, OffsetToken("records"))
+	wantItems(t, items, 6)
+	if paging.Pages != 2 || !paging.Truncated {
+		t.Errorf("paging = %+v", paging)
+	}
+}
+
+func TestPaginate_CutPagesLoseTheirCursor(t *testing.T) {
+	server := pagedServer(t, 10, 4, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		page := map[string]interface{}{"issues": items, "isLast": last}
+		if !last {
+			page["nextPageToken"] = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	for maxItems, want := range map[int]string{
+		// Cut mid-page: resuming from the token would skip items 7 and 8
+		6: `{"isLast":false,"issues":[1,2,3,4,5,6]}`,
+		// Cut on a page boundary: the token resumes right after item 8
+		8: `{"isLast":false,"issues":[1,2,3,4,5,6,7,8],"nextPageToken":"2"}`,
+	} {
+		paging := &Paging{MaxItems: maxItems}
+		body, err := New().Paginate(WithPaging(context.Background(), paging), PageRequest{Method: "GET", URL: server.URL}, nil, JiraPages("issues"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != want {
+			t.Errorf("max %d: body = %s, want %s", maxItems, body, want)
+		}
+	}
+}
+
+func TestPaginate_PageCap(t *testing.T) {
+	server := pagedServer(t, 10*MaxPages, 1, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"records": items, "offset": strconv.Itoa(n + 1)})
+	})
+
+	items, paging := collect(t, 0, PageRequest{Method: "GET", URL: server.URL}, OffsetToken("records"))
+	wantItems(t, items, MaxPages)
+	if !paging.Truncated {
+		t.Errorf("paging = %+v", paging)
+	}
+}
+
+func TestPaginate_FirstPageOnlyWithoutPaging(t *testing.T) {
+	server := pagedServer(t, 5, 2, func(w http.ResponseWriter, r *http.Request, n int, items []int, last bool) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"records": items, "offset": "1"})
+	})
+
+	body, err := New().Paginate(context.Background(), PageRequest{Method: "GET", URL: server.URL}, nil, OffsetToken("records"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"offset":"1","records":[1,2]}`; string(body) != want+"\n" {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
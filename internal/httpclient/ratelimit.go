@@ -0,0 +1,201 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned, wrapped, when a fail-fast rate limit has no
+// token left
+var ErrRateLimited = errors.New("client-side rate limit exceeded")
+
+// RateLimit is a token bucket limit on the requests to one upstream.
+// Buckets are kept per credential (the Authorization header) and, when
+// Partition is set, per partition of the requests, e.g. per Airtable base.
+type RateLimit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+	// Burst is the number of requests allowed at once
+	Burst int
+	// FailFast returns ErrRateLimited instead of waiting for a token
+	FailFast bool
+	// Partition optionally splits a credential's bucket by request
+	Partition func(req *http.Request) string
+}
+
+// lowRemaining is the share of an upstream's own quota below which requests
+// are spread evenly over the time left until the quota resets
+const lowRemaining = 0.2
+
+var limiters = struct {
+	sync.Mutex
+	byUpstream map[string]*limiter
+}{byUpstream: map[string]*limiter{}}
+
+// SetRateLimit installs the limit for an upstream. Clients created
+// WithUpstream(upstream) share it. The limit is reference counted: while it
+// is installed, further calls only add a reference and keep the limit and
+// buckets in place, so registries sharing a module also share its budget.
+func SetRateLimit(upstream string, limit RateLimit) {
+	limiters.Lock()
+	defer limiters.Unlock()
+	if l, ok := limiters.byUpstream[upstream]; ok {
+		l.refs++
+		return
+	}
+	limiters.byUpstream[upstream] = &limiter{limit: limit, buckets: map[string]*bucket{}, refs: 1}
+}
+
+// RemoveRateLimit drops a reference to the limit of an upstream, and the
+// limit itself with the last one
+func RemoveRateLimit(upstream string) {
+	limiters.Lock()
+	defer limiters.Unlock()
+	l, ok := limiters.byUpstream[upstream]
+	if !ok {
+		return
+	}
+	if l.refs--; l.refs <= 0 {
+		delete(limiters.byUpstream, upstream)
+	}
+}
+
+func limiterFor(upstream string) *limiter {
+	if upstream == "" {
+		return nil
+	}
+	limiters.Lock()
+	defer limiters.Unlock()
+	return limiters.byUpstream[upstream]
+}
+
+// WithUpstream names the upstream a client talks to, so requests are
+// subject to the upstream's RateLimit
+func WithUpstream(upstream string) Option {
+	return func(c *Client) {
+		c.upstream = upstream
+	}
+}
+
+type limiter struct {
+	limit   RateLimit
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// refs counts the SetRateLimit calls not yet removed, under limiters
+	refs int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// paced lowers the rate until pacedUntil, after the upstream reported
+	// its quota running low
+	paced      float64
+	pacedUntil time.Time
+}
+
+// key identifies the bucket of a request without keeping the credential
+func (l *limiter) key(req *http.Request) string {
+	key := credential(req.Header.Get("Authorization"))
+	if l.limit.Partition != nil {
+		key += "/" + l.limit.Partition(req)
+	}
+	return key
+}
+
+func (l *limiter) bucket(key string, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+func (l *limiter) rate(b *bucket, now time.Time) float64 {
+	if now.Before(b.pacedUntil) && b.paced < l.limit.Rate {
+		return b.paced
+	}
+	return l.limit.Rate
+}
+
+// reserve takes a token and returns how long to wait before using it. In
+// fail-fast mode nothing is taken when a wait would be needed.
+func (l *limiter) reserve(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, now)
+	rate := l.rate(b, now)
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	var wait time.Duration
+	switch {
+	case rate <= 0 && now.Before(b.pacedUntil):
+		// The quota is exhausted until it resets
+		wait = b.pacedUntil.Sub(now)
+	case b.tokens < 1:
+		wait = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	if wait > 0 && l.limit.FailFast {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel returns a token that was reserved but not used
+func (l *limiter) cancel(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(l.limit.Burst), b.tokens+1)
+	}
+}
+
+// wait blocks until the request may be sent
+func (l *limiter) wait(ctx context.Context, key string) error {
+	wait, ok := l.reserve(key, time.Now())
+	if !ok {
+		return fmt.Errorf("%w: next request possible in %v", ErrRateLimited, wait.Round(time.Millisecond))
+	}
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel(key)
+		return err
+	}
+	return nil
+}
+
+// observe reads the upstream's own quota headers, as GitHub sends them, and
+// paces the bucket when the remaining quota is low
+func (l *limiter) observe(key string, header http.Header, now time.Time) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	untilReset, ok := resetDelay(header.Get("X-RateLimit-Reset"), now)
+	if !ok || untilReset <= 0 {
+		return
+	}
+	if quota, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil && quota > 0 &&
+		float64(remaining) >= lowRemaining*float64(quota) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key, now)
+	b.paced = float64(remaining) / untilReset.Seconds()
+	b.pacedUntil = now.Add(untilReset)
+	b.tokens = math.Min(b.tokens, float64(remaining))
+}
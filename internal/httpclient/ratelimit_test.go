@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// limitedClient installs limit for a fresh upstream and returns a client
+// bound to it
+func limitedClient(t *testing.T, limit RateLimit) *Client {
+	t.Helper()
+	upstream := t.Name()
+	SetRateLimit(upstream, limit)
+	t.Cleanup(func() { RemoveRateLimit(upstream) })
+	return New(WithUpstream(upstream), WithRetryPolicy(NoRetry))
+}
+
+func TestRateLimit_BlocksBeyondBurst(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil)
+	client := limitedClient(t, RateLimit{Rate: 10, Burst: 2})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Two requests pass at once, the other two wait 100ms each
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond || hits != 4 {
+		t.Errorf("hits = %d after %v; want about 200ms", hits, elapsed)
+	}
+}
+
+func TestRateLimit_FailFast(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil)
+	client := limitedClient(t, RateLimit{Rate: 1, Burst: 1, FailFast: true})
+	client.retry = fastRetry
+
+	if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	start := time.Now()
+	_, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil)
+	if !errors.Is(err, ErrRateLimited) || hits != 1 || time.Since(start) > 100*time.Millisecond {
+		t.Errorf("hits = %d, err = %v", hits, err)
+	}
+}
+
+func TestRateLimit_StaysUntilTheLastReferenceIsRemoved(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil)
+	upstream := t.Name()
+	client := New(WithUpstream(upstream), WithRetryPolicy(NoRetry))
+
+	// A second registration shares the limit and its buckets
+	SetRateLimit(upstream, RateLimit{Rate: 1, Burst: 1, FailFast: true})
+	SetRateLimit(upstream, RateLimit{Rate: 100, Burst: 100})
+	if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	RemoveRateLimit(upstream)
+	if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("limit removed with a reference left: %v", err)
+	}
+
+	RemoveRateLimit(upstream)
+	if limiterFor(upstream) != nil {
+		t.Error("limit kept after the last reference was removed")
+	}
+}
+
+func TestRateLimit_BucketPerCredentialAndPartition(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil)
+	client := limitedClient(t, RateLimit{Rate: 1, Burst: 1, FailFast: true, Partition: func(req *http.Request) string {
+		return req.URL.Query().Get("base")
+	}})
+
+	for _, call := range []struct {
+		token, base string
+		limited     bool
+	}{
+		{"a", "app1", false},
+		{"b", "app1", false},
+		{"a", "app2", false},
+		{"a", "app1", true},
+	} {
+		headers := map[string]string{"Authorization": "Bearer " + call.token}
+		_, err := client.DoJSON(context.Background(), "GET", server.URL+"?base="+call.base, headers, nil)
+		if limited := errors.Is(err, ErrRateLimited); limited != call.limited {
+			t.Errorf("token %s, base %s: err = %v", call.token, call.base, err)
+		}
+	}
+}
+
+func TestRateLimit_CancelledWhileWaiting(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil)
+	client := limitedClient(t, RateLimit{Rate: 0.1, Burst: 1})
+
+	client.DoJSON(context.Background(), "GET", server.URL, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.DoJSON(ctx, "GET", server.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) || hits != 1 {
+		t.Errorf("hits = %d, err = %v", hits, err)
+	}
+}
+
+func TestRateLimit_PacesOnLowUpstreamQuota(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(2*time.Second).Unix(), 10)
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", reset)
+	}))
+	t.Cleanup(server.Close)
+	client := limitedClient(t, RateLimit{Rate: 100, Burst: 100, FailFast: true})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	// The upstream allows one request in the next two seconds; the burst no
+	// longer applies
+	if _, err := client.DoJSON(context.Background(), "GET", server.URL, nil, nil); !errors.Is(err, ErrRateLimited) || hits != 2 {
+		t.Errorf("hits = %d, err = %v", hits, err)
+	}
+}
+
+func TestRateLimit_PlentyOfQuotaIsIgnored(t *testing.T) {
+	l := &limiter{limit: RateLimit{Rate: 100, Burst: 100}, buckets: map[string]*bucket{}}
+	now := time.Now()
+	l.observe("k", http.Header{
+		"X-Ratelimit-Limit":     {"5000"},
+		"X-Ratelimit-Remaining": {"4000"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(now.Add(time.Hour).Unix(), 10)},
+	}, now)
+	if _, ok := l.buckets["k"]; ok {
+		t.Error("bucket paced despite plenty of quota")
+	}
+}
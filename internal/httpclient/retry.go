@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how DoJSON retries failed requests. Rate limited
+// responses (429, and 403 with rate limit headers), 502, 503, 504 and
+// network errors are retried. Requests with a non-idempotent method are only
+// retried when the caller marks them with Idempotent.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the first backoff, doubled for every further attempt
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff; when a server asks for a longer wait,
+	// the error is returned instead. 0 means no cap.
+	MaxDelay time.Duration
+	// MaxElapsed bounds the whole call, waits included; 0 means no bound
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy is the policy of clients created by New
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    20 * time.Second,
+	MaxElapsed:  60 * time.Second,
+}
+
+// NoRetry makes exactly one attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithTimeout sets the timeout of a single attempt; the default is 30s.
+// DoStream applies it to the wait for the response headers only.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+type idempotentKey struct{}
+
+// Idempotent marks requests made with the returned context as reads that
+// are safe to retry whatever their method, e.g. a search sent as POST. They
+// do not invalidate the client's cache.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	marked, _ := ctx.Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// retryable reports whether a response status is worth another attempt
+func retryable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		// GitHub reports primary and secondary rate limits as 403
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+// serverDelay reads how long the server asks us to wait, from Retry-After
+// (seconds or an HTTP date) or X-RateLimit-Reset (epoch seconds as GitHub
+// sends it, or an ISO 8601 time as Jira does). ok is false without either.
+func serverDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return nonNegative(at.Sub(now)), true
+		}
+	}
+	return resetDelay(header.Get("X-RateLimit-Reset"), now)
+}
+
+// resetDelay parses an X-RateLimit-Reset value into the time left until it
+func resetDelay(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if epoch, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+		return nonNegative(time.Unix(epoch, 0).Sub(now)), true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if at, err := time.Parse(layout, value); err == nil {
+			return nonNegative(at.Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// backoff returns the wait before attempt n+1 (n >= 1): BaseDelay doubled
+// n-1 times and capped at MaxDelay, with jitter over its upper half
+func (p RetryPolicy) backoff(n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay == 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	return delay
+}
+
+// sleep waits for d unless ctx ends first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fitsDeadline reports whether waiting d leaves time for another attempt
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Now().Add(d).Before(deadline)
+}
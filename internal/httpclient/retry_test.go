@@ -0,0 +1,163 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second, MaxElapsed: 5 * time.Second}
+
+// flakyServer answers with the given statuses in turn, then 200 with the
+// request body echoed back
+func flakyServer(t *testing.T, hits *int32, header http.Header, statuses ...int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(hits, 1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			w.Write([]byte(`{"error":"try later"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetry_TransientErrorsThenSuccess(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusServiceUnavailable, http.StatusBadGateway)
+
+	resp, err := New(WithRetryPolicy(fastRetry)).DoJSON(context.Background(), "GET", server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hits != 3 || string(resp) != "" {
+		t.Errorf("hits = %d, resp = %q", hits, resp)
+	}
+}
+
+func TestRetry_NonIdempotentOnlyWhenMarked(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusServiceUnavailable)
+	client := New(WithRetryPolicy(fastRetry))
+
+	_, err := client.DoJSON(context.Background(), "POST", server.URL, nil, map[string]string{"q": "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || hits != 1 {
+		t.Fatalf("POST: hits = %d, err = %v", hits, err)
+	}
+
+	hits = 0
+	resp, err := client.DoJSON(Idempotent(context.Background()), "POST", server.URL, nil, map[string]string{"q": "x"})
+	if err != nil || hits != 2 || string(resp) != `{"q":"x"}` {
+		t.Errorf("marked POST: hits = %d, resp = %q, err = %v", hits, resp, err)
+	}
+}
+
+func TestRetry_ClientErrorsAreNotRetried(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusForbidden} {
+		var hits int32
+		server := flakyServer(t, &hits, nil, status)
+		if _, err := New(WithRetryPolicy(fastRetry)).DoJSON(context.Background(), "GET", server.URL, nil, nil); err == nil || hits != 1 {
+			t.Errorf("status %d: hits = %d, err = %v", status, hits, err)
+		}
+	}
+}
+
+func TestRetry_RespectsRetryAfter(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+
+	start := time.Now()
+	if _, err := New(WithRetryPolicy(fastRetry)).DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || hits != 2 {
+		t.Errorf("hits = %d after %v; want a wait of about 1s", hits, elapsed)
+	}
+}
+
+func TestRetry_GitHubRateLimit(t *testing.T) {
+	var hits int32
+	reset := strconv.FormatInt(time.Now().Unix(), 10)
+	server := flakyServer(t, &hits, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}, http.StatusForbidden)
+
+	if _, err := New(WithRetryPolicy(fastRetry)).DoJSON(context.Background(), "GET", server.URL, nil, nil); err != nil || hits != 2 {
+		t.Errorf("hits = %d, err = %v", hits, err)
+	}
+}
+
+func TestRetry_GivesUpOnLongServerWaits(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+
+	start := time.Now()
+	_, err := New(WithRetryPolicy(fastRetry)).DoJSON(context.Background(), "GET", server.URL, nil, nil)
+	if err == nil || hits != 1 || time.Since(start) > time.Second {
+		t.Errorf("hits = %d, err = %v", hits, err)
+	}
+}
+
+func TestRetry_MaxElapsedBoundsTheCall(t *testing.T) {
+	var hits int32
+	statuses := make([]int, 1000)
+	for i := range statuses {
+		statuses[i] = http.StatusServiceUnavailable
+	}
+	server := flakyServer(t, &hits, nil, statuses...)
+	policy := RetryPolicy{MaxAttempts: 1000, BaseDelay: 20 * time.Millisecond, MaxDelay: 20 * time.Millisecond, MaxElapsed: 150 * time.Millisecond}
+
+	start := time.Now()
+	_, err := New(WithRetryPolicy(policy)).DoJSON(context.Background(), "GET", server.URL, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("call took %v despite MaxElapsed", elapsed)
+	}
+	if hits < 2 {
+		t.Errorf("expected several attempts, got %d", hits)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	for name, header := range map[string]http.Header{
+		"seconds":    {"Retry-After": {"5"}},
+		"http date":  {"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}},
+		"epoch":      {"X-Ratelimit-Reset": {strconv.FormatInt(now.Add(5*time.Second).Unix(), 10)}},
+		"iso 8601":   {"X-Ratelimit-Reset": {"2026-01-10T12:00:05Z"}},
+		"jira style": {"X-Ratelimit-Reset": {"2026-01-10T21:00:05+09:00"}},
+	} {
+		if got, ok := serverDelay(header, now); !ok || got != 5*time.Second {
+			t.Errorf("%s: delay = %v, %v", name, got, ok)
+		}
+	}
+	if _, ok := serverDelay(http.Header{}, now); ok {
+		t.Error("expected no delay without headers")
+	}
+}
+
+func TestBackoff_ExponentialWithJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for i := 0; i < 50; i++ {
+		if d := policy.backoff(3); d < 200*time.Millisecond || d > 400*time.Millisecond {
+			t.Fatalf("backoff(3) = %v, want within [200ms, 400ms]", d)
+		}
+		if d := policy.backoff(10); d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("backoff(10) = %v, want within [500ms, 1s]", d)
+		}
+	}
+}
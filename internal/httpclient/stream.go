@@ -0,0 +1,205 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxResponseBytes caps the bodies Do and DoJSON read into memory
+const DefaultMaxResponseBytes = 32 << 20
+
+// maxErrorBytes caps the body kept in an APIError
+const maxErrorBytes = 64 << 10
+
+// ErrResponseTooLarge is returned when a response body exceeds its size limit
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// Request is a request with a raw body, for uploads and downloads that are
+// not JSON
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	// Body is sent as is; nil sends none. Failed attempts are only retried
+	// when Body is an io.Seeker, like *bytes.Reader or *os.File, and they
+	// start over from where the first one began reading.
+	Body io.Reader
+	// ContentType is sent as the Content-Type of Body
+	ContentType string
+	// MaxResponseBytes caps the response body; Do defaults to
+	// DefaultMaxResponseBytes and DoStream to no limit
+	MaxResponseBytes int64
+}
+
+// Response is a response read into memory by Do
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Do makes req and reads the response body, which must fit in
+// MaxResponseBytes. It retries, rate limits and invalidates the cache the way
+// DoJSON does; a non-2xx response is an *APIError.
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	resp, err := c.read(ctx, req)
+	if err == nil {
+		c.invalidate(ctx, req.Method, req.URL)
+	}
+	return resp, err
+}
+
+// read is Do without cache invalidation. resp is set whenever the server
+// answered.
+func (c *Client) read(ctx context.Context, req Request) (*Response, error) {
+	limit := req.MaxResponseBytes
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	policy := c.policy(ctx, req)
+	if policy.MaxElapsed > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.MaxElapsed)
+		defer cancel()
+	}
+
+	var out *Response
+	_, err := c.send(ctx, c.httpClient, policy, req, func(resp *http.Response) error {
+		defer resp.Body.Close()
+		out = &Response{StatusCode: resp.StatusCode, Header: resp.Header}
+		body, err := readLimited(resp.Body, limit)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		out.Body = body
+		return nil
+	})
+	return out, err
+}
+
+// DoStream makes req and returns the response with its body unread, for
+// downloads too large to hold in memory. The caller must close the body;
+// reading past MaxResponseBytes fails with ErrResponseTooLarge. A non-2xx
+// response is an *APIError. Retries only cover getting the response, so
+// the retry policy's MaxElapsed does not apply. The client's timeout only
+// bounds the wait for the response headers; ctx bounds the download.
+func (c *Client) DoStream(ctx context.Context, req Request) (*http.Response, error) {
+	resp, err := c.send(ctx, c.streamClient, c.policy(ctx, req), req, func(resp *http.Response) error {
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxResponseBytes > 0 {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: req.MaxResponseBytes}
+	}
+	c.invalidate(ctx, req.Method, req.URL)
+	return resp, nil
+}
+
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
+// limitedBody fails reads past the size limit instead of truncating silently
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Tell the end of the body from more of it; a probe that reads
+		// nothing is taken as the end
+		var probe [1]byte
+		if n, err := b.ReadCloser.Read(probe[:]); n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// FilePart is a file of a multipart form
+type FilePart struct {
+	Field    string
+	Filename string
+	// ContentType defaults to application/octet-stream
+	ContentType string
+	Content     io.Reader
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Multipart encodes fields and files as a multipart/form-data body and
+// returns it with its Content-Type. The body is built in memory, so requests
+// sending it can be retried.
+func Multipart(fields map[string]string, files ...FilePart) (*bytes.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := w.WriteField(name, fields[name]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.Field), quoteEscaper.Replace(file.Filename)))
+		header.Set("Content-Type", contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", file.Filename, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(buf.Bytes()), w.FormDataContentType(), nil
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDo_RawBodyAndContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.Header.Get("Content-Type") + ":" + string(body)))
+	}))
+	defer server.Close()
+
+	resp, err := New().Do(context.Background(), Request{
+		Method:      "PUT",
+		URL:         server.URL,
+		Body:        strings.NewReader("raw bytes"),
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Body) != "application/octet-stream:raw bytes" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("unexpected response: %d %q", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestDo_RetriesRewindTheBody(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusServiceUnavailable)
+
+	resp, err := New(WithRetryPolicy(fastRetry)).Do(context.Background(), Request{
+		Method: "PUT",
+		URL:    server.URL,
+		Body:   bytes.NewReader([]byte("payload")),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Body) != "payload" || hits != 2 {
+		t.Errorf("body %q after %d attempts", resp.Body, hits)
+	}
+}
+
+func TestDo_UnseekableBodiesAreNotRetried(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusServiceUnavailable)
+
+	_, err := New(WithRetryPolicy(fastRetry)).Do(context.Background(), Request{
+		Method: "PUT",
+		URL:    server.URL,
+		Body:   io.MultiReader(strings.NewReader("payload")),
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || hits != 1 {
+		t.Errorf("got %v after %d attempts", err, hits)
+	}
+}
+
+func TestDo_ResponseSizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 100))
+	}))
+	defer server.Close()
+	client := New()
+
+	if _, err := client.Do(context.Background(), Request{Method: "GET", URL: server.URL, MaxResponseBytes: 99}); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if resp, err := client.Do(context.Background(), Request{Method: "GET", URL: server.URL, MaxResponseBytes: 100}); err != nil || len(resp.Body) != 100 {
+		t.Errorf("body within the limit: %v", err)
+	}
+}
+
+func TestDoStream_ReadsTheBodyLazily(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("head,"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("tail"))
+	}))
+	defer server.Close()
+
+	resp, err := New().DoStream(context.Background(), Request{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	close(release)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "head,tail" {
+		t.Errorf("body %q, err %v", body, err)
+	}
+}
+
+func TestDoStream_SizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 100))
+	}))
+	defer server.Close()
+	client := New()
+
+	resp, err := client.DoStream(context.Background(), Request{Method: "GET", URL: server.URL, MaxResponseBytes: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrResponseTooLarge) || len(body) != 10 {
+		t.Errorf("read %d bytes, err %v", len(body), err)
+	}
+
+	resp, err = client.DoStream(context.Background(), Request{Method: "GET", URL: server.URL, MaxResponseBytes: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || len(body) != 100 {
+		t.Errorf("exact size: read %d bytes, err %v", len(body), err)
+	}
+}
+
+func TestDoStream_TimeoutOnlyBoundsTheHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(150 * time.Millisecond)
+		}
+		w.Write([]byte("head,"))
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("tail"))
+	}))
+	defer server.Close()
+	client := New(WithTimeout(50*time.Millisecond), WithRetryPolicy(NoRetry))
+
+	// The body takes longer than the timeout to arrive
+	resp, err := client.DoStream(context.Background(), Request{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "head,tail" {
+		t.Errorf("body %q, err %v", body, err)
+	}
+
+	if _, err := client.DoStream(context.Background(), Request{Method: "GET", URL: server.URL + "/slow-headers"}); err == nil {
+		t.Error("expected a timeout waiting for the headers")
+	}
+}
+
+// emptyReads reads nothing without an error, as io.Reader allows
+type emptyReads struct{}
+
+func (emptyReads) Read(p []byte) (int, error) { return 0, nil }
+
+func TestLimitedBody_EmptyProbeIsTheEnd(t *testing.T) {
+	body := &limitedBody{ReadCloser: io.NopCloser(emptyReads{}), remaining: 0}
+	if n, err := body.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("read %d bytes, err %v; want io.EOF", n, err)
+	}
+}
+
+func TestDoStream_APIError(t *testing.T) {
+	var hits int32
+	server := flakyServer(t, &hits, nil, http.StatusNotFound)
+
+	_, err := New().DoStream(context.Background(), Request{Method: "GET", URL: server.URL})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Body != `{"error":"try later"}` {
+		t.Errorf("expected the 404, got %v", err)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		w.Write([]byte(r.FormValue("comment") + "|" + header.Filename + "|" + header.Header.Get("Content-Type") + "|" + string(content)))
+	}))
+	defer server.Close()
+
+	body, contentType, err := Multipart(map[string]string{"comment": "hi"},
+		FilePart{Field: "file", Filename: `a "b".txt`, ContentType: "text/plain", Content: strings.NewReader("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := New().Do(context.Background(), Request{Method: "POST", URL: server.URL, Body: body, ContentType: contentType})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `hi|a "b".txt|text/plain|hello`; string(resp.Body) != want {
+		t.Errorf("server saw %q, want %q", resp.Body, want)
+	}
+}
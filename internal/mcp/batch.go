@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// maxBatchConcurrency limits how many calls of one batch run at the same time
+const maxBatchConcurrency = 8
+
+// isBatch reports whether a message body is a JSON-RPC batch (a JSON array)
+func isBatch(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// processBatch runs the calls of a JSON-RPC 2.0 batch in parallel and returns
+// their responses in request order. Notifications produce no entry, so the
+// result is empty when the batch only holds notifications. A non-nil *Error
+// means the batch itself is invalid and must be answered with a single response.
+func (h *Handler) processBatch(ctx context.Context, session *Session, body []byte) ([]Response, *Error) {
+	if !session.features().batching {
+		return nil, &Error{Code: InvalidRequest, Message: "Invalid Request: batching is not supported in protocol version " + session.ProtocolVersion()}
+	}
+
+	var rawMessages []json.RawMessage
+	if err := json.Unmarshal(body, &rawMessages); err != nil {
+		return nil, &Error{Code: ParseError, Message: "Parse error"}
+	}
+
+	if len(rawMessages) == 0 {
+		return nil, &Error{Code: InvalidRequest, Message: "Invalid Request: empty batch"}
+	}
+
+	responses := make([]*Response, len(rawMessages))
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+
+	for i, raw := range rawMessages {
+		var req Request
+		if err := json.Unmarshal(raw, &req); err != nil || req.Method == "" {
+			responses[i] = &Response{JSONRPC: "2.0", Error: &Error{Code: InvalidRequest, Message: "Invalid Request"}}
+			continue
+		}
+
+		if req.Method == "initialize" {
+			if req.ID != nil {
+				responses[i] = &Response{JSONRPC: "2.0", ID: req.ID, Error: &Error{Code: InvalidRequest, Message: "initialize must not be part of a batch"}}
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			responses[i] = h.respond(ctx, session, &req)
+		}(i, req)
+	}
+
+	wg.Wait()
+
+	result := make([]Response, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			result = append(result, *resp)
+		}
+	}
+	return result, nil
+}
+
+// respond processes a single message and builds its response.
+// Notifications and cancelled requests return nil because they must not be
+// answered.
+func (h *Handler) respond(ctx context.Context, session *Session, req *Request) *Response {
+	result, rpcErr := h.processRequest(ctx, session, req)
+	if req.ID == nil || rpcErr == errRequestCancelled {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// batchHasToolCall reports whether any message of a batch is a tools/call
+func batchHasToolCall(body []byte) bool {
+	var methods []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &methods); err != nil {
+		return false
+	}
+	for _, m := range methods {
+		if m.Method == "tools/call" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var inFlight, maxInFlight int32
+
+// slowModule is a slow module to observe batch concurrency
+var slowModule = modules.ModuleDefinition{
+	Name:        "slow",
+	Description: "Slow test module",
+	Tools: []modules.Tool{
+		{Name: "wait", Description: "Sleep briefly", InputSchema: modules.InputSchema{Type: "object"}},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"wait": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return "done", nil
+		},
+	},
+}
+
+func TestBatch_OrderAndNotifications(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250326), "", `[
+		{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"test","tool_name":"echo","params":{"message":"first"}}}},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":"b","method":"unknown/method"},
+		{"jsonrpc":"2.0","id":"c","method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"test","tool_name":"echo","params":{"message":"third"}}}}
+	]`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var responses []struct {
+		ID     string          `json:"id"`
+		Result *ToolCallResult `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("failed to unmarshal batch response: %v (%s)", err, rec.Body.String())
+	}
+
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses (notification omitted), got %d", len(responses))
+	}
+
+	if responses[0].ID != "a" || responses[0].Result.Content[0].Text != "Echo: first" {
+		t.Errorf("unexpected first response: %+v", responses[0])
+	}
+	if responses[1].ID != "b" || responses[1].Error == nil || responses[1].Error.Code != MethodNotFound {
+		t.Errorf("expected MethodNotFound for b, got %+v", responses[1])
+	}
+	if responses[2].ID != "c" || responses[2].Result.Content[0].Text != "Echo: third" {
+		t.Errorf("unexpected third response: %+v", responses[2])
+	}
+}
+
+func TestBatch_InvalidEntries(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250326), "", `[1, {"jsonrpc":"2.0","id":2,"method":"initialize"}]`)
+
+	var responses []Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("failed to unmarshal batch response: %v", err)
+	}
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	for _, resp := range responses {
+		if resp.Error == nil || resp.Error.Code != InvalidRequest {
+			t.Errorf("expected InvalidRequest, got %+v", resp)
+		}
+	}
+}
+
+func TestBatch_Empty(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250326), "", `[]`)
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected a single response object: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest, got %+v", resp.Error)
+	}
+}
+
+func TestBatch_RejectedFrom20250618(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250618), "", `[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`)
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected a single response object: %v (%s)", err, rec.Body.String())
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest, got %+v", resp.Error)
+	}
+}
+
+func TestBatch_OnlyNotifications(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250326), "", `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestBatch_ConcurrencyLimit(t *testing.T) {
+	handler := newTestHandler(t)
+
+	body := "["
+	for i := 0; i < maxBatchConcurrency*3; i++ {
+		if i > 0 {
+			body += ","
+		}
+		body += `{"jsonrpc":"2.0","id":` + jsonNumber(i) + `,"method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"slow","tool_name":"wait"}}}`
+	}
+	body += "]"
+
+	rec := postMCP(handler, initSession(t, handler, ProtocolVersion20250326), "", body)
+
+	var responses []Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("failed to unmarshal batch response: %v", err)
+	}
+	if len(responses) != maxBatchConcurrency*3 {
+		t.Fatalf("expected %d responses, got %d", maxBatchConcurrency*3, len(responses))
+	}
+	for i, resp := range responses {
+		if resp.ID.(float64) != float64(i) {
+			t.Fatalf("response %d has id %v", i, resp.ID)
+		}
+	}
+
+	if max := atomic.LoadInt32(&maxInFlight); max < 2 || max > maxBatchConcurrency {
+		t.Errorf("expected parallel execution capped at %d, observed %d", maxBatchConcurrency, max)
+	}
+}
+
+func jsonNumber(i int) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var blockStarted = make(chan struct{}, 1)
+
+// blockModule has a tool that runs until its context is cancelled
+var blockModule = modules.ModuleDefinition{
+	Name:        "block",
+	Description: "Blocking test module",
+	Tools: []modules.Tool{
+		{Name: "forever", Description: "Block until cancelled", InputSchema: modules.InputSchema{Type: "object"}},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"forever": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			blockStarted <- struct{}{}
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	},
+}
+
+func blockingCall(id interface{}) *Request {
+	return &Request{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  "tools/call",
+		Params: map[string]interface{}{
+			"name":      "call_module_tool",
+			"arguments": map[string]interface{}{"module": "block", "tool_name": "forever"},
+		},
+	}
+}
+
+func startBlockingCall(t *testing.T, handler *Handler, session *Session, id interface{}) <-chan *Response {
+	t.Helper()
+	done := make(chan *Response, 1)
+	go func() {
+		done <- handler.respond(context.Background(), session, blockingCall(id))
+	}()
+
+	select {
+	case <-blockStarted:
+	case <-time.After(time.Second):
+		t.Fatal("tool call did not start")
+	}
+	return done
+}
+
+func waitResponse(t *testing.T, done <-chan *Response) *Response {
+	t.Helper()
+	select {
+	case resp := <-done:
+		return resp
+	case <-time.After(time.Second):
+		t.Fatal("tool call was not cancelled")
+		return nil
+	}
+}
+
+func TestCancelled_AbortsInFlightRequest(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	done := startBlockingCall(t, handler, session, 7)
+
+	// Cancelling an unknown or differently typed ID must not touch the call
+	handler.respond(context.Background(), session, &Request{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  map[string]interface{}{"requestId": "7"},
+	})
+	select {
+	case <-done:
+		t.Fatal("request with string ID \"7\" must not cancel numeric ID 7")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Decoded IDs are float64, which must still match the int used above
+	resp := handler.respond(context.Background(), session, &Request{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  map[string]interface{}{"requestId": float64(7), "reason": "user aborted"},
+	})
+	if resp != nil {
+		t.Errorf("notifications must not be answered, got %+v", resp)
+	}
+
+	if resp := waitResponse(t, done); resp != nil {
+		t.Errorf("cancelled request must not be answered, got %+v", resp)
+	}
+
+	session.mu.RLock()
+	inflight := len(session.inflight)
+	session.mu.RUnlock()
+	if inflight != 0 {
+		t.Errorf("expected no in-flight requests, got %d", inflight)
+	}
+}
+
+func TestCancelled_SessionCloseAbortsRequests(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	done := startBlockingCall(t, handler, session, "long")
+	session.close()
+
+	if resp := waitResponse(t, done); resp != nil {
+		t.Errorf("aborted request must not be answered, got %+v", resp)
+	}
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// completionCacheTTL is how long completion candidates are reused. Hosts ask
+// for completions on every keystroke, so the module lookup runs once per
+// argument instead.
+const completionCacheTTL = 5 * time.Minute
+
+type completionEntry struct {
+	candidates []string
+	expires    time.Time
+}
+
+// completionCache holds completion candidates of one session
+type completionCache struct {
+	mu      sync.Mutex
+	entries map[string]completionEntry
+}
+
+// get returns cached candidates for key, calling load on a miss.
+// Errors are not cached so a failed lookup is retried on the next keystroke.
+func (c *completionCache) get(key string, now time.Time, load func() ([]string, error)) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.candidates, nil
+	}
+
+	candidates, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]completionEntry)
+	}
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = completionEntry{candidates: candidates, expires: now.Add(completionCacheTTL)}
+	c.mu.Unlock()
+
+	return candidates, nil
+}
+
+// completionKey identifies the candidates of an argument. The other
+// arguments are part of the key because completers may depend on them.
+func completionKey(ref, argName string, args map[string]string) string {
+	parts := make([]string, 0, len(args))
+	for name, value := range args {
+		if name != argName {
+			parts = append(parts, name+"="+value)
+		}
+	}
+	sort.Strings(parts)
+	return ref + "\x00" + argName + "\x00" + strings.Join(parts, "\x00")
+}
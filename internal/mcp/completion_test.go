@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func completeResource(t *testing.T, handler *Handler, session *Session, uri, arg, value string) *Response {
+	t.Helper()
+	return call(t, handler, session, "completion/complete", map[string]interface{}{
+		"ref":      map[string]string{"type": "ref/resource", "uri": uri},
+		"argument": map[string]string{"name": arg, "value": value},
+	})
+}
+
+func TestCompletion_ResourceTemplateCachedPerSession(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+	before := atomic.LoadInt32(&spaceLookups)
+
+	// Typing "t", "te", "tea" looks the candidates up only once
+	var completion Completion
+	for _, value := range []string{"t", "te", "tea"} {
+		resp := completeResource(t, handler, session, "docs://{space}/file/{path}", "space", value)
+		if resp.Error != nil {
+			t.Fatalf("%q: unexpected error %+v", value, resp.Error)
+		}
+		completion = resp.Result.(*CompleteResult).Completion
+	}
+	if len(completion.Values) != 1 || completion.Values[0] != "team" {
+		t.Errorf("expected [team], got %+v", completion)
+	}
+	if lookups := atomic.LoadInt32(&spaceLookups) - before; lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", lookups)
+	}
+
+	// Another session has its own cache
+	completeResource(t, handler, initializedSession(LatestProtocolVersion), "docs://{space}/file/{path}", "space", "")
+	if lookups := atomic.LoadInt32(&spaceLookups) - before; lookups != 2 {
+		t.Errorf("expected a second lookup for a new session, got %d", lookups)
+	}
+
+	for _, tt := range []struct{ uri, arg string }{
+		{"docs://unknown/{x}", "x"},
+		{"docs://{space}/file/{path}", "missing"},
+	} {
+		if resp := completeResource(t, handler, session, tt.uri, tt.arg, ""); resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("%s %s: expected an invalid params error, got %+v", tt.uri, tt.arg, resp.Error)
+		}
+	}
+}
+
+func TestCompletionCache_ExpiresAndSkipsErrors(t *testing.T) {
+	var cache completionCache
+	now := time.Now()
+	loads := 0
+	load := func() ([]string, error) {
+		loads++
+		return []string{"a"}, nil
+	}
+
+	cache.get("k", now, load)
+	cache.get("k", now.Add(completionCacheTTL-time.Second), load)
+	if loads != 1 {
+		t.Fatalf("expected 1 load within the TTL, got %d", loads)
+	}
+	cache.get("k", now.Add(completionCacheTTL), load)
+	if loads != 2 {
+		t.Fatalf("expected a reload after the TTL, got %d", loads)
+	}
+
+	failures := 0
+	fail := func() ([]string, error) {
+		failures++
+		return nil, errors.New("unavailable")
+	}
+	cache.get("err", now, fail)
+	cache.get("err", now, fail)
+	if failures != 2 {
+		t.Errorf("expected errors not to be cached, got %d loads", failures)
+	}
+}
+
+func TestCompletionKey_IgnoresArgumentOrder(t *testing.T) {
+	a := completionKey("ref", "table", map[string]string{"base_id": "app1", "view": "v", "table": "typed"})
+	b := completionKey("ref", "table", map[string]string{"view": "v", "base_id": "app1"})
+	if a != b {
+		t.Errorf("expected equal keys, got %q and %q", a, b)
+	}
+	if c := completionKey("ref", "table", map[string]string{"base_id": "app2"}); c == a {
+		t.Error("expected different keys for different context arguments")
+	}
+}
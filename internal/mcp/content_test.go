@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// typedModule returns structured and typed results
+var typedModule = modules.ModuleDefinition{
+	Name:        "typed",
+	Description: "Typed content test module",
+	Tools: []modules.Tool{
+		{
+			Name:        "record",
+			Description: "Return a JSON object",
+			InputSchema: modules.InputSchema{Type: "object"},
+			OutputSchema: &modules.InputSchema{
+				Type:       "object",
+				Properties: map[string]modules.Property{"id": {Type: "string", Description: "Record ID"}},
+			},
+		},
+		{Name: "link", Description: "Return a resource link", InputSchema: modules.InputSchema{Type: "object"}},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"record": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return `{"id": "rec1"}`, nil
+		},
+	},
+	ResultHandlers: map[string]modules.ResultHandler{
+		"link": func(ctx context.Context, params map[string]interface{}) (*modules.ToolCallResult, error) {
+			return &modules.ToolCallResult{
+				Content: []modules.ContentBlock{
+					modules.ResourceLink("typed://rec1", "rec1", "A record", "application/json"),
+					modules.ImageContent([]byte{0x89, 'P', 'N', 'G'}, "image/png"),
+				},
+			}, nil
+		},
+	},
+}
+
+func callTyped(t *testing.T, version, tool string) *ToolCallResult {
+	t.Helper()
+	handler := newTestHandler(t)
+	session := initializedSession(version)
+
+	resp := handler.respond(context.Background(), session, &Request{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: map[string]interface{}{
+			"name":      "call_module_tool",
+			"arguments": map[string]interface{}{"module": "typed", "tool_name": tool},
+		},
+	})
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	return resp.Result.(*ToolCallResult)
+}
+
+func TestToolResult_StructuredContent(t *testing.T) {
+	result := callTyped(t, ProtocolVersion20250618, "record")
+
+	structured, ok := result.StructuredContent.(map[string]interface{})
+	if !ok || structured["id"] != "rec1" {
+		t.Errorf("expected structuredContent {id: rec1}, got %#v", result.StructuredContent)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text == "" {
+		t.Errorf("expected the JSON to stay available as text, got %+v", result.Content)
+	}
+
+	// Clients before 2025-06-18 do not know structuredContent
+	if result := callTyped(t, ProtocolVersion20250326, "record"); result.StructuredContent != nil {
+		t.Errorf("expected no structuredContent for 2025-03-26, got %#v", result.StructuredContent)
+	}
+}
+
+func TestToolResult_TypedContent(t *testing.T) {
+	result := callTyped(t, ProtocolVersion20250618, "link")
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	var decoded struct {
+		Content []map[string]interface{} `json:"content"`
+	}
+	json.Unmarshal(data, &decoded)
+
+	link := decoded.Content[0]
+	if link["type"] != "resource_link" || link["uri"] != "typed://rec1" || link["name"] != "rec1" {
+		t.Errorf("unexpected resource_link block: %v", link)
+	}
+	if _, ok := link["text"]; ok {
+		t.Errorf("resource_link must not carry text: %v", link)
+	}
+
+	image := decoded.Content[1]
+	if image["type"] != "image" || image["data"] != "iVBORw==" || image["mimeType"] != "image/png" {
+		t.Errorf("unexpected image block: %v", image)
+	}
+
+	// Older clients get the link as text
+	result = callTyped(t, ProtocolVersion20250326, "link")
+	if block := result.Content[0]; block.Type != "text" || block.Text != "rec1: typed://rec1" {
+		t.Errorf("expected resource_link downgraded to text, got %+v", block)
+	}
+}
+
+func TestContentBlock_EmptyTextIsKept(t *testing.T) {
+	data, _ := json.Marshal(modules.TextContent(""))
+	if string(data) != `{"type":"text","text":""}` {
+		t.Errorf("unexpected JSON for empty text block: %s", data)
+	}
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// ExposureMode controls how module tools appear in tools/list
+type ExposureMode string
+
+const (
+	// ExposureMeta lists only get_module_schema and call_module_tool, which
+	// load module tools lazily
+	ExposureMeta ExposureMode = "meta"
+	// ExposureFlat lists every module tool directly as module__tool
+	ExposureFlat ExposureMode = "flat"
+	// ExposureHybrid lists the meta tools plus the tools of the modules a
+	// session expanded by calling get_module_schema
+	ExposureHybrid ExposureMode = "hybrid"
+)
+
+// ParseExposureMode parses a mode name; the empty string means ExposureMeta
+func ParseExposureMode(s string) (ExposureMode, error) {
+	switch mode := ExposureMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ExposureMeta, nil
+	case ExposureMeta, ExposureFlat, ExposureHybrid:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown tool exposure mode %q (want meta, flat or hybrid)", s)
+	}
+}
+
+// Option configures a Handler
+type Option func(*Handler)
+
+// WithExposureMode sets how module tools are listed; the default is ExposureMeta
+func WithExposureMode(mode ExposureMode) Option {
+	return func(h *Handler) {
+		h.exposure = mode
+	}
+}
+
+// listedTools returns the tools the session sees in tools/list
+func (h *Handler) listedTools(session *Session) []modules.Tool {
+	tools := make([]modules.Tool, 0)
+	if h.exposure != ExposureFlat {
+		tools = append(tools, h.registry.MetaTools()...)
+	}
+	if h.exposure == ExposureMeta {
+		return tools
+	}
+
+	for _, module := range h.registry.Modules() {
+		if h.exposure == ExposureHybrid && !session.isExpanded(module.Name) {
+			continue
+		}
+		for _, tool := range module.Tools {
+			tool.Name = module.Name + modules.NameSeparator + tool.Name
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// exposedTool resolves a module__tool name the session is allowed to call
+func (h *Handler) exposedTool(session *Session, name string) (string, string, bool) {
+	if h.exposure == ExposureMeta {
+		return "", "", false
+	}
+	moduleName, toolName, ok := strings.Cut(name, modules.NameSeparator)
+	if !ok {
+		return "", "", false
+	}
+	if h.exposure == ExposureHybrid && !session.isExpanded(moduleName) {
+		return "", "", false
+	}
+	return moduleName, toolName, true
+}
+
+// expandModule adds a module's tools to the session's tools/list in hybrid
+// mode and tells the client to refetch the list
+func (h *Handler) expandModule(ctx context.Context, session *Session, moduleName string) {
+	if h.exposure != ExposureHybrid || !session.expandModule(moduleName) {
+		return
+	}
+
+	notification := Notification{JSONRPC: "2.0", Method: "notifications/tools/list_changed"}
+	if notify := notifierFrom(ctx); notify != nil {
+		notify(notification)
+		return
+	}
+	h.queueMessage(session, notification)
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func toolNames(t *testing.T, handler *Handler, session *Session) map[string]bool {
+	t.Helper()
+	list := call(t, handler, session, "tools/list", nil).Result.(*ToolsListResult)
+	names := make(map[string]bool, len(list.Tools))
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func callTool(t *testing.T, handler *Handler, session *Session, name string, args map[string]interface{}) *Response {
+	t.Helper()
+	return call(t, handler, session, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+}
+
+func TestParseExposureMode(t *testing.T) {
+	for input, want := range map[string]ExposureMode{"": ExposureMeta, "flat": ExposureFlat, " Hybrid ": ExposureHybrid} {
+		if got, err := ParseExposureMode(input); err != nil || got != want {
+			t.Errorf("ParseExposureMode(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseExposureMode("all"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestExposure_MetaListsOnlyMetaTools(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	names := toolNames(t, handler, session)
+	if len(names) != 3 || !names["get_module_schema"] || !names["call_module_tool"] || !names["search_tools"] {
+		t.Errorf("tools = %v", names)
+	}
+	if resp := callTool(t, handler, session, "test__echo", nil); resp.Error == nil {
+		t.Error("module tools must not be callable directly in meta mode")
+	}
+}
+
+func TestExposure_FlatListsEveryModuleTool(t *testing.T) {
+	handler := NewHandler(newTestRegistry(t), WithExposureMode(ExposureFlat))
+	session := initializedSession(LatestProtocolVersion)
+
+	names := toolNames(t, handler, session)
+	if names["get_module_schema"] || !names["test__echo"] || !names["typed__record"] {
+		t.Errorf("tools = %v", names)
+	}
+
+	resp := callTool(t, handler, session, "test__echo", map[string]interface{}{"message": "hi"})
+	if resp.Error != nil || resp.Result.(*ToolCallResult).Content[0].Text != "Echo: hi" {
+		t.Errorf("test__echo = %+v", resp)
+	}
+}
+
+func TestExposure_HybridExpandsModulesPerSession(t *testing.T) {
+	handler := NewHandler(newTestRegistry(t), WithExposureMode(ExposureHybrid))
+	session, _ := handler.sessions.Create(TransportStreamableHTTP)
+	session.initialize(LatestProtocolVersion, ClientInfo{Name: "hybrid"}, ClientCapabilities{})
+	other, _ := handler.sessions.Create(TransportStreamableHTTP)
+	other.initialize(LatestProtocolVersion, ClientInfo{Name: "other"}, ClientCapabilities{})
+
+	if names := toolNames(t, handler, session); len(names) != 3 {
+		t.Fatalf("tools before expansion = %v", names)
+	}
+	if resp := callTool(t, handler, session, "test__echo", nil); resp.Error == nil {
+		t.Error("unexpanded module tools must not be callable")
+	}
+
+	callTool(t, handler, session, "get_module_schema", map[string]interface{}{"module": "test"})
+	if len(session.messages) != 1 {
+		t.Fatalf("expected one list_changed notification, got %d", len(session.messages))
+	}
+	var notification Notification
+	json.Unmarshal(<-session.messages, &notification)
+	if notification.Method != "notifications/tools/list_changed" {
+		t.Errorf("notification = %+v", notification)
+	}
+
+	names := toolNames(t, handler, session)
+	if !names["get_module_schema"] || !names["test__echo"] || names["typed__record"] {
+		t.Errorf("tools after expansion = %v", names)
+	}
+	if resp := callTool(t, handler, session, "test__echo", map[string]interface{}{"message": "hi"}); resp.Error != nil {
+		t.Errorf("test__echo = %+v", resp.Error)
+	}
+
+	// Expanding again changes nothing, and other sessions are unaffected
+	callTool(t, handler, session, "get_module_schema", map[string]interface{}{"module": "test"})
+	callTool(t, handler, session, "get_module_schema", map[string]interface{}{"module": "missing"})
+	if len(session.messages) != 0 {
+		t.Errorf("unexpected notifications: %d", len(session.messages))
+	}
+	if names := toolNames(t, handler, other); names["test__echo"] {
+		t.Error("expansion leaked into another session")
+	}
+}
@@ -1,41 +1,74 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/shibaleo/go-mcp-dev/internal/modules"
 )
 
 type Handler struct {
-	sessions map[string]*Session
-	mu       sync.RWMutex
+	sessions *SessionManager
+	registry *modules.Registry
+	exposure ExposureMode
 }
 
-type Session struct {
-	id       string
-	writer   http.ResponseWriter
-	flusher  http.Flusher
-	done     chan struct{}
-	messages chan []byte
+// NewHandler returns a handler serving the modules in registry. Modules
+// added or removed later are announced to connected clients.
+func NewHandler(registry *modules.Registry, opts ...Option) *Handler {
+	h := &Handler{
+		sessions: NewSessionManager(DefaultSessionIdleTimeout),
+		registry: registry,
+		exposure: ExposureMeta,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	registry.Subscribe(h.modulesChanged)
+	return h
 }
 
-func NewHandler() *Handler {
-	return &Handler{
-		sessions: make(map[string]*Session),
-	}
+// Sessions returns a snapshot of all live sessions
+func (h *Handler) Sessions() []SessionInfo {
+	return h.sessions.List()
+}
+
+// SessionsHandler serves the live session list as JSON for admin introspection
+func (h *Handler) SessionsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sessions": h.Sessions(),
+		})
+	})
 }
 
+// ServeHTTP serves both MCP transports on a single endpoint:
+//   - Streamable HTTP (2025-03-26+): POST /mcp, GET /mcp with Mcp-Session-Id, DELETE /mcp
+//   - HTTP+SSE (2024-11-05): GET /mcp opens the stream, POST /mcp?sessionId=... sends messages
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		h.handleSSE(w, r)
+		if r.Header.Get(sessionIDHeader) != "" {
+			h.handleStream(w, r)
+		} else {
+			h.handleSSE(w, r)
+		}
 	case http.MethodPost:
 		h.handleMessage(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
@@ -48,55 +81,87 @@ func (h *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	// Create session
-	sessionID := fmt.Sprintf("%d", r.Context().Value("session_id"))
-	if sessionID == "<nil>" {
-		sessionID = fmt.Sprintf("%p", r)
+	session, err := h.sessions.Create(TransportSSE)
+	if err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
 	}
+	defer h.sessions.Close(session.id)
+
+	setSSEHeaders(w)
+
+	// Send endpoint event (MCP SSE protocol)
+	fmt.Fprintf(w, "event: endpoint\ndata: /mcp?sessionId=%s\n\n", session.id)
+	flusher.Flush()
+	log.Printf("SSE connection established, session=%s", session.id)
+
+	h.pumpMessages(w, flusher, r, session)
+	log.Printf("SSE connection closed, session=%s", session.id)
+}
 
-	session := &Session{
-		id:       sessionID,
-		writer:   w,
-		flusher:  flusher,
-		done:     make(chan struct{}),
-		messages: make(chan []byte, 100),
+// handleStream opens the server-initiated SSE stream of a Streamable HTTP session
+func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "Not Acceptable: client must accept text/event-stream", http.StatusNotAcceptable)
+		return
 	}
 
-	h.mu.Lock()
-	h.sessions[sessionID] = session
-	h.mu.Unlock()
+	session, ok := h.sessions.Get(r.Header.Get(sessionIDHeader))
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
 
-	defer func() {
-		h.mu.Lock()
-		delete(h.sessions, sessionID)
-		h.mu.Unlock()
-		close(session.done)
-	}()
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
 
-	// Send endpoint event (MCP SSE protocol)
-	fmt.Fprintf(w, "event: endpoint\ndata: /mcp?sessionId=%s\n\n", sessionID)
+	setSSEHeaders(w)
+	w.WriteHeader(http.StatusOK)
 	flusher.Flush()
-	log.Printf("SSE connection established, session=%s", sessionID)
+	log.Printf("Stream opened, session=%s", session.id)
+
+	h.pumpMessages(w, flusher, r, session)
+	log.Printf("Stream closed, session=%s", session.id)
+}
+
+// pumpMessages forwards queued session messages to the client until the
+// request is cancelled or the session ends
+func (h *Handler) pumpMessages(w http.ResponseWriter, flusher http.Flusher, r *http.Request, session *Session) {
+	session.attachStream()
+	defer session.detachStream()
 
-	// Keep connection open and send messages
 	for {
 		select {
 		case msg := <-session.messages:
-			fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
-			flusher.Flush()
+			writeEvent(w, flusher, msg)
+		case <-session.done:
+			return
 		case <-r.Context().Done():
-			log.Printf("SSE connection closed, session=%s", sessionID)
 			return
 		}
 	}
 }
 
+// handleDelete terminates a Streamable HTTP session
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(sessionIDHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing "+sessionIDHeader+" header", http.StatusBadRequest)
+		return
+	}
+
+	if !h.sessions.Close(sessionID) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("Session terminated by client, session=%s", sessionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.URL.Query().Get("sessionId")
 	if sessionID == "" {
@@ -104,10 +169,7 @@ func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.mu.RLock()
-	session, ok := h.sessions[sessionID]
-	h.mu.RUnlock()
-
+	session, ok := h.sessions.Get(sessionID)
 	if !ok {
 		http.Error(w, "Session not found", http.StatusNotFound)
 		return
@@ -119,6 +181,21 @@ func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Progress and other request-scoped notifications go out on the SSE stream
+	ctx := withNotifier(r.Context(), func(msg interface{}) { h.queueMessage(session, msg) })
+
+	if isBatch(body) {
+		log.Printf("Received batch request, session=%s", sessionID)
+		responses, rpcErr := h.processBatch(ctx, session, body)
+		if rpcErr != nil {
+			h.sendToSession(session, nil, rpcErr)
+		} else if len(responses) > 0 {
+			h.queueMessage(session, responses)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	var req Request
 	if err := json.Unmarshal(body, &req); err != nil {
 		h.sendToSession(session, nil, &Error{Code: ParseError, Message: "Parse error"})
@@ -128,16 +205,18 @@ func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Received request: method=%s id=%v session=%s", req.Method, req.ID, sessionID)
 
-	result, rpcErr := h.processRequest(&req)
-	if rpcErr != nil {
-		h.sendToSession(session, req.ID, rpcErr)
-	} else if req.ID != nil {
-		h.sendResultToSession(session, req.ID, result)
+	if resp := h.respond(ctx, session, &req); resp != nil {
+		h.queueMessage(session, resp)
 	}
 
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// handleInlineMessage handles a Streamable HTTP POST. The response is written
+// inline, either as application/json or as a single-event SSE stream.
+// initialize creates a session and returns its ID in the Mcp-Session-Id header
+// when the negotiated version supports it. Any other request without the
+// header is rejected with 400, so nothing bypasses the handshake.
 func (h *Handler) handleInlineMessage(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -145,41 +224,103 @@ func (h *Handler) handleInlineMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	batch := isBatch(body)
+
 	var req Request
-	if err := json.Unmarshal(body, &req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		resp := Response{JSONRPC: "2.0", Error: &Error{Code: ParseError, Message: "Parse error"}}
-		json.NewEncoder(w).Encode(resp)
+	if !batch {
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			resp := Response{JSONRPC: "2.0", Error: &Error{Code: ParseError, Message: "Parse error"}}
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+	}
+
+	headerVersion := r.Header.Get(protocolVersionHeader)
+	if headerVersion != "" && !isSupportedVersion(headerVersion) {
+		http.Error(w, "Unsupported "+protocolVersionHeader+": "+headerVersion, http.StatusBadRequest)
+		return
+	}
+
+	var session *Session
+	created := false
+	if sessionID := r.Header.Get(sessionIDHeader); sessionID != "" {
+		s, ok := h.sessions.Get(sessionID)
+		if !ok {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		if headerVersion != "" && s.features().versionHeader && headerVersion != s.ProtocolVersion() {
+			http.Error(w, protocolVersionHeader+" does not match the negotiated version", http.StatusBadRequest)
+			return
+		}
+		session = s
+	} else if !batch && req.Method == "initialize" {
+		s, err := h.sessions.Create(TransportStreamableHTTP)
+		if err != nil {
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+		session = s
+		created = true
+	} else {
+		http.Error(w, "Missing "+sessionIDHeader+" header: send initialize first", http.StatusBadRequest)
+		return
+	}
+
+	if !created {
+		w.Header().Set(sessionIDHeader, session.id)
+		w.Header().Set("Access-Control-Expose-Headers", sessionIDHeader)
+	}
+
+	iw := newInlineWriter(w, r)
+	ctx := withNotifier(r.Context(), iw.notify)
+
+	if batch {
+		log.Printf("Received inline batch request")
+
+		responses, rpcErr := h.processBatch(ctx, session, body)
+		switch {
+		case rpcErr != nil:
+			iw.write(Response{JSONRPC: "2.0", Error: rpcErr}, false)
+		case len(responses) == 0:
+			iw.accepted()
+		default:
+			iw.write(responses, batchHasToolCall(body))
+		}
 		return
 	}
 
 	log.Printf("Received inline request: method=%s id=%v", req.Method, req.ID)
 
-	result, rpcErr := h.processRequest(&req)
+	resp := h.respond(ctx, session, &req)
 
-	w.Header().Set("Content-Type", "application/json")
-	var resp Response
-	if rpcErr != nil {
-		resp = Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
-	} else {
-		resp = Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if created {
+		if resp == nil || resp.Error != nil || !session.features().streamableHTTP {
+			// 2024-11-05 clients do not know Mcp-Session-Id; they go on over HTTP+SSE
+			h.sessions.Close(session.id)
+		} else {
+			w.Header().Set(sessionIDHeader, session.id)
+			w.Header().Set("Access-Control-Expose-Headers", sessionIDHeader)
+		}
+	}
+
+	// Notifications and responses get no body
+	if resp == nil {
+		iw.accepted()
+		return
 	}
-	json.NewEncoder(w).Encode(resp)
+
+	iw.write(resp, req.Method == "tools/call")
 }
 
 func (h *Handler) sendToSession(session *Session, id interface{}, err *Error) {
-	resp := Response{JSONRPC: "2.0", ID: id, Error: err}
-	data, _ := json.Marshal(resp)
-	select {
-	case session.messages <- data:
-	default:
-		log.Printf("Session message buffer full")
-	}
+	h.queueMessage(session, Response{JSONRPC: "2.0", ID: id, Error: err})
 }
 
-func (h *Handler) sendResultToSession(session *Session, id interface{}, result interface{}) {
-	resp := Response{JSONRPC: "2.0", ID: id, Result: result}
-	data, _ := json.Marshal(resp)
+// queueMessage queues a message for the session's SSE stream
+func (h *Handler) queueMessage(session *Session, msg interface{}) {
+	data, _ := json.Marshal(msg)
 	select {
 	case session.messages <- data:
 	default:
@@ -187,40 +328,294 @@ func (h *Handler) sendResultToSession(session *Session, id interface{}, result i
 	}
 }
 
-func (h *Handler) processRequest(req *Request) (interface{}, *Error) {
+// methodsBeforeInitialize may be sent before the initialize handshake completes
+var methodsBeforeInitialize = map[string]bool{
+	"initialize":                true,
+	"initialized":               true,
+	"notifications/initialized": true,
+	"ping":                      true,
+}
+
+// errRequestCancelled is returned for requests aborted by notifications/cancelled.
+// The spec forbids answering them, so respond drops the response.
+var errRequestCancelled = &Error{Code: InternalError, Message: "Request cancelled"}
+
+func (h *Handler) processRequest(ctx context.Context, session *Session, req *Request) (interface{}, *Error) {
+	if session.State() != SessionInitialized && !methodsBeforeInitialize[req.Method] {
+		return nil, &Error{Code: InvalidRequest, Message: fmt.Sprintf("Session is %s: send initialize first", session.State())}
+	}
+
 	switch req.Method {
 	case "initialize":
-		return h.handleInitialize(req), nil
-	case "initialized":
+		return h.handleInitialize(session, req)
+	case "initialized", "notifications/initialized":
 		return nil, nil
+	case "notifications/cancelled":
+		h.handleCancelled(session, req)
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
 	case "tools/list":
-		return h.handleToolsList(), nil
-	case "tools/call":
-		return h.handleToolCall(req)
+		return h.handleToolsList(session), nil
+	case "tools/call", "resources/list", "resources/read", "prompts/get", "completion/complete":
+		return h.processCancellable(ctx, session, req)
+	case "prompts/list":
+		return &PromptsListResult{Prompts: h.registry.ListPrompts()}, nil
+	case "resources/templates/list":
+		return &ResourceTemplatesListResult{ResourceTemplates: h.registry.ResourceTemplates()}, nil
+	case "resources/subscribe":
+		return h.handleSubscribe(session, req, true)
+	case "resources/unsubscribe":
+		return h.handleSubscribe(session, req, false)
 	default:
 		return nil, &Error{Code: MethodNotFound, Message: "Method not found"}
 	}
 }
 
-func (h *Handler) handleInitialize(req *Request) *InitializeResult {
+// processCancellable runs a request that calls external APIs, so that
+// notifications/cancelled and session termination can abort it
+func (h *Handler) processCancellable(ctx context.Context, session *Session, req *Request) (interface{}, *Error) {
+	if req.ID != nil {
+		var finish func()
+		ctx, finish = session.beginRequest(ctx, req.ID)
+		defer finish()
+	}
+
+	var result interface{}
+	var rpcErr *Error
+	switch req.Method {
+	case "tools/call":
+		var toolResult *ToolCallResult
+		if toolResult, rpcErr = h.handleToolCall(ctx, session, req); rpcErr == nil {
+			result = adaptToolResult(session, toolResult)
+		}
+	case "resources/list":
+		result = &ResourcesListResult{Resources: h.registry.ListResources(ctx)}
+	case "resources/read":
+		var readResult *ReadResourceResult
+		if readResult, rpcErr = h.handleResourceRead(ctx, req); rpcErr == nil {
+			result = readResult
+		}
+	case "prompts/get":
+		var promptResult *modules.PromptResult
+		if promptResult, rpcErr = h.handlePromptGet(ctx, req); rpcErr == nil {
+			result = promptResult
+		}
+	case "completion/complete":
+		var completeResult *CompleteResult
+		if completeResult, rpcErr = h.handleComplete(ctx, session, req); rpcErr == nil {
+			result = completeResult
+		}
+	}
+
+	if req.ID != nil && ctx.Err() != nil {
+		return nil, errRequestCancelled
+	}
+	return result, rpcErr
+}
+
+// decodeParams converts the generic params of a request into v
+func decodeParams(req *Request, v interface{}) *Error {
+	paramsBytes, err := json.Marshal(req.Params)
+	if err != nil {
+		return &Error{Code: InvalidParams, Message: "Invalid params"}
+	}
+	if err := json.Unmarshal(paramsBytes, v); err != nil {
+		return &Error{Code: InvalidParams, Message: "Invalid params structure"}
+	}
+	return nil
+}
+
+// handleCancelled aborts the in-flight request named by notifications/cancelled.
+// Unknown or already finished requests are ignored, as the spec allows.
+func (h *Handler) handleCancelled(session *Session, req *Request) {
+	var params CancelledParams
+	if req.Params != nil {
+		paramsBytes, _ := json.Marshal(req.Params)
+		json.Unmarshal(paramsBytes, &params)
+	}
+	if params.RequestID == nil {
+		return
+	}
+
+	if session.cancelRequest(params.RequestID) {
+		log.Printf("Request cancelled: id=%v reason=%q session=%s", params.RequestID, params.Reason, session.id)
+	}
+}
+
+func (h *Handler) handleInitialize(session *Session, req *Request) (*InitializeResult, *Error) {
+	var params InitializeParams
+	if req.Params != nil {
+		paramsBytes, err := json.Marshal(req.Params)
+		if err != nil {
+			return nil, &Error{Code: InvalidParams, Message: "Invalid params"}
+		}
+		if err := json.Unmarshal(paramsBytes, &params); err != nil {
+			return nil, &Error{Code: InvalidParams, Message: "Invalid params structure"}
+		}
+	}
+
+	version := negotiateVersion(params.ProtocolVersion)
+	if err := session.initialize(version, params.ClientInfo, params.Capabilities); err != nil {
+		return nil, &Error{Code: InvalidRequest, Message: fmt.Sprintf("Cannot initialize: %v", err)}
+	}
+	log.Printf("Initialize: client=%s/%s requested=%s negotiated=%s",
+		params.ClientInfo.Name, params.ClientInfo.Version, params.ProtocolVersion, version)
+
+	capabilities := ServerCapabilities{
+		Tools:     &ToolsCapability{ListChanged: true},
+		Resources: &ResourcesCapability{Subscribe: true, ListChanged: true},
+		Prompts:   &PromptsCapability{ListChanged: true},
+	}
+	if session.features().completions {
+		capabilities.Completions = &struct{}{}
+	}
+
 	return &InitializeResult{
-		ProtocolVersion: "2024-11-05",
-		Capabilities: ServerCapabilities{
-			Tools: &ToolsCapability{},
-		},
+		ProtocolVersion: version,
+		Capabilities:    capabilities,
 		ServerInfo: ServerInfo{
 			Name:    "go-mcp-dev",
 			Version: "0.2.0",
 		},
+	}, nil
+}
+
+func (h *Handler) handleResourceRead(ctx context.Context, req *Request) (*ReadResourceResult, *Error) {
+	var params ResourceParams
+	if rpcErr := decodeParams(req, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if params.URI == "" {
+		return nil, &Error{Code: InvalidParams, Message: "uri is required"}
+	}
+
+	contents, err := h.registry.ReadResource(ctx, params.URI)
+	if errors.Is(err, modules.ErrResourceNotFound) {
+		return nil, &Error{Code: ResourceNotFound, Message: "Resource not found", Data: map[string]string{"uri": params.URI}}
+	}
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+	return &ReadResourceResult{Contents: contents}, nil
+}
+
+func (h *Handler) handlePromptGet(ctx context.Context, req *Request) (*modules.PromptResult, *Error) {
+	var params GetPromptParams
+	if rpcErr := decodeParams(req, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	result, err := h.registry.GetPrompt(ctx, params.Name, params.Arguments)
+	if err != nil {
+		// Unknown prompts and missing arguments are both invalid params
+		return nil, &Error{Code: InvalidParams, Message: err.Error()}
+	}
+	return result, nil
+}
+
+func (h *Handler) handleComplete(ctx context.Context, session *Session, req *Request) (*CompleteResult, *Error) {
+	var params CompleteParams
+	if rpcErr := decodeParams(req, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	var args map[string]string
+	if params.Context != nil {
+		args = params.Context.Arguments
+	}
+
+	var load func() ([]string, error)
+	switch params.Ref.Type {
+	case "ref/prompt":
+		load = func() ([]string, error) {
+			return h.registry.PromptArgumentCandidates(ctx, params.Ref.Name, params.Argument.Name, args)
+		}
+	case "ref/resource":
+		load = func() ([]string, error) {
+			return h.registry.ResourceArgumentCandidates(ctx, params.Ref.URI, params.Argument.Name, args)
+		}
+	default:
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Unsupported ref type: %s", params.Ref.Type)}
+	}
+
+	key := completionKey(params.Ref.Type+" "+params.Ref.Name+params.Ref.URI, params.Argument.Name, args)
+	candidates, err := session.completions.get(key, time.Now(), load)
+	if errors.Is(err, modules.ErrPromptNotFound) || errors.Is(err, modules.ErrResourceNotFound) || errors.Is(err, modules.ErrArgumentNotFound) {
+		return nil, &Error{Code: InvalidParams, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+
+	values, total := modules.FilterCompletions(candidates, params.Argument.Value)
+	return &CompleteResult{Completion: Completion{Values: values, Total: total, HasMore: total > len(values)}}, nil
+}
+
+// handleSubscribe records interest in resources/updated notifications for a URI
+func (h *Handler) handleSubscribe(session *Session, req *Request, subscribe bool) (interface{}, *Error) {
+	var params ResourceParams
+	if rpcErr := decodeParams(req, &params); rpcErr != nil {
+		return nil, rpcErr
+	}
+	if params.URI == "" {
+		return nil, &Error{Code: InvalidParams, Message: "uri is required"}
 	}
+
+	session.setSubscribed(params.URI, subscribe)
+	return struct{}{}, nil
 }
 
-func (h *Handler) handleToolsList() *ToolsListResult {
-	// Return only meta tools (lazy loading)
-	return &ToolsListResult{Tools: modules.MetaTools()}
+// notifyResourceUpdated sends notifications/resources/updated to every
+// session subscribed to uri
+func (h *Handler) notifyResourceUpdated(uri string) {
+	notification := Notification{
+		JSONRPC: "2.0",
+		Method:  "notifications/resources/updated",
+		Params:  ResourceParams{URI: uri},
+	}
+	for _, session := range h.sessions.all() {
+		if session.isSubscribed(uri) {
+			h.queueMessage(session, notification)
+		}
+	}
+}
+
+// modulesChanged tells every session that the lists derived from the
+// registry changed: the meta tool descriptions name the modules, and
+// resources and prompts come from them
+func (h *Handler) modulesChanged(event modules.ChangeEvent) {
+	log.Printf("Module %s: %s", event.Kind, event.Module.Name)
+	for _, method := range []string{
+		"notifications/tools/list_changed",
+		"notifications/resources/list_changed",
+		"notifications/prompts/list_changed",
+	} {
+		notification := Notification{JSONRPC: "2.0", Method: method}
+		for _, session := range h.sessions.all() {
+			if session.State() == SessionInitialized {
+				h.queueMessage(session, notification)
+			}
+		}
+	}
 }
 
-func (h *Handler) handleToolCall(req *Request) (*ToolCallResult, *Error) {
+func (h *Handler) handleToolsList(session *Session) *ToolsListResult {
+	tools := h.listedTools(session)
+
+	features := session.features()
+	for i := range tools {
+		if !features.toolAnnotations {
+			tools[i].Annotations = nil
+		}
+		if !features.structuredOutput {
+			tools[i].OutputSchema = nil
+		}
+	}
+
+	return &ToolsListResult{Tools: tools}
+}
+
+func (h *Handler) handleToolCall(ctx context.Context, session *Session, req *Request) (*ToolCallResult, *Error) {
 	paramsBytes, err := json.Marshal(req.Params)
 	if err != nil {
 		return nil, &Error{Code: InvalidParams, Message: "Invalid params"}
@@ -231,31 +626,146 @@ func (h *Handler) handleToolCall(req *Request) (*ToolCallResult, *Error) {
 		return nil, &Error{Code: InvalidParams, Message: "Invalid params structure"}
 	}
 
+	if params.Meta != nil && req.ID != nil {
+		ctx = withProgress(ctx, session, params.Meta.ProgressToken)
+	}
+	ctx = modules.WithResourceNotifier(ctx, h.notifyResourceUpdated)
+
 	switch params.Name {
 	case "get_module_schema":
-		return h.handleGetModuleSchema(params.Arguments)
+		return h.handleGetModuleSchema(ctx, session, params.Arguments)
 	case "call_module_tool":
-		return h.handleCallModuleTool(params.Arguments)
-	default:
-		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}
+		return h.handleCallModuleTool(ctx, params.Arguments)
+	case "search_tools":
+		return h.handleSearchTools(params.Arguments)
+	}
+
+	if moduleName, toolName, ok := h.exposedTool(session, params.Name); ok {
+		return h.callModuleTool(ctx, moduleName, toolName, params.Arguments)
+	}
+	return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}
+}
+
+// adaptToolResult drops result features the negotiated version does not know.
+// resource_link blocks are turned into text so older clients still see the URI.
+func adaptToolResult(session *Session, result *ToolCallResult) *ToolCallResult {
+	features := session.features()
+	if result == nil || features.structuredOutput && features.resourceLinks {
+		return result
+	}
+
+	adapted := *result
+	if !features.structuredOutput {
+		adapted.StructuredContent = nil
+	}
+	if !features.resourceLinks {
+		adapted.Content = make([]ContentBlock, len(result.Content))
+		for i, block := range result.Content {
+			if block.Type == "resource_link" {
+				block = modules.TextContent(fmt.Sprintf("%s: %s", block.Name, block.URI))
+			}
+			adapted.Content[i] = block
+		}
 	}
+	return &adapted
 }
 
-func (h *Handler) handleGetModuleSchema(args map[string]interface{}) (*ToolCallResult, *Error) {
+func (h *Handler) handleGetModuleSchema(ctx context.Context, session *Session, args map[string]interface{}) (*ToolCallResult, *Error) {
 	moduleName, ok := args["module"].(string)
 	if !ok {
 		return nil, &Error{Code: InvalidParams, Message: "module must be a string"}
 	}
 
-	result, err := modules.GetModuleSchema(moduleName)
+	format, _ := args["format"].(string)
+	schemaFormat, err := modules.ParseSchemaFormat(format)
+	if err != nil {
+		return nil, &Error{Code: InvalidParams, Message: err.Error()}
+	}
+	opts := modules.SchemaOptions{Format: schemaFormat}
+	if tools, ok := args["tools"].([]interface{}); ok {
+		for _, tool := range tools {
+			name, ok := tool.(string)
+			if !ok {
+				return nil, &Error{Code: InvalidParams, Message: "tools must be an array of strings"}
+			}
+			opts.Tools = append(opts.Tools, name)
+		}
+	}
+
+	result, err := h.registry.GetModuleSchema(moduleName, opts)
 	if err != nil {
 		return nil, &Error{Code: InternalError, Message: err.Error()}
 	}
+	if !result.IsError {
+		h.expandModule(ctx, session, moduleName)
+	}
 
 	return result, nil
 }
 
-func (h *Handler) handleCallModuleTool(args map[string]interface{}) (*ToolCallResult, *Error) {
+func (h *Handler) handleSearchTools(args map[string]interface{}) (*ToolCallResult, *Error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return nil, &Error{Code: InvalidParams, Message: "query must be a non-empty string"}
+	}
+	moduleName, _ := args["module"].(string)
+	if moduleName != "" {
+		if _, ok := h.registry.Lookup(moduleName); !ok {
+			return &ToolCallResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown module: %s", moduleName)}},
+				IsError: true,
+			}, nil
+		}
+	}
+	limit := 0
+	if n, ok := args["limit"].(float64); ok {
+		limit = int(n)
+	}
+
+	type searchResult struct {
+		Module      string            `json:"module"`
+		Tool        string            `json:"tool"`
+		Call        string            `json:"call,omitempty"`
+		Description string            `json:"description"`
+		Params      map[string]string `json:"params"`
+	}
+	results := make([]searchResult, 0)
+	for _, match := range h.registry.SearchTools(query, moduleName, limit) {
+		result := searchResult{
+			Module:      match.Module,
+			Tool:        match.Tool.Name,
+			Description: match.Tool.Description,
+			Params:      modules.CompactSchema(match.Tool.InputSchema),
+		}
+		if h.exposure == ExposureFlat {
+			result.Call = match.Module + modules.NameSeparator + match.Tool.Name
+		}
+		results = append(results, result)
+	}
+
+	hint := "Call a result with call_module_tool using its module and tool. Use get_module_schema for the full input schema."
+	if h.exposure == ExposureFlat {
+		hint = "Call a result directly by its call name."
+	}
+	if len(results) == 0 {
+		hint = "No tools matched. Try other words, or use get_module_schema to browse a module."
+	}
+
+	jsonBytes, err := json.MarshalIndent(struct {
+		Query   string         `json:"query"`
+		Results []searchResult `json:"results"`
+		Hint    string         `json:"hint"`
+	}{query, results, hint}, "", "  ")
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+
+	return &ToolCallResult{
+		Content: []ContentBlock{{Type: "text", Text: string(jsonBytes)}},
+	}, nil
+}
+
+func (h *Handler) handleCallModuleTool(ctx context.Context, args map[string]interface{}) (*ToolCallResult, *Error) {
 	moduleName, ok := args["module"].(string)
 	if !ok {
 		return nil, &Error{Code: InvalidParams, Message: "module must be a string"}
@@ -267,11 +777,15 @@ func (h *Handler) handleCallModuleTool(args map[string]interface{}) (*ToolCallRe
 	}
 
 	params, _ := args["params"].(map[string]interface{})
+	return h.callModuleTool(ctx, moduleName, toolName, params)
+}
+
+func (h *Handler) callModuleTool(ctx context.Context, moduleName, toolName string, params map[string]interface{}) (*ToolCallResult, *Error) {
 	if params == nil {
 		params = make(map[string]interface{})
 	}
 
-	result, err := modules.CallModuleTool(moduleName, toolName, params)
+	result, err := h.registry.CallModuleTool(ctx, moduleName, toolName, params)
 	if err != nil {
 		return nil, &Error{Code: InternalError, Message: err.Error()}
 	}
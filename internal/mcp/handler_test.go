@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,34 +11,65 @@ import (
 	"github.com/shibaleo/go-mcp-dev/internal/modules"
 )
 
-func init() {
-	// Register a test module for testing
-	modules.Register(modules.ModuleDefinition{
-		Name:        "test",
-		Description: "Test module",
-		Tools: []modules.Tool{
-			{
-				Name:        "echo",
-				Description: "Echo back the input",
-				InputSchema: modules.InputSchema{
-					Type: "object",
-					Properties: map[string]modules.Property{
-						"message": {Type: "string", Description: "Message to echo"},
-					},
+// testModule is a test module for testing
+var testModule = modules.ModuleDefinition{
+	Name:        "test",
+	Description: "Test module",
+	Tools: []modules.Tool{
+		{
+			Name:        "echo",
+			Description: "Echo back the input",
+			InputSchema: modules.InputSchema{
+				Type: "object",
+				Properties: map[string]modules.Property{
+					"message": {Type: "string", Description: "Message to echo"},
 				},
 			},
 		},
-		Handlers: map[string]modules.ToolHandler{
-			"echo": func(params map[string]interface{}) (string, error) {
-				msg, _ := params["message"].(string)
-				return "Echo: " + msg, nil
-			},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"echo": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			msg, _ := params["message"].(string)
+			return "Echo: " + msg, nil
 		},
-	})
+	},
+}
+
+// testModules are registered into a fresh registry for every test handler,
+// so tests never share registry state
+func testModules() []modules.ModuleDefinition {
+	return []modules.ModuleDefinition{
+		testModule, slowModule, blockModule, typedModule, stepsModule,
+		promptsModule, docsModule,
+	}
+}
+
+func newTestRegistry(t *testing.T) *modules.Registry {
+	t.Helper()
+	registry := modules.NewRegistry()
+	for _, module := range testModules() {
+		if err := registry.Register(module); err != nil {
+			t.Fatalf("register %s: %v", module.Name, err)
+		}
+	}
+	return registry
+}
+
+// initializedSession returns an unregistered session that completed the
+// handshake at version, for calling the handler directly
+func initializedSession(version string) *Session {
+	session := newSession("", TransportStreamableHTTP)
+	session.initialize(version, ClientInfo{Name: "test"}, ClientCapabilities{})
+	return session
+}
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	return NewHandler(newTestRegistry(t))
 }
 
 func TestHandleInlineMessage_Initialize(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0"}}}`
 
@@ -81,12 +113,13 @@ func TestHandleInlineMessage_Initialize(t *testing.T) {
 }
 
 func TestHandleInlineMessage_ToolsList(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -114,19 +147,20 @@ func TestHandleInlineMessage_ToolsList(t *testing.T) {
 		t.Fatalf("unexpected tools type: %T", resultMap["tools"])
 	}
 
-	// Should return 2 meta tools: get_module_schema and call_module_tool
-	if len(tools) != 2 {
-		t.Errorf("expected 2 meta tools, got %d", len(tools))
+	// Should return 3 meta tools: get_module_schema, call_module_tool and search_tools
+	if len(tools) != 3 {
+		t.Errorf("expected 3 meta tools, got %d", len(tools))
 	}
 }
 
 func TestHandleInlineMessage_GetModuleSchema(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_module_schema","arguments":{"module":"test"}}}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -156,12 +190,13 @@ func TestHandleInlineMessage_GetModuleSchema(t *testing.T) {
 }
 
 func TestHandleInlineMessage_CallModuleTool(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"test","tool_name":"echo","params":{"message":"hello"}}}}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -197,7 +232,7 @@ func TestHandleInlineMessage_CallModuleTool(t *testing.T) {
 }
 
 func TestHandleInlineMessage_ParseError(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{invalid json`
 
@@ -222,12 +257,13 @@ func TestHandleInlineMessage_ParseError(t *testing.T) {
 }
 
 func TestHandleInlineMessage_MethodNotFound(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"unknown/method"}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -247,12 +283,13 @@ func TestHandleInlineMessage_MethodNotFound(t *testing.T) {
 }
 
 func TestHandleInlineMessage_UnknownTool(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"nonexistent_tool","arguments":{}}}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -272,9 +309,9 @@ func TestHandleInlineMessage_UnknownTool(t *testing.T) {
 }
 
 func TestServeHTTP_MethodNotAllowed(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
-	req := httptest.NewRequest("DELETE", "/mcp", nil)
+	req := httptest.NewRequest("PUT", "/mcp", nil)
 	rec := httptest.NewRecorder()
 
 	handler.ServeHTTP(rec, req)
@@ -285,12 +322,13 @@ func TestServeHTTP_MethodNotAllowed(t *testing.T) {
 }
 
 func TestHandleInlineMessage_UnknownModule(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_module_schema","arguments":{"module":"nonexistent"}}}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -316,13 +354,14 @@ func TestHandleInlineMessage_UnknownModule(t *testing.T) {
 }
 
 func TestHandleInlineMessage_InvalidParams(t *testing.T) {
-	handler := NewHandler()
+	handler := newTestHandler(t)
 
 	// tools/call with missing params
 	reqBody := `{"jsonrpc":"2.0","id":1,"method":"tools/call"}`
 
 	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionIDHeader, initSession(t, handler, LatestProtocolVersion))
 	rec := httptest.NewRecorder()
 
 	handler.handleInlineMessage(rec, req)
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// notifyFunc delivers a server notification on the stream that carries the
+// response of the current request
+type notifyFunc func(msg interface{})
+
+type notifierKey struct{}
+
+func withNotifier(ctx context.Context, fn notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierKey{}, fn)
+}
+
+func notifierFrom(ctx context.Context) notifyFunc {
+	fn, _ := ctx.Value(notifierKey{}).(notifyFunc)
+	return fn
+}
+
+// withProgress lets tool handlers emit notifications/progress for token via
+// modules.ReportProgress. Progress is dropped when there is no token or no
+// stream to carry it.
+func withProgress(ctx context.Context, session *Session, token interface{}) context.Context {
+	notify := notifierFrom(ctx)
+	if token == nil || notify == nil {
+		return ctx
+	}
+
+	includeMessage := session.features().progressMessage
+	var (
+		mu   sync.Mutex
+		sent bool
+		last float64
+	)
+
+	return modules.WithProgress(ctx, func(progress, total float64, message string) {
+		// The spec requires progress to increase with every notification
+		mu.Lock()
+		if sent && progress <= last {
+			mu.Unlock()
+			return
+		}
+		sent, last = true, progress
+		mu.Unlock()
+
+		if !includeMessage {
+			message = ""
+		}
+		notify(Notification{
+			JSONRPC: "2.0",
+			Method:  "notifications/progress",
+			Params: ProgressParams{
+				ProgressToken: token,
+				Progress:      progress,
+				Total:         total,
+				Message:       message,
+			},
+		})
+	})
+}
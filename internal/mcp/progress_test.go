@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// stepsModule reports progress in three steps
+var stepsModule = modules.ModuleDefinition{
+	Name:        "steps",
+	Description: "Progress test module",
+	Tools: []modules.Tool{
+		{Name: "run", Description: "Report three steps", InputSchema: modules.InputSchema{Type: "object"}},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"run": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			for i := 1; i <= 3; i++ {
+				modules.ReportProgress(ctx, float64(i), 3, "step")
+			}
+			// Going backwards is not allowed and must be dropped
+			modules.ReportProgress(ctx, 2, 3, "step")
+			return "finished", nil
+		},
+	},
+}
+
+const progressCall = `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"steps","tool_name":"run"},"_meta":{"progressToken":"tok"}}}`
+
+// sseMessages parses the data lines of an SSE body
+func sseMessages(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var messages []map[string]interface{}
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			t.Fatalf("invalid SSE data %q: %v", data, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestProgress_StreamedPOST(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, LatestProtocolVersion), "application/json, text/event-stream", progressCall)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	messages := sseMessages(t, rec.Body.String())
+	if len(messages) != 4 {
+		t.Fatalf("expected 3 progress notifications and a response, got %d: %s", len(messages), rec.Body.String())
+	}
+
+	for i, msg := range messages[:3] {
+		if msg["method"] != "notifications/progress" {
+			t.Fatalf("message %d: expected notifications/progress, got %v", i, msg["method"])
+		}
+		params := msg["params"].(map[string]interface{})
+		if params["progressToken"] != "tok" || params["progress"] != float64(i+1) || params["total"] != float64(3) {
+			t.Errorf("message %d: unexpected params %v", i, params)
+		}
+		if params["message"] != "step" {
+			t.Errorf("message %d: expected message, got %v", i, params["message"])
+		}
+	}
+
+	if messages[3]["id"] != float64(1) || messages[3]["result"] == nil {
+		t.Errorf("expected the tool result last, got %v", messages[3])
+	}
+}
+
+func TestProgress_JSONOnlyClient(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, LatestProtocolVersion), "application/json", progressCall)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected application/json, got %q", ct)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Error != nil {
+		t.Errorf("unexpected error: %+v", resp.Error)
+	}
+}
+
+func TestProgress_LegacySessionStream(t *testing.T) {
+	handler := newTestHandler(t)
+	session, err := handler.sessions.Create(TransportSSE)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	session.initialize(ProtocolVersion20241105, ClientInfo{Name: "test"}, ClientCapabilities{})
+
+	req := postRequest(progressCall)
+	req.URL.RawQuery = "sessionId=" + session.ID()
+	if rec := serve(handler, req); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	var queued []map[string]interface{}
+	for len(session.messages) > 0 {
+		var msg map[string]interface{}
+		json.Unmarshal(<-session.messages, &msg)
+		queued = append(queued, msg)
+	}
+	if len(queued) != 4 {
+		t.Fatalf("expected 4 queued messages, got %d", len(queued))
+	}
+
+	// 2024-11-05 has no progress message field
+	params := queued[0]["params"].(map[string]interface{})
+	if _, ok := params["message"]; ok {
+		t.Errorf("expected no message for 2024-11-05, got %v", params["message"])
+	}
+}
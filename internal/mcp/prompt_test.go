@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// promptsModule offers a prompt with completable arguments
+var promptsModule = modules.ModuleDefinition{
+	Name:        "prompts",
+	Description: "Prompt test module",
+	Prompts: []modules.Prompt{
+		{
+			Name: "greet",
+			Arguments: []modules.PromptArgument{
+				{Name: "name", Required: true},
+				{Name: "lang"},
+				{Name: "number"},
+			},
+		},
+	},
+	PromptHandlers: map[string]modules.PromptHandler{
+		"greet": func(ctx context.Context, args map[string]string) (*modules.PromptResult, error) {
+			return &modules.PromptResult{
+				Messages: []modules.PromptMessage{modules.UserMessage("Greet " + args["name"] + " in " + args["lang"])},
+			}, nil
+		},
+	},
+	Completers: map[string]modules.Completer{
+		"lang": func(ctx context.Context, args map[string]string) ([]string, error) {
+			return []string{"ja", "en", "es", "de"}, nil
+		},
+		"number": func(ctx context.Context, args map[string]string) ([]string, error) {
+			values := make([]string, 150)
+			for i := range values {
+				values[i] = fmt.Sprintf("%03d", i)
+			}
+			return values, nil
+		},
+	},
+}
+
+func TestPrompts_ListAndGet(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	resp := call(t, handler, session, "prompts/list", nil)
+	found := false
+	for _, p := range resp.Result.(*PromptsListResult).Prompts {
+		found = found || p.Name == "prompts__greet"
+	}
+	if !found {
+		t.Fatalf("expected prompts__greet in prompts/list, got %+v", resp.Result)
+	}
+
+	resp = call(t, handler, session, "prompts/get", map[string]interface{}{
+		"name":      "prompts__greet",
+		"arguments": map[string]string{"name": "Ada", "lang": "en"},
+	})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	result := resp.Result.(*modules.PromptResult)
+	if len(result.Messages) != 1 || result.Messages[0].Content.Text != "Greet Ada in en" {
+		t.Errorf("unexpected prompt result: %+v", result)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"name": "prompts__greet", "arguments": map[string]string{"lang": "en"}},
+		{"name": "prompts__unknown"},
+		{"name": "greet"},
+	} {
+		resp := call(t, handler, session, "prompts/get", params)
+		if resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("%v: expected InvalidParams, got %+v", params, resp.Error)
+		}
+	}
+}
+
+func TestCompletion_PromptArgument(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	complete := func(arg, value string) *Response {
+		return call(t, handler, session, "completion/complete", map[string]interface{}{
+			"ref":      map[string]string{"type": "ref/prompt", "name": "prompts__greet"},
+			"argument": map[string]string{"name": arg, "value": value},
+		})
+	}
+
+	resp := complete("lang", "E")
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+	completion := resp.Result.(*CompleteResult).Completion
+	if len(completion.Values) != 2 || completion.Values[0] != "en" || completion.Values[1] != "es" || completion.HasMore {
+		t.Errorf("expected [en es], got %+v", completion)
+	}
+
+	// Arguments without a completer have no suggestions
+	completion = complete("name", "A").Result.(*CompleteResult).Completion
+	if len(completion.Values) != 0 {
+		t.Errorf("expected no values, got %+v", completion)
+	}
+
+	// At most 100 values are returned
+	completion = complete("number", "").Result.(*CompleteResult).Completion
+	if len(completion.Values) != modules.MaxCompletionValues || completion.Total != 150 || !completion.HasMore {
+		t.Errorf("expected 100 of 150 values with hasMore, got %d/%d hasMore=%v", len(completion.Values), completion.Total, completion.HasMore)
+	}
+
+	if resp := complete("missing", ""); resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("expected an invalid params error for an unknown argument, got %+v", resp.Error)
+	}
+}
+
+func TestInitialize_AdvertisesPromptsAndCompletions(t *testing.T) {
+	for _, tt := range []struct {
+		version     string
+		completions bool
+	}{
+		{ProtocolVersion20241105, false},
+		{ProtocolVersion20250326, true},
+	} {
+		handler := newTestHandler(t)
+		session, _ := handler.sessions.Create(TransportStreamableHTTP)
+		resp := call(t, handler, session, "initialize", map[string]interface{}{"protocolVersion": tt.version})
+		caps := resp.Result.(*InitializeResult).Capabilities
+		if caps.Prompts == nil {
+			t.Errorf("%s: expected prompts capability", tt.version)
+		}
+		if (caps.Completions != nil) != tt.completions {
+			t.Errorf("%s: completions advertised = %v, want %v", tt.version, caps.Completions != nil, tt.completions)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+func TestHandler_HotAddAndRemoveModule(t *testing.T) {
+	registry := modules.NewRegistry()
+	handler := NewHandler(registry)
+	session, _ := handler.sessions.Create(TransportStreamableHTTP)
+	session.initialize(LatestProtocolVersion, ClientInfo{Name: "client"}, ClientCapabilities{})
+
+	echo := func() *ToolCallResult {
+		resp := call(t, handler, session, "tools/call", map[string]interface{}{
+			"name":      "call_module_tool",
+			"arguments": map[string]interface{}{"module": "test", "tool_name": "echo", "params": map[string]interface{}{"message": "hi"}},
+		})
+		return resp.Result.(*ToolCallResult)
+	}
+
+	if result := echo(); !result.IsError {
+		t.Fatal("expected unknown module before registration")
+	}
+
+	registry.Register(testModule)
+	if result := echo(); result.IsError || result.Content[0].Text != "Echo: hi" {
+		t.Fatalf("after register: %+v", result)
+	}
+
+	var methods []string
+	for len(session.messages) > 0 {
+		var notification Notification
+		json.Unmarshal(<-session.messages, &notification)
+		methods = append(methods, notification.Method)
+	}
+	want := "notifications/tools/list_changed,notifications/resources/list_changed,notifications/prompts/list_changed"
+	if strings.Join(methods, ",") != want {
+		t.Errorf("notifications = %v", methods)
+	}
+
+	list := call(t, handler, session, "tools/list", nil).Result.(*ToolsListResult)
+	if !strings.Contains(list.Tools[1].Description, "- test: ") {
+		t.Errorf("meta tools should list the added module:\n%s", list.Tools[1].Description)
+	}
+
+	registry.Unregister("test")
+	if result := echo(); !result.IsError {
+		t.Error("expected unknown module after unregistration")
+	}
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var spaceLookups int32
+
+// docsModule exposes resources
+var docsModule = modules.ModuleDefinition{
+	Name:        "docs",
+	Description: "Resource test module",
+	Tools: []modules.Tool{
+		{Name: "touch", Description: "Modify a note", InputSchema: modules.InputSchema{Type: "object"}},
+	},
+	Handlers: map[string]modules.ToolHandler{
+		"touch": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			id, _ := params["id"].(string)
+			modules.NotifyResourceUpdated(ctx, "docs://note/"+id)
+			return "touched", nil
+		},
+	},
+	ResourceTemplates: []modules.ResourceTemplate{
+		{URITemplate: "docs://note/{id}", Name: "note", MimeType: "text/plain"},
+		{URITemplate: "docs://{space}/file/{path}", Name: "file", MimeType: "text/plain"},
+	},
+	ResourceHandlers: map[string]modules.ResourceHandler{
+		"note": func(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+			return []modules.ResourceContents{{URI: "docs://note/" + vars["id"], MimeType: "text/plain", Text: "note " + vars["id"]}}, nil
+		},
+		"file": func(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+			return []modules.ResourceContents{{URI: "docs://" + vars["space"] + "/file/" + vars["path"], Text: vars["space"] + ":" + vars["path"]}}, nil
+		},
+	},
+	ListResources: func(ctx context.Context) ([]modules.Resource, error) {
+		return []modules.Resource{{URI: "docs://note/1", Name: "First note"}}, nil
+	},
+	Completers: map[string]modules.Completer{
+		"space": func(ctx context.Context, args map[string]string) ([]string, error) {
+			atomic.AddInt32(&spaceLookups, 1)
+			return []string{"team", "personal", "tech"}, nil
+		},
+	},
+}
+
+func call(t *testing.T, handler *Handler, session *Session, method string, params interface{}) *Response {
+	t.Helper()
+	resp := handler.respond(context.Background(), session, &Request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if resp == nil {
+		t.Fatalf("%s: expected a response", method)
+	}
+	return resp
+}
+
+func TestResources_ListAndTemplates(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	resp := call(t, handler, session, "resources/list", nil)
+	list := resp.Result.(*ResourcesListResult)
+	found := false
+	for _, r := range list.Resources {
+		found = found || r.URI == "docs://note/1"
+	}
+	if !found {
+		t.Errorf("expected docs://note/1 in resources/list, got %+v", list.Resources)
+	}
+
+	resp = call(t, handler, session, "resources/templates/list", nil)
+	templates := resp.Result.(*ResourceTemplatesListResult)
+	count := 0
+	for _, tmpl := range templates.ResourceTemplates {
+		if tmpl.URITemplate == "docs://note/{id}" || tmpl.URITemplate == "docs://{space}/file/{path}" {
+			count++
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected both docs templates, got %+v", templates.ResourceTemplates)
+	}
+}
+
+func TestResources_Read(t *testing.T) {
+	handler := newTestHandler(t)
+	session := initializedSession(LatestProtocolVersion)
+
+	tests := []struct {
+		uri  string
+		text string
+	}{
+		{"docs://note/42", "note 42"},
+		// The last variable may span slashes
+		{"docs://team/file/a/b/c.md", "team:a/b/c.md"},
+		// Values are percent-decoded, so other variables carry slashes as %2F
+		{"docs://my%2Fteam/file/a%20b/c.md", "my/team:a b/c.md"},
+	}
+	for _, tt := range tests {
+		resp := call(t, handler, session, "resources/read", map[string]interface{}{"uri": tt.uri})
+		if resp.Error != nil {
+			t.Fatalf("%s: unexpected error %+v", tt.uri, resp.Error)
+		}
+		contents := resp.Result.(*ReadResourceResult).Contents
+		if len(contents) != 1 || contents[0].Text != tt.text {
+			t.Errorf("%s: expected %q, got %+v", tt.uri, tt.text, contents)
+		}
+	}
+
+	for _, uri := range []string{"docs://unknown/1", "docs://team/file/%zz", "nomodule://x", "not-a-uri"} {
+		resp := call(t, handler, session, "resources/read", map[string]interface{}{"uri": uri})
+		if resp.Error == nil || resp.Error.Code != ResourceNotFound {
+			t.Errorf("%s: expected ResourceNotFound, got %+v", uri, resp.Error)
+		}
+	}
+}
+
+func TestResources_SubscribeNotifiesOnUpdate(t *testing.T) {
+	handler := newTestHandler(t)
+	subscriber, _ := handler.sessions.Create(TransportStreamableHTTP)
+	subscriber.initialize(LatestProtocolVersion, ClientInfo{Name: "subscriber"}, ClientCapabilities{})
+	other, _ := handler.sessions.Create(TransportStreamableHTTP)
+	other.initialize(LatestProtocolVersion, ClientInfo{Name: "other"}, ClientCapabilities{})
+
+	if resp := call(t, handler, subscriber, "resources/subscribe", map[string]interface{}{"uri": "docs://note/7"}); resp.Error != nil {
+		t.Fatalf("subscribe failed: %+v", resp.Error)
+	}
+
+	call(t, handler, other, "tools/call", map[string]interface{}{
+		"name":      "call_module_tool",
+		"arguments": map[string]interface{}{"module": "docs", "tool_name": "touch", "params": map[string]interface{}{"id": "7"}},
+	})
+
+	if len(other.messages) != 0 {
+		t.Errorf("unsubscribed session must not be notified")
+	}
+	if len(subscriber.messages) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(subscriber.messages))
+	}
+	var notification Notification
+	json.Unmarshal(<-subscriber.messages, &notification)
+	params, _ := notification.Params.(map[string]interface{})
+	if notification.Method != "notifications/resources/updated" || params["uri"] != "docs://note/7" {
+		t.Errorf("unexpected notification: %+v", notification)
+	}
+
+	// After unsubscribing no more notifications arrive
+	call(t, handler, subscriber, "resources/unsubscribe", map[string]interface{}{"uri": "docs://note/7"})
+	call(t, handler, other, "tools/call", map[string]interface{}{
+		"name":      "call_module_tool",
+		"arguments": map[string]interface{}{"module": "docs", "tool_name": "touch", "params": map[string]interface{}{"id": "7"}},
+	})
+	if len(subscriber.messages) != 0 {
+		t.Errorf("expected no notification after unsubscribe")
+	}
+}
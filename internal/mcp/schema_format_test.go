@@ -0,0 +1,11 @@
+package mcp
+
+import "testing"
+
+func TestGetModuleSchema_RejectsUnknownFormat(t *testing.T) {
+	resp := callTool(t, newTestHandler(t), initializedSession(LatestProtocolVersion), "get_module_schema",
+		map[string]interface{}{"module": "test", "format": "yaml"})
+	if resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("expected invalid params, got %+v", resp)
+	}
+}
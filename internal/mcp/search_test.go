@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type searchOutput struct {
+	Query   string `json:"query"`
+	Results []struct {
+		Module string            `json:"module"`
+		Tool   string            `json:"tool"`
+		Call   string            `json:"call"`
+		Params map[string]string `json:"params"`
+	} `json:"results"`
+	Hint string `json:"hint"`
+}
+
+func searchTools(t *testing.T, handler *Handler, args map[string]interface{}) searchOutput {
+	t.Helper()
+	resp := callTool(t, handler, initializedSession(LatestProtocolVersion), "search_tools", args)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	result := resp.Result.(*ToolCallResult)
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %s", result.Content[0].Text)
+	}
+
+	var out searchOutput
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &out); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+	return out
+}
+
+func TestSearchTools_MetaModeHintsAtCallModuleTool(t *testing.T) {
+	handler := newTestHandler(t)
+
+	out := searchTools(t, handler, map[string]interface{}{"query": "echo message"})
+	if len(out.Results) == 0 || out.Results[0].Module != "test" || out.Results[0].Params["message"] == "" {
+		t.Fatalf("results = %+v", out.Results)
+	}
+	if out.Results[0].Call != "" || out.Hint == "" {
+		t.Errorf("meta mode should hint at call_module_tool: %+v", out)
+	}
+
+	out = searchTools(t, handler, map[string]interface{}{"query": "zzz nothing matches"})
+	if len(out.Results) != 0 || out.Hint == "" {
+		t.Errorf("no-match output = %+v", out)
+	}
+}
+
+func TestSearchTools_InvalidArguments(t *testing.T) {
+	handler := newTestHandler(t)
+
+	resp := callTool(t, handler, initializedSession(LatestProtocolVersion), "search_tools",
+		map[string]interface{}{"query": "echo", "module": "missing"})
+	if resp.Error != nil || !resp.Result.(*ToolCallResult).IsError {
+		t.Errorf("unknown module should be a tool error: %+v", resp)
+	}
+	if resp := callTool(t, handler, initializedSession(LatestProtocolVersion), "search_tools", nil); resp.Error == nil {
+		t.Error("missing query should be an invalid params error")
+	}
+}
+
+func TestSearchTools_FlatModeReturnsCallNames(t *testing.T) {
+	handler := NewHandler(newTestRegistry(t), WithExposureMode(ExposureFlat))
+
+	out := searchTools(t, handler, map[string]interface{}{"query": "echo message"})
+	if len(out.Results) == 0 || out.Results[0].Call != "test__echo" {
+		t.Errorf("results = %+v", out.Results)
+	}
+}
@@ -0,0 +1,421 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionState is the lifecycle state of a session
+type SessionState int
+
+const (
+	// SessionUninitialized sessions only accept initialize and ping
+	SessionUninitialized SessionState = iota
+	// SessionInitialized sessions completed the initialize handshake
+	SessionInitialized
+	// SessionClosed sessions were terminated by the client or expired
+	SessionClosed
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case SessionUninitialized:
+		return "uninitialized"
+	case SessionInitialized:
+		return "initialized"
+	case SessionClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("SessionState(%d)", int(s))
+	}
+}
+
+// Transport names recorded on sessions
+const (
+	TransportStreamableHTTP = "streamable-http"
+	TransportSSE            = "sse"
+)
+
+type Session struct {
+	id        string
+	transport string
+	done      chan struct{}
+	closeOnce sync.Once
+	messages  chan []byte
+
+	// ctx is cancelled when the session closes, aborting in-flight requests
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu              sync.RWMutex
+	state           SessionState
+	protocolVersion string
+	clientInfo      ClientInfo
+	capabilities    ClientCapabilities
+	createdAt       time.Time
+	lastActive      time.Time
+	streams         int
+	inflight        map[string]context.CancelFunc
+	subscriptions   map[string]bool
+	expanded        map[string]bool
+	completions     completionCache
+}
+
+// SessionInfo is a snapshot of a session for admin introspection
+type SessionInfo struct {
+	ID              string     `json:"id"`
+	Transport       string     `json:"transport"`
+	State           string     `json:"state"`
+	ProtocolVersion string     `json:"protocolVersion,omitempty"`
+	ClientInfo      ClientInfo `json:"clientInfo"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	LastActive      time.Time  `json:"lastActive"`
+	Streams         int        `json:"streams"`
+}
+
+func newSession(id, transport string) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	return &Session{
+		id:            id,
+		transport:     transport,
+		done:          make(chan struct{}),
+		messages:      make(chan []byte, 100),
+		ctx:           ctx,
+		cancel:        cancel,
+		state:         SessionUninitialized,
+		createdAt:     now,
+		lastActive:    now,
+		inflight:      make(map[string]context.CancelFunc),
+		subscriptions: make(map[string]bool),
+		expanded:      make(map[string]bool),
+	}
+}
+
+// ID returns the session ID; stateless sessions have none
+func (s *Session) ID() string {
+	return s.id
+}
+
+// State returns the current lifecycle state
+func (s *Session) State() SessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// ProtocolVersion returns the protocol version negotiated in initialize
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protocolVersion
+}
+
+// ClientInfo returns the client name and version sent in initialize
+func (s *Session) ClientInfo() ClientInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo
+}
+
+// initialize records the handshake result and moves the session to initialized
+func (s *Session) initialize(protocolVersion string, info ClientInfo, capabilities ClientCapabilities) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != SessionUninitialized {
+		return fmt.Errorf("session is %s", s.state)
+	}
+	s.state = SessionInitialized
+	s.protocolVersion = protocolVersion
+	s.clientInfo = info
+	s.capabilities = capabilities
+	return nil
+}
+
+func (s *Session) features() protocolFeatures {
+	return featuresFor(s.ProtocolVersion())
+}
+
+func (s *Session) touch() {
+	s.mu.Lock()
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+// attachStream marks an open SSE stream; sessions with streams never go idle
+func (s *Session) attachStream() {
+	s.mu.Lock()
+	s.streams++
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *Session) detachStream() {
+	s.mu.Lock()
+	s.streams--
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *Session) idleSince(now time.Time) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.streams > 0 {
+		return 0
+	}
+	return now.Sub(s.lastActive)
+}
+
+func (s *Session) info() SessionInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return SessionInfo{
+		ID:              s.id,
+		Transport:       s.transport,
+		State:           s.state.String(),
+		ProtocolVersion: s.protocolVersion,
+		ClientInfo:      s.clientInfo,
+		CreatedAt:       s.createdAt,
+		LastActive:      s.lastActive,
+		Streams:         s.streams,
+	}
+}
+
+// beginRequest registers an in-flight request so notifications/cancelled can
+// abort it. The returned context is also cancelled when parent is done or the
+// session closes; finish must be called once the request completes.
+func (s *Session) beginRequest(parent context.Context, id interface{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(s.ctx, cancel)
+
+	key := requestKey(id)
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		stop()
+		cancel()
+	}
+}
+
+// cancelRequest aborts an in-flight request and reports whether it was found
+func (s *Session) cancelRequest(id interface{}) bool {
+	s.mu.Lock()
+	cancel, ok := s.inflight[requestKey(id)]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// requestKey normalizes a JSON-RPC ID to its JSON encoding, so the number 1
+// matches however it was decoded while the string "1" stays distinct.
+func requestKey(id interface{}) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+// setSubscribed adds or removes a resource subscription
+func (s *Session) setSubscribed(uri string, subscribed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if subscribed {
+		s.subscriptions[uri] = true
+	} else {
+		delete(s.subscriptions, uri)
+	}
+}
+
+// isSubscribed reports whether the client subscribed to updates of uri
+func (s *Session) isSubscribed(uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subscriptions[uri]
+}
+
+// expandModule adds a module to the tools listed in hybrid exposure mode.
+// It reports whether the module was newly added.
+func (s *Session) expandModule(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expanded[name] {
+		return false
+	}
+	s.expanded[name] = true
+	return true
+}
+
+// isExpanded reports whether the session expanded the module
+func (s *Session) isExpanded(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.expanded[name]
+}
+
+// close ends the session and terminates any attached SSE stream
+func (s *Session) close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.state = SessionClosed
+		s.mu.Unlock()
+		s.cancel()
+		close(s.done)
+	})
+}
+
+// DefaultSessionIdleTimeout is how long a session without open streams or
+// requests is kept before it expires
+const DefaultSessionIdleTimeout = 30 * time.Minute
+
+// sessionSweepInterval throttles how often idle sessions are looked for
+const sessionSweepInterval = time.Minute
+
+// SessionManager issues session IDs and tracks session lifecycles.
+// Idle sessions are expired lazily whenever the manager is used, so no
+// background goroutine is needed.
+type SessionManager struct {
+	mu          sync.Mutex
+	sessions    map[string]*Session
+	idleTimeout time.Duration
+	lastSweep   time.Time
+}
+
+// NewSessionManager creates a manager that expires sessions idle for longer
+// than idleTimeout
+func NewSessionManager(idleTimeout time.Duration) *SessionManager {
+	return &SessionManager{
+		sessions:    make(map[string]*Session),
+		idleTimeout: idleTimeout,
+		lastSweep:   time.Now(),
+	}
+}
+
+// Create registers a new uninitialized session with an unguessable ID
+func (m *SessionManager) Create(transport string) (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := newSession(id, transport)
+
+	m.mu.Lock()
+	m.sweepLocked(session.createdAt)
+	m.sessions[id] = session
+	m.mu.Unlock()
+
+	return session, nil
+}
+
+// Get returns a live session and marks it active
+func (m *SessionManager) Get(id string) (*Session, bool) {
+	m.mu.Lock()
+	m.sweepLocked(time.Now())
+	session, ok := m.sessions[id]
+	m.mu.Unlock()
+
+	if ok {
+		session.touch()
+	}
+	return session, ok
+}
+
+// Close terminates a session; it reports whether the session existed
+func (m *SessionManager) Close(id string) bool {
+	m.mu.Lock()
+	session, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if ok {
+		session.close()
+	}
+	return ok
+}
+
+// List returns a snapshot of all live sessions, oldest first
+func (m *SessionManager) List() []SessionInfo {
+	m.mu.Lock()
+	m.sweepLocked(time.Now())
+	infos := make([]SessionInfo, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		infos = append(infos, session.info())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// all returns a snapshot of the live sessions
+func (m *SessionManager) all() []*Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Len returns the number of live sessions
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Expire closes every session idle for longer than the idle timeout at now
+// and returns how many were closed
+func (m *SessionManager) Expire(now time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expireLocked(now)
+}
+
+func (m *SessionManager) sweepLocked(now time.Time) {
+	if now.Sub(m.lastSweep) < sessionSweepInterval {
+		return
+	}
+	m.expireLocked(now)
+}
+
+func (m *SessionManager) expireLocked(now time.Time) int {
+	m.lastSweep = now
+	if m.idleTimeout <= 0 {
+		return 0
+	}
+
+	expired := 0
+	for id, session := range m.sessions {
+		if session.idleSince(now) > m.idleTimeout {
+			delete(m.sessions, id)
+			session.close()
+			expired++
+		}
+	}
+	return expired
+}
+
+// newSessionID returns 128 random bits as hex, which only uses visible ASCII
+// as the Streamable HTTP spec requires
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
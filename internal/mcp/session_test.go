@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSessionManager_UnguessableIDs(t *testing.T) {
+	manager := NewSessionManager(DefaultSessionIdleTimeout)
+	hexID := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		session, err := manager.Create(TransportStreamableHTTP)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hexID.MatchString(session.ID()) {
+			t.Fatalf("unexpected session ID format: %q", session.ID())
+		}
+		if seen[session.ID()] {
+			t.Fatalf("duplicate session ID: %s", session.ID())
+		}
+		seen[session.ID()] = true
+	}
+}
+
+func TestSessionManager_Lifecycle(t *testing.T) {
+	manager := NewSessionManager(DefaultSessionIdleTimeout)
+
+	session, _ := manager.Create(TransportStreamableHTTP)
+	if session.State() != SessionUninitialized {
+		t.Fatalf("expected uninitialized, got %s", session.State())
+	}
+
+	if err := session.initialize(ProtocolVersion20250326, ClientInfo{Name: "test"}, ClientCapabilities{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.State() != SessionInitialized {
+		t.Fatalf("expected initialized, got %s", session.State())
+	}
+
+	if err := session.initialize(ProtocolVersion20250326, ClientInfo{}, ClientCapabilities{}); err == nil {
+		t.Error("expected error when initializing twice")
+	}
+
+	if !manager.Close(session.ID()) {
+		t.Fatal("expected Close to find the session")
+	}
+	if session.State() != SessionClosed {
+		t.Errorf("expected closed, got %s", session.State())
+	}
+	if _, ok := manager.Get(session.ID()); ok {
+		t.Error("closed session should not be returned")
+	}
+}
+
+func TestSessionManager_IdleExpiry(t *testing.T) {
+	manager := NewSessionManager(time.Minute)
+
+	idle, _ := manager.Create(TransportStreamableHTTP)
+	streaming, _ := manager.Create(TransportSSE)
+	streaming.attachStream()
+
+	if n := manager.Expire(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Fatalf("expected 1 expired session, got %d", n)
+	}
+
+	if _, ok := manager.Get(idle.ID()); ok {
+		t.Error("idle session should have expired")
+	}
+	if _, ok := manager.Get(streaming.ID()); !ok {
+		t.Error("session with an open stream should not expire")
+	}
+
+	select {
+	case <-idle.done:
+	default:
+		t.Error("expired session should be closed")
+	}
+}
+
+func TestSessionManager_List(t *testing.T) {
+	manager := NewSessionManager(DefaultSessionIdleTimeout)
+
+	first, _ := manager.Create(TransportSSE)
+	manager.Create(TransportStreamableHTTP)
+	first.initialize(ProtocolVersion20241105, ClientInfo{Name: "legacy"}, ClientCapabilities{})
+
+	infos := manager.List()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.ID == first.ID() {
+			if info.State != "initialized" || info.ClientInfo.Name != "legacy" {
+				t.Errorf("unexpected info for initialized session: %+v", info)
+			}
+		} else if info.State != "uninitialized" {
+			t.Errorf("expected other session uninitialized, got %s", info.State)
+		}
+	}
+}
+
+func TestProcessRequest_RejectsBeforeInitialize(t *testing.T) {
+	handler := newTestHandler(t)
+
+	session, _ := handler.sessions.Create(TransportSSE)
+
+	_, rpcErr := handler.processRequest(context.Background(), session, &Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	if rpcErr == nil || rpcErr.Code != InvalidRequest {
+		t.Fatalf("expected InvalidRequest before initialize, got %v", rpcErr)
+	}
+
+	if _, rpcErr := handler.processRequest(context.Background(), session, &Request{JSONRPC: "2.0", ID: 2, Method: "ping"}); rpcErr != nil {
+		t.Errorf("ping should be allowed before initialize: %v", rpcErr)
+	}
+
+	if _, rpcErr := handler.processRequest(context.Background(), session, &Request{JSONRPC: "2.0", ID: 3, Method: "initialize"}); rpcErr != nil {
+		t.Fatalf("unexpected initialize error: %v", rpcErr)
+	}
+
+	if _, rpcErr := handler.processRequest(context.Background(), session, &Request{JSONRPC: "2.0", ID: 4, Method: "tools/list"}); rpcErr != nil {
+		t.Errorf("unexpected error after initialize: %v", rpcErr)
+	}
+}
+
+func TestSessionsHandler(t *testing.T) {
+	handler := newTestHandler(t)
+	handler.sessions.Create(TransportSSE)
+
+	rec := httptest.NewRecorder()
+	handler.SessionsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/admin/sessions", nil))
+
+	var body struct {
+		Sessions []SessionInfo `json:"sessions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(body.Sessions) != 1 || body.Sessions[0].Transport != TransportSSE {
+		t.Errorf("unexpected sessions: %+v", body.Sessions)
+	}
+}
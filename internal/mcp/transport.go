@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sessionIDHeader carries the session ID in the Streamable HTTP transport
+const sessionIDHeader = "Mcp-Session-Id"
+
+func setSSEHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+}
+
+// writeEvent writes a JSON-RPC message as an SSE "message" event
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, data []byte) {
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	flusher.Flush()
+}
+
+// acceptsEventStream reports whether the client listed text/event-stream in Accept
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+			if mediaType == "text/event-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inlineWriter writes the response to a Streamable HTTP POST. It switches to
+// an SSE stream when a notification has to be sent ahead of the response.
+type inlineWriter struct {
+	w         http.ResponseWriter
+	r         *http.Request
+	mu        sync.Mutex
+	streaming bool
+}
+
+func newInlineWriter(w http.ResponseWriter, r *http.Request) *inlineWriter {
+	return &inlineWriter{w: w, r: r}
+}
+
+// notify sends a notification on the response stream. Clients that only
+// accept application/json cannot receive it, so it is dropped for them.
+func (iw *inlineWriter) notify(msg interface{}) {
+	flusher, ok := iw.w.(http.Flusher)
+	if !ok || !acceptsEventStream(iw.r) {
+		return
+	}
+	data, _ := json.Marshal(msg)
+
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	if !iw.streaming {
+		setSSEHeaders(iw.w)
+		iw.w.WriteHeader(http.StatusOK)
+		iw.streaming = true
+	}
+	writeEvent(iw.w, flusher, data)
+}
+
+// write sends the final response. Tool calls may run long, so they are sent
+// as an SSE stream when the client accepts one.
+func (iw *inlineWriter) write(payload interface{}, stream bool) {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+
+	flusher, ok := iw.w.(http.Flusher)
+	if ok && (iw.streaming || stream && acceptsEventStream(iw.r)) {
+		data, _ := json.Marshal(payload)
+		if !iw.streaming {
+			setSSEHeaders(iw.w)
+			iw.w.WriteHeader(http.StatusOK)
+		}
+		writeEvent(iw.w, flusher, data)
+		return
+	}
+
+	iw.w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(iw.w).Encode(payload)
+}
+
+// accepted answers a POST that produced no response. A stream that already
+// carried notifications is simply ended.
+func (iw *inlineWriter) accepted() {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	if !iw.streaming {
+		iw.w.WriteHeader(http.StatusAccepted)
+	}
+}
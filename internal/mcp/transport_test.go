@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postRequest(body string) *http.Request {
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func serve(handler *Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func postMCP(handler *Handler, sessionID, accept, body string) *httptest.ResponseRecorder {
+	req := postRequest(body)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+	return serve(handler, req)
+}
+
+// initSession runs the initialize handshake at version and returns the ID of
+// the new session
+func initSession(t *testing.T, handler *Handler, version string) string {
+	t.Helper()
+	rec := postMCP(handler, "", "",
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"`+version+`","clientInfo":{"name":"test","version":"1.0"}}}`)
+	sessionID := rec.Header().Get(sessionIDHeader)
+	if sessionID == "" {
+		t.Fatalf("initialize at %s assigned no session: %d %s", version, rec.Code, rec.Body.String())
+	}
+	return sessionID
+}
+
+func TestStreamable_InitializeAssignsSession(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, "", "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0"}}}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	sessionID := rec.Header().Get(sessionIDHeader)
+	if sessionID == "" {
+		t.Fatal("expected Mcp-Session-Id header on initialize response")
+	}
+
+	if _, ok := handler.sessions.Get(sessionID); !ok {
+		t.Errorf("session %s was not registered", sessionID)
+	}
+
+	// Follow-up requests on the session are accepted
+	rec = postMCP(handler, sessionID, "", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestStreamable_RequestsWithoutSessionAreRejected(t *testing.T) {
+	handler := newTestHandler(t)
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"test","tool_name":"echo","params":{}}}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`,
+	} {
+		rec := postMCP(handler, "", "", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "Echo") {
+			t.Errorf("%s: tool ran without a session", body)
+		}
+	}
+}
+
+func TestStreamable_UnknownSession(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, "does-not-exist", "", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestStreamable_NotificationAccepted(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, LatestProtocolVersion), "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestStreamable_ToolCallAsEventStream(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, initSession(t, handler, LatestProtocolVersion), "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"call_module_tool","arguments":{"module":"test","tool_name":"echo","params":{"message":"hi"}}}}`)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "event: message\ndata: ") {
+		t.Fatalf("unexpected SSE body: %q", body)
+	}
+
+	data := strings.TrimSpace(strings.TrimPrefix(body, "event: message\ndata: "))
+	var resp Response
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatalf("failed to unmarshal event data: %v", err)
+	}
+	if resp.Error != nil {
+		t.Errorf("unexpected error: %v", resp.Error)
+	}
+}
+
+func TestStreamable_DeleteSession(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, "", "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	sessionID := rec.Header().Get(sessionIDHeader)
+
+	req := httptest.NewRequest("DELETE", "/mcp", nil)
+	req.Header.Set(sessionIDHeader, sessionID)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	if _, ok := handler.sessions.Get(sessionID); ok {
+		t.Error("expected session to be removed")
+	}
+
+	rec = postMCP(handler, sessionID, "", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d after delete, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestStreamable_GetRequiresEventStream(t *testing.T) {
+	handler := newTestHandler(t)
+
+	req := httptest.NewRequest("GET", "/mcp", nil)
+	req.Header.Set(sessionIDHeader, "any")
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("expected status %d, got %d", http.StatusNotAcceptable, rec.Code)
+	}
+}
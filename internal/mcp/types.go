@@ -18,6 +18,13 @@ type Response struct {
 	Error   *Error      `json:"error,omitempty"`
 }
 
+// JSON-RPC 2.0 Notification sent by the server
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // JSON-RPC 2.0 Error
 type Error struct {
 	Code    int         `json:"code"`
@@ -32,6 +39,9 @@ const (
 	MethodNotFound = -32601
 	InvalidParams  = -32602
 	InternalError  = -32603
+
+	// ResourceNotFound is the MCP error for resources/read of an unknown URI
+	ResourceNotFound = -32002
 )
 
 // MCP Protocol Types
@@ -64,13 +74,25 @@ type InitializeResult struct {
 }
 
 type ServerCapabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools       *ToolsCapability     `json:"tools,omitempty"`
+	Resources   *ResourcesCapability `json:"resources,omitempty"`
+	Prompts     *PromptsCapability   `json:"prompts,omitempty"`
+	Completions *struct{}            `json:"completions,omitempty"`
 }
 
 type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 type ServerInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -83,6 +105,79 @@ type ToolsListResult struct {
 type ToolCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// RequestMeta is the _meta object a client may attach to a request
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// ProgressParams are the params of notifications/progress
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+type ResourcesListResult struct {
+	Resources []modules.Resource `json:"resources"`
+}
+
+type ResourceTemplatesListResult struct {
+	ResourceTemplates []modules.ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ResourceParams are the params of resources/read, resources/subscribe,
+// resources/unsubscribe and notifications/resources/updated
+type ResourceParams struct {
+	URI string `json:"uri"`
+}
+
+type ReadResourceResult struct {
+	Contents []modules.ResourceContents `json:"contents"`
+}
+
+type PromptsListResult struct {
+	Prompts []modules.Prompt `json:"prompts"`
+}
+
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments"`
+}
+
+// CompleteParams are the params of completion/complete
+type CompleteParams struct {
+	Ref struct {
+		Type string `json:"type"` // ref/prompt or ref/resource
+		Name string `json:"name,omitempty"`
+		URI  string `json:"uri,omitempty"`
+	} `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+	Context *struct {
+		Arguments map[string]string `json:"arguments"`
+	} `json:"context,omitempty"`
+}
+
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// CancelledParams are the params of notifications/cancelled
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
 }
 
 // Use modules.ToolCallResult and modules.ContentBlock instead
@@ -0,0 +1,75 @@
+package mcp
+
+// MCP protocol revisions understood by this server
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+// protocolVersionHeader is sent by 2025-06-18+ clients on every HTTP request
+const protocolVersionHeader = "MCP-Protocol-Version"
+
+// supportedVersions lists supported protocol versions, newest first
+var supportedVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// protocolFeatures describes what the negotiated protocol version allows
+type protocolFeatures struct {
+	// streamableHTTP allows Mcp-Session-Id sessions on the Streamable HTTP transport
+	streamableHTTP bool
+	// toolAnnotations includes Tool.annotations in tools/list
+	toolAnnotations bool
+	// progressMessage includes a message in notifications/progress
+	progressMessage bool
+	// completions advertises the completions capability
+	completions bool
+	// structuredOutput includes outputSchema and structuredContent
+	structuredOutput bool
+	// resourceLinks allows resource_link content blocks in tool results
+	resourceLinks bool
+	// versionHeader requires the MCP-Protocol-Version header to match the session
+	versionHeader bool
+	// batching accepts JSON-RPC batches, which 2025-06-18 removed
+	batching bool
+}
+
+// isSupportedVersion reports whether the server implements the given version
+func isSupportedVersion(version string) bool {
+	for _, v := range supportedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateVersion picks the version to answer initialize with. The client's
+// requested version is used when supported; otherwise the server proposes its
+// latest version and the client decides whether to continue.
+func negotiateVersion(requested string) string {
+	if isSupportedVersion(requested) {
+		return requested
+	}
+	return LatestProtocolVersion
+}
+
+// featuresFor returns the feature set of a protocol version.
+// Versions are ISO dates, so they compare lexically.
+func featuresFor(version string) protocolFeatures {
+	return protocolFeatures{
+		streamableHTTP:   version >= ProtocolVersion20250326,
+		toolAnnotations:  version >= ProtocolVersion20250326,
+		progressMessage:  version >= ProtocolVersion20250326,
+		completions:      version >= ProtocolVersion20250326,
+		structuredOutput: version >= ProtocolVersion20250618,
+		resourceLinks:    version >= ProtocolVersion20250618,
+		versionHeader:    version >= ProtocolVersion20250618,
+		batching:         version < ProtocolVersion20250618,
+	}
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		expected  string
+	}{
+		{ProtocolVersion20241105, ProtocolVersion20241105},
+		{ProtocolVersion20250326, ProtocolVersion20250326},
+		{ProtocolVersion20250618, ProtocolVersion20250618},
+		{"2099-01-01", LatestProtocolVersion},
+		{"", LatestProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateVersion(tt.requested); got != tt.expected {
+			t.Errorf("negotiateVersion(%q) = %q, expected %q", tt.requested, got, tt.expected)
+		}
+	}
+}
+
+func TestInitialize_StoresVersionAndClientInfo(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, "", "",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"claude","version":"2.0"}}}`)
+
+	session, ok := handler.sessions.Get(rec.Header().Get(sessionIDHeader))
+	if !ok {
+		t.Fatal("expected session to be created")
+	}
+	if session.ProtocolVersion() != ProtocolVersion20250618 {
+		t.Errorf("expected version %s, got %s", ProtocolVersion20250618, session.ProtocolVersion())
+	}
+	if session.ClientInfo().Name != "claude" {
+		t.Errorf("expected client name claude, got %s", session.ClientInfo().Name)
+	}
+}
+
+func TestInitialize_LegacyVersionStaysStateless(t *testing.T) {
+	handler := newTestHandler(t)
+
+	rec := postMCP(handler, "", "",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`)
+
+	if rec.Header().Get(sessionIDHeader) != "" {
+		t.Error("2024-11-05 clients should not receive Mcp-Session-Id")
+	}
+	if n := handler.sessions.Len(); n != 0 {
+		t.Errorf("expected no registered sessions, got %d", n)
+	}
+}
+
+func TestStreamable_UnsupportedVersionHeader(t *testing.T) {
+	handler := newTestHandler(t)
+
+	req := postRequest(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	req.Header.Set(protocolVersionHeader, "1999-01-01")
+	rec := serve(handler, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestToolsList_AnnotationsDependOnVersion(t *testing.T) {
+	handler := newTestHandler(t)
+
+	for version, expectAnnotations := range map[string]bool{
+		ProtocolVersion20241105: false,
+		ProtocolVersion20250326: true,
+	} {
+		resp := handler.respond(context.Background(), initializedSession(version), &Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+		result, ok := resp.Result.(*ToolsListResult)
+		if !ok {
+			t.Fatalf("version %s: unexpected response %+v", version, resp)
+		}
+
+		for _, tool := range result.Tools {
+			if (tool.Annotations != nil) != expectAnnotations {
+				t.Errorf("version %s: tool %s annotations present=%v, expected %v",
+					version, tool.Name, tool.Annotations != nil, expectAnnotations)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var compactors = map[string]modules.Compactor{
+	"query": compactRecords,
+}
+
+// compactRecords renders query results as a table with one column per field
+func compactRecords(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var result struct {
+		Records []struct {
+			ID     string                 `json:"id"`
+			Fields map[string]interface{} `json:"fields"`
+		} `json:"records"`
+		Offset string `json:"offset"`
+	}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return "", err
+	}
+	if len(result.Records) == 0 {
+		return "No records", nil
+	}
+
+	// Columns in order of first appearance; Airtable omits empty fields
+	seen := map[string]bool{}
+	var columns []string
+	for _, record := range result.Records {
+		names := make([]string, 0, len(record.Fields))
+		for name := range record.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	rows := make([][]string, len(result.Records))
+	for i, record := range result.Records {
+		row := []string{record.ID}
+		for _, column := range columns {
+			row = append(row, cellText(record.Fields[column]))
+		}
+		rows[i] = row
+	}
+
+	text := fmt.Sprintf("%d records\n\n", len(rows)) +
+		strings.TrimSuffix(modules.MarkdownTable(append([]string{"id"}, columns...), rows), "\n")
+	if result.Offset != "" {
+		text += fmt.Sprintf("\n\nMore records: pass offset %q", result.Offset)
+	}
+	return text, nil
+}
+
+// cellText flattens a field value. Lists are joined, and attachments and
+// collaborators are reduced to their name.
+func cellText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = cellText(item)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		for _, key := range []string{"name", "filename", "email", "url"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
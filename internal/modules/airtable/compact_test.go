@@ -0,0 +1,37 @@
+package airtable
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// checkSummary runs compact on raw and checks that the summary has every
+// want and is shorter than the payload
+func checkSummary(t *testing.T, compact modules.Compactor, raw string, want ...string) {
+	t.Helper()
+	got, err := compact(context.Background(), nil, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in:\n%s", w, got)
+		}
+	}
+	if len(got) >= len(raw) {
+		t.Error("summary is not shorter than the payload")
+	}
+}
+
+func TestCompactRecords(t *testing.T) {
+	raw := `{"records":[{"id":"rec1","createdTime":"2026-01-01T00:00:00.000Z","fields":{"Name":"A","Tags":["x","y"]}},{"id":"rec2","createdTime":"2026-01-02T00:00:00.000Z","fields":{"Name":"B","Count":3}}],"offset":"itr1"}`
+	checkSummary(t, compactRecords, raw,
+		"| id | Name | Tags | Count |",
+		"| rec1 | A | x, y |  |",
+		"| rec2 | B |  | 3 |",
+		`pass offset "itr1"`,
+	)
+}
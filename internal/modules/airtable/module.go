@@ -1,10 +1,13 @@
 package airtable
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
 	"github.com/shibaleo/go-mcp-dev/internal/modules"
@@ -13,9 +16,25 @@ import (
 const (
 	airtableAPIBase = "https://api.airtable.com/v0"
 	airtableVersion = "v0"
+
+	// batchSize is the most records Airtable accepts in one write request
+	batchSize = 10
 )
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("airtable"), httpclient.WithCache(httpclient.DefaultCachePolicy))
+
+// rateLimit follows Airtable's limit of 5 requests per second per base
+var rateLimit = httpclient.RateLimit{Rate: 5, Burst: 5, Partition: baseOf}
+
+// baseOf returns the base a request addresses, from /v0/{baseId}/... or
+// /v0/meta/bases/{baseId}/...
+func baseOf(req *http.Request) string {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/v0/"), "/")
+	if len(parts) >= 3 && parts[0] == "meta" && parts[1] == "bases" {
+		return parts[2]
+	}
+	return parts[0]
+}
 
 func getToken() string {
 	return os.Getenv("AIRTABLE_API_KEY")
@@ -31,297 +50,204 @@ func headers() map[string]string {
 // Module returns the Airtable module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "airtable",
-		Description: "Airtable API - Bases, Tables, Records operations",
-		APIVersion:  airtableVersion,
-		TestedAt:    "2026-01-14",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "airtable",
+		Description:       "Airtable API - Bases, Tables, Records operations",
+		APIVersion:        airtableVersion,
+		TestedAt:          "2026-01-14",
+		Compactors:        compactors,
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		Completers:        completers,
+	}.WithTools(tools...).WithPagination("query")
 }
 
-var tools = []modules.Tool{
+var tools = []modules.TypedTool{
 	// Base Operations
-	{
-		Name:        "list_bases",
-		Description: "List all accessible Airtable bases with their names, IDs, and permission levels",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
-	},
+	modules.NewTool("list_bases", "List all accessible Airtable bases with their names, IDs, and permission levels", listBases),
 	// Schema Operations
-	{
-		Name:        "describe",
-		Description: "Describe Airtable base or table schema. Use detailLevel to optimize context: tableIdentifiersOnly (minimal), identifiersOnly (IDs and names), full (complete details with field types)",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"scope": {
-					Type:        "string",
-					Description: "Scope of description: 'base' for all tables, 'table' for a specific table",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID (required when scope='table')",
-				},
-				"detail_level": {
-					Type:        "string",
-					Description: "Detail level: tableIdentifiersOnly, identifiersOnly, or full (default: full)",
-				},
-				"include_views": {
-					Type:        "boolean",
-					Description: "Include view information (default: false)",
-				},
-			},
-			Required: []string{"base_id"},
-		},
-	},
+	modules.NewTool("describe", "Describe Airtable base or table schema. Use detailLevel to optimize context: tableIdentifiersOnly (minimal), identifiersOnly (IDs and names), full (complete details with field types)", describe),
 	// Record Operations
+	modules.NewTool("query", "Query Airtable records with filtering, sorting, and pagination", query),
+	modules.NewTool("get_record", "Retrieve a single record by ID", getRecord),
+	modules.NewTool("create", "Create new records in a table. Supports batch creation (up to 10 records per request)", create),
+	modules.NewTool("update", "Update existing records. Supports batch update (up to 10 records per request). Uses PATCH (partial update)", update),
+	modules.NewTool("delete", "Delete records from a table. Supports batch deletion (up to 10 records per request)", deleteRecords),
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "query",
-		Description: "Query Airtable records with filtering, sorting, and pagination",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID",
-				},
-				"fields": {
-					Type:        "array",
-					Description: "Array of field names to return",
-				},
-				"filter_by_formula": {
-					Type:        "string",
-					Description: "Airtable formula to filter records",
-				},
-				"view": {
-					Type:        "string",
-					Description: "View name or ID to use",
-				},
-				"sort": {
-					Type:        "array",
-					Description: "Sort configuration: [{field: string, direction: 'asc'|'desc'}]",
-				},
-				"page_size": {
-					Type:        "number",
-					Description: "Number of records per page (max 100)",
-				},
-				"max_records": {
-					Type:        "number",
-					Description: "Maximum number of records to return",
-				},
-				"offset": {
-					Type:        "string",
-					Description: "Pagination offset from previous response",
-				},
-			},
-			Required: []string{"base_id", "table"},
-		},
-	},
-	{
-		Name:        "get_record",
-		Description: "Retrieve a single record by ID",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID",
-				},
-				"record_id": {
-					Type:        "string",
-					Description: "Record ID (starts with 'rec')",
-				},
-			},
-			Required: []string{"base_id", "table", "record_id"},
-		},
-	},
-	{
-		Name:        "create",
-		Description: "Create new records in a table. Supports batch creation (up to 10 records per request)",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID",
-				},
-				"records": {
-					Type:        "array",
-					Description: "Array of records to create. Each record is {fields: {fieldName: value}}",
-				},
-				"typecast": {
-					Type:        "boolean",
-					Description: "Automatically typecast field values (default: false)",
-				},
-			},
-			Required: []string{"base_id", "table", "records"},
-		},
-	},
-	{
-		Name:        "update",
-		Description: "Update existing records. Supports batch update (up to 10 records per request). Uses PATCH (partial update)",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID",
-				},
-				"records": {
-					Type:        "array",
-					Description: "Array of records to update. Each record is {id: recordId, fields: {fieldName: value}}",
-				},
-				"typecast": {
-					Type:        "boolean",
-					Description: "Automatically typecast field values (default: false)",
-				},
-			},
-			Required: []string{"base_id", "table", "records"},
-		},
-	},
-	{
-		Name:        "delete",
-		Description: "Delete records from a table. Supports batch deletion (up to 10 records per request)",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"base_id": {
-					Type:        "string",
-					Description: "Base ID (starts with 'app')",
-				},
-				"table": {
-					Type:        "string",
-					Description: "Table name or ID",
-				},
-				"record_ids": {
-					Type:        "array",
-					Description: "Array of record IDs to delete",
-				},
-			},
-			Required: []string{"base_id", "table", "record_ids"},
-		},
+		URITemplate: "airtable://{base_id}/{table}/{record_id}",
+		Name:        "record",
+		Description: "A single Airtable record",
+		MimeType:    "application/json",
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"list_bases": listBases,
-	"describe":   describe,
-	"query":      query,
-	"get_record": getRecord,
-	"create":     create,
-	"update":     update,
-	"delete":     deleteRecords,
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"record": readRecordResource,
+}
+
+var completers = map[string]modules.Completer{
+	"base_id": completeBaseID,
+	"table":   completeTable,
+}
+
+// =============================================================================
+// Params and Results
+// =============================================================================
+
+// tableRef identifies a table; it is embedded in every record operation
+type tableRef struct {
+	BaseID string `json:"base_id" jsonschema:"required" description:"Base ID (starts with 'app')"`
+	Table  string `json:"table" jsonschema:"required" description:"Table name or ID"`
+}
+
+type listBasesParams struct{}
+
+type describeParams struct {
+	BaseID       string `json:"base_id" jsonschema:"required" description:"Base ID (starts with 'app')"`
+	Scope        string `json:"scope,omitempty" jsonschema:"enum=base|table,default=base" description:"Scope of description: 'base' for all tables, 'table' for a specific table"`
+	Table        string `json:"table,omitempty" description:"Table name or ID (required when scope='table')"`
+	DetailLevel  string `json:"detail_level,omitempty" jsonschema:"enum=tableIdentifiersOnly|identifiersOnly|full,default=full" description:"Detail level: tableIdentifiersOnly, identifiersOnly, or full (default: full)"`
+	IncludeViews bool   `json:"include_views,omitempty" jsonschema:"default=false" description:"Include view information (default: false)"`
+}
+
+type describeResult struct {
+	BaseID string        `json:"base_id" jsonschema:"required" description:"Base ID"`
+	Tables []interface{} `json:"tables" jsonschema:"required" description:"Tables filtered to the requested detail level"`
+}
+
+type sortSpec struct {
+	Field     string `json:"field" jsonschema:"required" description:"Field name"`
+	Direction string `json:"direction,omitempty" jsonschema:"enum=asc|desc,default=asc"`
+}
+
+type queryParams struct {
+	tableRef
+	Fields          []string   `json:"fields,omitempty" description:"Array of field names to return"`
+	FilterByFormula string     `json:"filter_by_formula,omitempty" description:"Airtable formula to filter records"`
+	View            string     `json:"view,omitempty" description:"View name or ID to use"`
+	Sort            []sortSpec `json:"sort,omitempty" description:"Sort configuration: [{field: string, direction: 'asc'|'desc'}]"`
+	PageSize        int        `json:"page_size,omitempty" jsonschema:"minimum=1,maximum=100" description:"Number of records per page (max 100)"`
+	MaxRecords      int        `json:"max_records,omitempty" jsonschema:"minimum=1" description:"Maximum number of records to return"`
+	Offset          string     `json:"offset,omitempty" description:"Pagination offset from previous response"`
+}
+
+type queryResult struct {
+	Records []interface{} `json:"records" jsonschema:"required" description:"Matching records: [{id, createdTime, fields}]"`
+	Offset  string        `json:"offset,omitempty" description:"Offset for the next page; absent on the last page"`
+}
+
+type recordParams struct {
+	tableRef
+	RecordID string `json:"record_id" jsonschema:"required" description:"Record ID (starts with 'rec')"`
+}
+
+type newRecord struct {
+	Fields map[string]interface{} `json:"fields" jsonschema:"required" description:"Field values keyed by field name"`
+}
+
+type createParams struct {
+	tableRef
+	Records  []newRecord `json:"records" jsonschema:"required,minItems=1" description:"Array of records to create. Each record is {fields: {fieldName: value}}"`
+	Typecast bool        `json:"typecast" jsonschema:"default=false" description:"Automatically typecast field values (default: false)"`
+}
+
+type recordUpdate struct {
+	ID     string                 `json:"id" jsonschema:"required" description:"Record ID (starts with 'rec')"`
+	Fields map[string]interface{} `json:"fields" jsonschema:"required" description:"Field values to change keyed by field name"`
+}
+
+type updateParams struct {
+	tableRef
+	Records  []recordUpdate `json:"records" jsonschema:"required,minItems=1" description:"Array of records to update. Each record is {id: recordId, fields: {fieldName: value}}"`
+	Typecast bool           `json:"typecast" jsonschema:"default=false" description:"Automatically typecast field values (default: false)"`
+}
+
+type deleteParams struct {
+	tableRef
+	RecordIDs []string `json:"record_ids" jsonschema:"required,minItems=1" description:"Array of record IDs to delete"`
+}
+
+// writeResult is returned by create, update and delete. Summary holds the
+// count under the operation name, e.g. {"created": 3}.
+type writeResult struct {
+	Records []interface{}  `json:"records" jsonschema:"required" description:"Records returned by Airtable"`
+	Summary map[string]int `json:"summary" jsonschema:"required" description:"Number of records written"`
+}
+
+func (r tableRef) endpoint() string {
+	return fmt.Sprintf("%s/%s/%s", airtableAPIBase, url.PathEscape(r.BaseID), url.PathEscape(r.Table))
 }
 
 // =============================================================================
 // Base Operations
 // =============================================================================
 
-func listBases(params map[string]interface{}) (string, error) {
+func listBases(ctx context.Context, params listBasesParams) (json.RawMessage, error) {
 	endpoint := "https://api.airtable.com/v0/meta/bases"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(httpclient.Cacheable(ctx), "GET", endpoint, headers(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return httpclient.PrettyJSON(respBody), nil
+	return json.RawMessage(respBody), nil
 }
 
 // =============================================================================
 // Schema Operations
 // =============================================================================
 
-func describe(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
-	}
-
-	scope, _ := params["scope"].(string)
-	if scope == "" {
-		scope = "base"
-	}
-
+func describe(ctx context.Context, params describeParams) (describeResult, error) {
 	// Get tables (this endpoint returns table schema)
-	tablesEndpoint := fmt.Sprintf("https://api.airtable.com/v0/meta/bases/%s/tables", url.PathEscape(baseID))
-	tablesInfoBytes, err := client.DoJSON("GET", tablesEndpoint, headers(), nil)
+	tablesEndpoint := fmt.Sprintf("https://api.airtable.com/v0/meta/bases/%s/tables", url.PathEscape(params.BaseID))
+	tablesInfoBytes, err := client.DoJSON(httpclient.Cacheable(ctx), "GET", tablesEndpoint, headers(), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to get tables: %w", err)
+		return describeResult{}, fmt.Errorf("failed to get tables: %w", err)
 	}
 
-	var tablesData map[string]interface{}
-	if err := json.Unmarshal(tablesInfoBytes, &tablesData); err != nil {
-		return "", fmt.Errorf("failed to parse tables: %w", err)
+	var tablesData struct {
+		Tables []interface{} `json:"tables"`
 	}
-
-	// Build response
-	result := map[string]interface{}{
-		"base_id": baseID,
+	if err := json.Unmarshal(tablesInfoBytes, &tablesData); err != nil {
+		return describeResult{}, fmt.Errorf("failed to parse tables: %w", err)
 	}
-	tables, _ := tablesData["tables"].([]interface{})
+	tables := tablesData.Tables
 
-	detailLevel, _ := params["detail_level"].(string)
+	detailLevel := params.DetailLevel
 	if detailLevel == "" {
 		detailLevel = "full"
 	}
 
-	includeViews := false
-	if iv, ok := params["include_views"].(bool); ok {
-		includeViews = iv
-	}
-
 	// Filter to specific table if scope is "table"
-	if scope == "table" {
-		tableName, _ := params["table"].(string)
-		if tableName == "" {
-			return "", fmt.Errorf("table is required when scope is 'table'")
+	if params.Scope == "table" {
+		if params.Table == "" {
+			return describeResult{}, fmt.Errorf("table is required when scope is 'table'")
 		}
 
 		var foundTable interface{}
 		for _, t := range tables {
 			tbl, _ := t.(map[string]interface{})
-			if tbl["name"] == tableName || tbl["id"] == tableName {
+			if tbl["name"] == params.Table || tbl["id"] == params.Table {
 				foundTable = t
 				break
 			}
 		}
 
 		if foundTable == nil {
-			return "", fmt.Errorf("table '%s' not found", tableName)
+			return describeResult{}, fmt.Errorf("table '%s' not found", params.Table)
 		}
 
 		tables = []interface{}{foundTable}
 	}
 
 	// Apply detail level filtering
-	filteredTables := filterTablesDetail(tables, detailLevel, includeViews)
-	result["tables"] = filteredTables
-
-	return httpclient.PrettyJSONFromInterface(result), nil
+	return describeResult{
+		BaseID: params.BaseID,
+		Tables: filterTablesDetail(tables, detailLevel, params.IncludeViews),
+	}, nil
 }
 
 func filterTablesDetail(tables []interface{}, detailLevel string, includeViews bool) []interface{} {
@@ -398,286 +324,215 @@ func filterTablesDetail(tables []interface{}, detailLevel string, includeViews b
 // Record Operations
 // =============================================================================
 
-func query(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
-	}
-
-	table, ok := params["table"].(string)
-	if !ok || table == "" {
-		return "", fmt.Errorf("table is required")
-	}
-
+func query(ctx context.Context, params queryParams) (queryResult, error) {
 	// Build query parameters
-	queryParams := url.Values{}
+	queryValues := url.Values{}
 
-	if fields, ok := params["fields"].([]interface{}); ok {
-		for _, f := range fields {
-			if fieldName, ok := f.(string); ok {
-				queryParams.Add("fields[]", fieldName)
-			}
-		}
+	for _, fieldName := range params.Fields {
+		queryValues.Add("fields[]", fieldName)
 	}
 
-	if formula, ok := params["filter_by_formula"].(string); ok && formula != "" {
-		queryParams.Set("filterByFormula", formula)
+	if params.FilterByFormula != "" {
+		queryValues.Set("filterByFormula", params.FilterByFormula)
 	}
 
-	if view, ok := params["view"].(string); ok && view != "" {
-		queryParams.Set("view", view)
+	if params.View != "" {
+		queryValues.Set("view", params.View)
 	}
 
-	if sorts, ok := params["sort"].([]interface{}); ok {
-		for i, s := range sorts {
-			sort, _ := s.(map[string]interface{})
-			if field, ok := sort["field"].(string); ok {
-				queryParams.Set(fmt.Sprintf("sort[%d][field]", i), field)
-				direction := "asc"
-				if dir, ok := sort["direction"].(string); ok {
-					direction = dir
-				}
-				queryParams.Set(fmt.Sprintf("sort[%d][direction]", i), direction)
-			}
+	for i, sort := range params.Sort {
+		direction := sort.Direction
+		if direction == "" {
+			direction = "asc"
 		}
+		queryValues.Set(fmt.Sprintf("sort[%d][field]", i), sort.Field)
+		queryValues.Set(fmt.Sprintf("sort[%d][direction]", i), direction)
 	}
 
-	if pageSize, ok := params["page_size"].(float64); ok {
-		queryParams.Set("pageSize", fmt.Sprintf("%d", int(pageSize)))
+	if params.PageSize > 0 {
+		queryValues.Set("pageSize", fmt.Sprintf("%d", params.PageSize))
 	}
 
-	if maxRecords, ok := params["max_records"].(float64); ok {
-		queryParams.Set("maxRecords", fmt.Sprintf("%d", int(maxRecords)))
+	if params.MaxRecords > 0 {
+		queryValues.Set("maxRecords", fmt.Sprintf("%d", params.MaxRecords))
 	}
 
-	if offset, ok := params["offset"].(string); ok && offset != "" {
-		queryParams.Set("offset", offset)
+	if params.Offset != "" {
+		queryValues.Set("offset", params.Offset)
 	}
 
-	endpoint := fmt.Sprintf("%s/%s/%s", airtableAPIBase, url.PathEscape(baseID), url.PathEscape(table))
-	if len(queryParams) > 0 {
-		endpoint += "?" + queryParams.Encode()
+	endpoint := params.endpoint()
+	if len(queryValues) > 0 {
+		endpoint += "?" + queryValues.Encode()
 	}
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.OffsetToken("records"))
 	if err != nil {
-		return "", err
-	}
-
-	return httpclient.PrettyJSON(respBody), nil
-}
-
-func getRecord(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
+		return queryResult{}, err
 	}
 
-	table, ok := params["table"].(string)
-	if !ok || table == "" {
-		return "", fmt.Errorf("table is required")
+	var result queryResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return queryResult{}, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	recordID, ok := params["record_id"].(string)
-	if !ok || recordID == "" {
-		return "", fmt.Errorf("record_id is required")
+	if result.Records == nil {
+		result.Records = []interface{}{}
 	}
+	return result, nil
+}
 
-	endpoint := fmt.Sprintf("%s/%s/%s/%s", airtableAPIBase, url.PathEscape(baseID), url.PathEscape(table), url.PathEscape(recordID))
+func getRecord(ctx context.Context, params recordParams) (json.RawMessage, error) {
+	endpoint := fmt.Sprintf("%s/%s", params.endpoint(), url.PathEscape(params.RecordID))
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return httpclient.PrettyJSON(respBody), nil
+	return json.RawMessage(respBody), nil
 }
 
-func create(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
+func create(ctx context.Context, params createParams) (writeResult, error) {
+	created, err := inBatches(ctx, params.Records, "Created", func(chunk []newRecord) (json.RawMessage, error) {
+		body := map[string]interface{}{
+			"records":  chunk,
+			"typecast": params.Typecast,
+		}
+		return client.DoJSON(ctx, "POST", params.endpoint(), headers(), body)
+	})
+	if err != nil {
+		return writeResult{}, fmt.Errorf("failed to create records %w", err)
 	}
 
-	table, ok := params["table"].(string)
-	if !ok || table == "" {
-		return "", fmt.Errorf("table is required")
-	}
+	return writeResult{Records: created, Summary: map[string]int{"created": len(created)}}, nil
+}
 
-	records, ok := params["records"].([]interface{})
-	if !ok || len(records) == 0 {
-		return "", fmt.Errorf("records is required and must not be empty")
+func update(ctx context.Context, params updateParams) (writeResult, error) {
+	updated, err := inBatches(ctx, params.Records, "Updated", func(chunk []recordUpdate) (json.RawMessage, error) {
+		body := map[string]interface{}{
+			"records":  chunk,
+			"typecast": params.Typecast,
+		}
+		return client.DoJSON(ctx, "PATCH", params.endpoint(), headers(), body)
+	})
+	if err != nil {
+		return writeResult{}, fmt.Errorf("failed to update records %w", err)
 	}
 
-	typecast := false
-	if tc, ok := params["typecast"].(bool); ok {
-		typecast = tc
+	return writeResult{Records: updated, Summary: map[string]int{"updated": len(updated)}}, nil
+}
+
+func deleteRecords(ctx context.Context, params deleteParams) (writeResult, error) {
+	deleted, err := inBatches(ctx, params.RecordIDs, "Deleted", func(chunk []string) (json.RawMessage, error) {
+		// Build query parameters for DELETE
+		queryValues := url.Values{}
+		for _, recordID := range chunk {
+			queryValues.Add("records[]", recordID)
+		}
+		return client.DoJSON(ctx, "DELETE", params.endpoint()+"?"+queryValues.Encode(), headers(), nil)
+	})
+	if err != nil {
+		return writeResult{}, fmt.Errorf("failed to delete records %w", err)
 	}
 
-	// Process records in chunks of 10
-	allCreated := make([]interface{}, 0)
+	return writeResult{Records: deleted, Summary: map[string]int{"deleted": len(deleted)}}, nil
+}
 
-	for i := 0; i < len(records); i += 10 {
-		end := i + 10
-		if end > len(records) {
-			end = len(records)
-		}
-		chunk := records[i:end]
+// inBatches sends items to Airtable batchSize at a time, reporting progress
+// after each batch, and collects the records from every response
+func inBatches[T any](ctx context.Context, items []T, verb string, send func(chunk []T) (json.RawMessage, error)) ([]interface{}, error) {
+	all := make([]interface{}, 0, len(items))
 
-		body := map[string]interface{}{
-			"records":  chunk,
-			"typecast": typecast,
+	for i := 0; i < len(items); i += batchSize {
+		end := i + batchSize
+		if end > len(items) {
+			end = len(items)
 		}
 
-		endpoint := fmt.Sprintf("%s/%s/%s", airtableAPIBase, url.PathEscape(baseID), url.PathEscape(table))
-
-		respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+		respBody, err := send(items[i:end])
 		if err != nil {
-			return "", fmt.Errorf("failed to create records (batch %d): %w", i/10+1, err)
+			return nil, fmt.Errorf("(batch %d): %w", i/batchSize+1, err)
 		}
 
-		var resp map[string]interface{}
-		if err := json.Unmarshal(respBody, &resp); err != nil {
-			return "", fmt.Errorf("failed to parse response: %w", err)
+		var resp struct {
+			Records []interface{} `json:"records"`
 		}
-		if created, ok := resp["records"].([]interface{}); ok {
-			allCreated = append(allCreated, created...)
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return nil, fmt.Errorf("(batch %d): failed to parse response: %w", i/batchSize+1, err)
 		}
+		all = append(all, resp.Records...)
+		modules.ReportProgress(ctx, float64(end), float64(len(items)), fmt.Sprintf("%s %d of %d records", verb, end, len(items)))
 	}
 
-	result := map[string]interface{}{
-		"records": allCreated,
-		"summary": map[string]interface{}{
-			"created": len(allCreated),
-		},
-	}
-
-	return httpclient.PrettyJSONFromInterface(result), nil
+	return all, nil
 }
 
-func update(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
-	}
+// =============================================================================
+// Resources
+// =============================================================================
 
-	table, ok := params["table"].(string)
-	if !ok || table == "" {
-		return "", fmt.Errorf("table is required")
+func readRecordResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	record, err := getRecord(ctx, recordParams{
+		tableRef: tableRef{BaseID: vars["base_id"], Table: vars["table"]},
+		RecordID: vars["record_id"],
+	})
+	if err != nil {
+		return nil, err
 	}
+	return []modules.ResourceContents{{
+		URI:      fmt.Sprintf("airtable://%s/%s/%s", vars["base_id"], url.PathEscape(vars["table"]), vars["record_id"]),
+		MimeType: "application/json",
+		Text:     httpclient.PrettyJSON(record),
+	}}, nil
+}
 
-	records, ok := params["records"].([]interface{})
-	if !ok || len(records) == 0 {
-		return "", fmt.Errorf("records is required and must not be empty")
-	}
+// =============================================================================
+// Completion
+// =============================================================================
 
-	typecast := false
-	if tc, ok := params["typecast"].(bool); ok {
-		typecast = tc
+func completeBaseID(ctx context.Context, args map[string]string) ([]string, error) {
+	raw, err := listBases(ctx, listBasesParams{})
+	if err != nil {
+		return nil, err
 	}
 
-	// Process records in chunks of 10
-	allUpdated := make([]interface{}, 0)
-
-	for i := 0; i < len(records); i += 10 {
-		end := i + 10
-		if end > len(records) {
-			end = len(records)
-		}
-		chunk := records[i:end]
-
-		body := map[string]interface{}{
-			"records":  chunk,
-			"typecast": typecast,
-		}
-
-		endpoint := fmt.Sprintf("%s/%s/%s", airtableAPIBase, url.PathEscape(baseID), url.PathEscape(table))
-
-		respBody, err := client.DoJSON("PATCH", endpoint, headers(), body)
-		if err != nil {
-			return "", fmt.Errorf("failed to update records (batch %d): %w", i/10+1, err)
-		}
-
-		var resp map[string]interface{}
-		if err := json.Unmarshal(respBody, &resp); err != nil {
-			return "", fmt.Errorf("failed to parse response: %w", err)
-		}
-		if updated, ok := resp["records"].([]interface{}); ok {
-			allUpdated = append(allUpdated, updated...)
-		}
+	var result struct {
+		Bases []struct {
+			ID string `json:"id"`
+		} `json:"bases"`
 	}
-
-	result := map[string]interface{}{
-		"records": allUpdated,
-		"summary": map[string]interface{}{
-			"updated": len(allUpdated),
-		},
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse bases: %w", err)
 	}
 
-	return httpclient.PrettyJSONFromInterface(result), nil
-}
-
-func deleteRecords(params map[string]interface{}) (string, error) {
-	baseID, ok := params["base_id"].(string)
-	if !ok || baseID == "" {
-		return "", fmt.Errorf("base_id is required")
+	ids := make([]string, 0, len(result.Bases))
+	for _, base := range result.Bases {
+		ids = append(ids, base.ID)
 	}
+	return ids, nil
+}
 
-	table, ok := params["table"].(string)
-	if !ok || table == "" {
-		return "", fmt.Errorf("table is required")
+// completeTable suggests the table names of the base given in base_id
+func completeTable(ctx context.Context, args map[string]string) ([]string, error) {
+	if args["base_id"] == "" {
+		return []string{}, nil
 	}
 
-	recordIDs, ok := params["record_ids"].([]interface{})
-	if !ok || len(recordIDs) == 0 {
-		return "", fmt.Errorf("record_ids is required and must not be empty")
+	result, err := describe(ctx, describeParams{
+		BaseID:      args["base_id"],
+		DetailLevel: "tableIdentifiersOnly",
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Process records in chunks of 10
-	allDeleted := make([]interface{}, 0)
-
-	for i := 0; i < len(recordIDs); i += 10 {
-		end := i + 10
-		if end > len(recordIDs) {
-			end = len(recordIDs)
-		}
-		chunk := recordIDs[i:end]
-
-		// Build query parameters for DELETE
-		queryParams := url.Values{}
-		for _, id := range chunk {
-			if recordID, ok := id.(string); ok {
-				queryParams.Add("records[]", recordID)
+	names := make([]string, 0, len(result.Tables))
+	for _, t := range result.Tables {
+		if table, ok := t.(map[string]interface{}); ok {
+			if name, ok := table["name"].(string); ok {
+				names = append(names, name)
 			}
 		}
-
-		endpoint := fmt.Sprintf("%s/%s/%s?%s", airtableAPIBase, url.PathEscape(baseID), url.PathEscape(table), queryParams.Encode())
-
-		respBody, err := client.DoJSON("DELETE", endpoint, headers(), nil)
-		if err != nil {
-			return "", fmt.Errorf("failed to delete records (batch %d): %w", i/10+1, err)
-		}
-
-		var resp map[string]interface{}
-		if err := json.Unmarshal(respBody, &resp); err != nil {
-			return "", fmt.Errorf("failed to parse response: %w", err)
-		}
-		if deleted, ok := resp["records"].([]interface{}); ok {
-			allDeleted = append(allDeleted, deleted...)
-		}
-	}
-
-	result := map[string]interface{}{
-		"records": allDeleted,
-		"summary": map[string]interface{}{
-			"deleted": len(allDeleted),
-		},
 	}
-
-	return httpclient.PrettyJSONFromInterface(result), nil
+	return names, nil
 }
-
-// Ensure json package is used
-var _ = json.Marshal
-
-
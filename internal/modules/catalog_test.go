@@ -0,0 +1,69 @@
+package modules_test
+
+import (
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/airtable"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/confluence"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/github"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/jira"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/notion"
+	"github.com/shibaleo/go-mcp-dev/internal/modules/supabase"
+)
+
+// catalog registers the real modules, to check search and schema rendering
+// against their actual tool definitions
+func catalog(t *testing.T) *modules.Registry {
+	t.Helper()
+	registry := modules.NewRegistry()
+	for _, module := range []modules.ModuleDefinition{
+		supabase.Module(), notion.Module(), github.Module(), jira.Module(), confluence.Module(), airtable.Module(),
+	} {
+		if err := registry.Register(module); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+func TestSearchTools_RanksRealModules(t *testing.T) {
+	registry := catalog(t)
+
+	for query, want := range map[string]string{
+		"list open pull requests":  "github/list_prs",
+		"create a jira issue":      "jira/create_issue",
+		"query a notion database":  "notion/query_database",
+		"workflow runs for a repo": "github/list_workflow_runs",
+	} {
+		matches := registry.SearchTools(query, "", 3)
+		var got []string
+		found := false
+		for _, match := range matches {
+			name := match.Module + "/" + match.Tool.Name
+			got = append(got, name)
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("SearchTools(%q) top 3 = %v, want %s among them", query, got, want)
+		}
+	}
+}
+
+func TestGetModuleSchema_CompactFormatsAreSmaller(t *testing.T) {
+	registry := catalog(t)
+
+	for _, module := range registry.Modules() {
+		size := map[modules.SchemaFormat]int{}
+		for _, format := range modules.SchemaFormats {
+			result, err := registry.GetModuleSchema(module.Name, modules.SchemaOptions{Format: modules.SchemaFormat(format)})
+			if err != nil || result.IsError {
+				t.Fatalf("%s/%s: %v %+v", module.Name, format, err, result)
+			}
+			size[modules.SchemaFormat(format)] = len(result.Content[0].Text)
+		}
+		if size[modules.SchemaFormatTypeScript] >= size[modules.SchemaFormatJSONMin] || size[modules.SchemaFormatJSONMin] >= size[modules.SchemaFormatJSON] {
+			t.Errorf("%s: sizes = %v", module.Name, size)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package modules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// moduleNamePattern keeps module names usable as URI schemes, tool
+// identifiers and prompt prefixes
+var moduleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+var propertyTypes = map[string]bool{
+	"": true, "string": true, "integer": true, "number": true,
+	"boolean": true, "array": true, "object": true,
+}
+
+// CheckModule reports inconsistencies in a module definition: tools without
+// handlers and handlers without tools, malformed schemas, resource templates,
+// prompts and completers that do not line up with each other.
+func CheckModule(module ModuleDefinition) error {
+	c := &moduleChecker{}
+
+	if !moduleNamePattern.MatchString(module.Name) {
+		c.fail("name must be lowercase letters, digits and single underscores")
+	}
+	if module.Description == "" {
+		c.fail("description is empty")
+	}
+
+	c.checkTools(module)
+	c.checkResources(module)
+	c.checkPrompts(module)
+	c.checkCompleters(module)
+	if limit := module.RateLimit; limit != nil && (limit.Rate <= 0 || limit.Burst < 1) {
+		c.fail("rate limit needs a positive Rate and a Burst of at least 1")
+	}
+
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("module %s:\n  %s", module.Name, strings.Join(c.problems, "\n  "))
+}
+
+type moduleChecker struct {
+	problems []string
+}
+
+func (c *moduleChecker) fail(format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+func (c *moduleChecker) checkTools(module ModuleDefinition) {
+	declared := make(map[string]bool, len(module.Tools))
+	for _, tool := range module.Tools {
+		if tool.Name == "" {
+			c.fail("tool with empty name")
+			continue
+		}
+		if declared[tool.Name] {
+			c.fail("tool %s: declared twice", tool.Name)
+		}
+		declared[tool.Name] = true
+
+		if strings.Contains(tool.Name, NameSeparator) {
+			c.fail("tool %s: name must not contain %q", tool.Name, NameSeparator)
+		}
+		if tool.Description == "" {
+			c.fail("tool %s: description is empty", tool.Name)
+		}
+		_, plain := module.Handlers[tool.Name]
+		_, result := module.ResultHandlers[tool.Name]
+		switch {
+		case !plain && !result:
+			c.fail("tool %s: no handler", tool.Name)
+		case plain && result:
+			c.fail("tool %s: both Handlers and ResultHandlers entries", tool.Name)
+		}
+
+		if tool.InputSchema.Type != "object" {
+			c.fail("tool %s: inputSchema type must be object", tool.Name)
+		}
+		c.checkObject("tool "+tool.Name+": input", tool.InputSchema.Properties, tool.InputSchema.Required)
+		for _, name := range sortedKeys(tool.InputSchema.Properties) {
+			if strings.HasPrefix(name, "_") {
+				c.fail("tool %s: input.%s: the _ prefix is reserved for response shaping params", tool.Name, name)
+			}
+		}
+		if tool.OutputSchema != nil {
+			c.checkObject("tool "+tool.Name+": output", tool.OutputSchema.Properties, tool.OutputSchema.Required)
+		}
+	}
+
+	for _, name := range sortedKeys(module.Handlers) {
+		if !declared[name] {
+			c.fail("handler %s: no tool definition", name)
+		}
+	}
+	for _, name := range sortedKeys(module.ResultHandlers) {
+		if !declared[name] {
+			c.fail("result handler %s: no tool definition", name)
+		}
+	}
+	for _, name := range sortedKeys(module.Compactors) {
+		if !declared[name] {
+			c.fail("compactor %s: no tool definition", name)
+		}
+	}
+	for _, name := range sortedKeys(module.Paginated) {
+		if !declared[name] {
+			c.fail("paginated tool %s: no tool definition", name)
+		}
+	}
+}
+
+func (c *moduleChecker) checkObject(path string, props map[string]Property, required []string) {
+	for _, name := range required {
+		if _, ok := props[name]; !ok {
+			c.fail("%s: required %s is not a property", path, name)
+		}
+	}
+	for _, name := range sortedKeys(props) {
+		c.checkProperty(path+"."+name, props[name])
+	}
+}
+
+func (c *moduleChecker) checkProperty(path string, prop Property) {
+	if !propertyTypes[prop.Type] {
+		c.fail("%s: unknown type %q", path, prop.Type)
+		return
+	}
+	numeric := prop.Type == "integer" || prop.Type == "number"
+
+	if len(prop.Enum) > 0 && prop.Type != "string" {
+		c.fail("%s: enum is only supported on strings", path)
+	}
+	if (prop.Minimum != nil || prop.Maximum != nil) && !numeric {
+		c.fail("%s: minimum/maximum on a non-numeric property", path)
+	}
+	if prop.Minimum != nil && prop.Maximum != nil && *prop.Minimum > *prop.Maximum {
+		c.fail("%s: minimum is greater than maximum", path)
+	}
+	if (prop.MinItems != nil || prop.MaxItems != nil || prop.Items != nil) && prop.Type != "array" {
+		c.fail("%s: items constraints on a non-array property", path)
+	}
+	if (len(prop.Properties) > 0 || len(prop.Required) > 0) && prop.Type != "object" {
+		c.fail("%s: properties on a non-object property", path)
+	}
+
+	if prop.Default != nil {
+		v := &validator{}
+		v.value(path, prop, normalizeDefault(prop.Default))
+		for _, fe := range v.errors {
+			c.fail("%s: default %s", fe.Field, fe.Message)
+		}
+	}
+
+	if prop.Items != nil {
+		c.checkProperty(path+"[]", *prop.Items)
+	}
+	if prop.Type == "object" {
+		c.checkObject(path, prop.Properties, prop.Required)
+	}
+}
+
+func (c *moduleChecker) checkResources(module ModuleDefinition) {
+	declared := make(map[string]bool, len(module.ResourceTemplates))
+	for _, tmpl := range module.ResourceTemplates {
+		if declared[tmpl.Name] {
+			c.fail("resource template %s: declared twice", tmpl.Name)
+		}
+		declared[tmpl.Name] = true
+
+		if !strings.HasPrefix(tmpl.URITemplate, module.Name+"://") {
+			c.fail("resource template %s: URI must use the %s:// scheme", tmpl.Name, module.Name)
+		}
+		if _, ok := module.ResourceHandlers[tmpl.Name]; !ok {
+			c.fail("resource template %s: no handler", tmpl.Name)
+		}
+	}
+	for _, name := range sortedKeys(module.ResourceHandlers) {
+		if !declared[name] {
+			c.fail("resource handler %s: no template", name)
+		}
+	}
+}
+
+func (c *moduleChecker) checkPrompts(module ModuleDefinition) {
+	declared := make(map[string]bool, len(module.Prompts))
+	for _, prompt := range module.Prompts {
+		if declared[prompt.Name] {
+			c.fail("prompt %s: declared twice", prompt.Name)
+		}
+		declared[prompt.Name] = true
+
+		if strings.Contains(prompt.Name, NameSeparator) {
+			c.fail("prompt %s: name must not contain %q", prompt.Name, NameSeparator)
+		}
+		if _, ok := module.PromptHandlers[prompt.Name]; !ok {
+			c.fail("prompt %s: no handler", prompt.Name)
+		}
+	}
+	for _, name := range sortedKeys(module.PromptHandlers) {
+		if !declared[name] {
+			c.fail("prompt handler %s: no prompt definition", name)
+		}
+	}
+}
+
+// checkCompleters flags completers for arguments that no prompt or resource
+// template of the module takes, since they can never be asked for
+func (c *moduleChecker) checkCompleters(module ModuleDefinition) {
+	arguments := map[string]bool{}
+	for _, prompt := range module.Prompts {
+		for _, arg := range prompt.Arguments {
+			arguments[arg.Name] = true
+		}
+	}
+	for _, tmpl := range module.ResourceTemplates {
+		for _, match := range templateVarPattern.FindAllStringSubmatch(tmpl.URITemplate, -1) {
+			arguments[match[1]] = true
+		}
+	}
+
+	for _, name := range sortedKeys(module.Completers) {
+		if !arguments[name] {
+			c.fail("completer %s: no prompt argument or template variable of that name", name)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,71 @@
+package modules
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
+)
+
+func TestCheckModule_ReportsInconsistencies(t *testing.T) {
+	noop := func(ctx context.Context, params map[string]interface{}) (string, error) { return "", nil }
+
+	err := CheckModule(ModuleDefinition{
+		Name:        "broken",
+		Description: "Inconsistent module",
+		Tools: []Tool{
+			{
+				Name:        "orphan",
+				Description: "Tool without a handler",
+				InputSchema: InputSchema{Type: "object", Required: []string{"id"}},
+			},
+			{
+				Name:        "limits",
+				Description: "Tool with bad constraints",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"count": {Type: "integer", Default: 500, Maximum: Max(100)},
+						"state": {Type: "boolean", Enum: []string{"open"}},
+					},
+				},
+			},
+		},
+		Handlers: map[string]ToolHandler{
+			"limits": noop,
+			"extra":  noop,
+		},
+		ResourceTemplates: []ResourceTemplate{
+			{URITemplate: "other://{id}", Name: "item"},
+		},
+		Compactors: map[string]Compactor{"gone": nil},
+		RateLimit:  &httpclient.RateLimit{Rate: 3},
+		Paginated:  map[string]bool{"missing": true},
+		Prompts:    []Prompt{{Name: "bad__name"}},
+		Completers: map[string]Completer{"nothing": nil},
+	})
+	if err == nil {
+		t.Fatal("expected problems")
+	}
+
+	for _, want := range []string{
+		"tool orphan: no handler",
+		"tool orphan: input: required id is not a property",
+		"handler extra: no tool definition",
+		"compactor gone: no tool definition",
+		"rate limit needs a positive Rate and a Burst of at least 1",
+		"paginated tool missing: no tool definition",
+		"tool limits: input.count: default must be at most 100",
+		"tool limits: input.state: enum is only supported on strings",
+		"resource template item: URI must use the broken:// scheme",
+		"resource template item: no handler",
+		`prompt bad__name: name must not contain "__"`,
+		"prompt bad__name: no handler",
+		"completer nothing: no prompt argument or template variable of that name",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
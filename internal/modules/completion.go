@@ -0,0 +1,82 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Completer returns candidate values for an argument; args holds the other
+// arguments already filled in. Candidates are cached per session and
+// filtered by what the user typed, so completers return all of them.
+type Completer func(ctx context.Context, args map[string]string) ([]string, error)
+
+// MaxCompletionValues is the most completion values returned at once
+const MaxCompletionValues = 100
+
+// ErrArgumentNotFound is returned for arguments a prompt or resource template
+// does not have
+var ErrArgumentNotFound = errors.New("argument not found")
+
+// PromptArgumentCandidates returns completion candidates for an argument of
+// a module__prompt
+func (r *Registry) PromptArgumentCandidates(ctx context.Context, name, argName string, args map[string]string) ([]string, error) {
+	module, prompt, ok := r.lookupPrompt(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+	for _, arg := range prompt.Arguments {
+		if arg.Name == argName {
+			return candidates(ctx, module, argName, args)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s of prompt %s", ErrArgumentNotFound, argName, name)
+}
+
+// ResourceArgumentCandidates returns completion candidates for a variable of
+// a resource template
+func (r *Registry) ResourceArgumentCandidates(ctx context.Context, uriTemplate, argName string, args map[string]string) ([]string, error) {
+	for _, module := range r.Modules() {
+		for _, template := range module.ResourceTemplates {
+			if template.URITemplate != uriTemplate {
+				continue
+			}
+			for _, match := range templateVarPattern.FindAllStringSubmatch(uriTemplate, -1) {
+				if match[1] == argName {
+					return candidates(ctx, module, argName, args)
+				}
+			}
+			return nil, fmt.Errorf("%w: %s of resource template %s", ErrArgumentNotFound, argName, uriTemplate)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uriTemplate)
+}
+
+func candidates(ctx context.Context, module ModuleDefinition, argName string, args map[string]string) ([]string, error) {
+	completer, ok := module.Completers[argName]
+	if !ok {
+		return []string{}, nil
+	}
+	return completer(ctx, args)
+}
+
+// FilterCompletions keeps the candidates starting with value, ignoring case.
+// It returns at most MaxCompletionValues sorted values and the total found.
+func FilterCompletions(candidates []string, value string) ([]string, int) {
+	prefix := strings.ToLower(value)
+	values := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+	sort.Strings(values)
+
+	total := len(values)
+	if total > MaxCompletionValues {
+		values = values[:MaxCompletionValues]
+	}
+	return values, total
+}
@@ -1,6 +1,7 @@
 package confluence
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/url"
@@ -17,7 +18,14 @@ const (
 	confluenceAPIVersion = "v2" // Primary API version (v2), with v1 fallback for some endpoints
 )
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("confluence"))
+
+// rateLimit stays clear of Atlassian Cloud's cost-based limits, which the
+// Retry-After of a 429 covers beyond that
+var rateLimit = httpclient.RateLimit{Rate: 10, Burst: 10}
+
+// pages follows _links.next, which both the v1 and v2 APIs send
+var pages = httpclient.LinksNext("results")
 
 func getDomain() string {
 	return os.Getenv("CONFLUENCE_DOMAIN")
@@ -50,284 +58,157 @@ func baseURLV1() string {
 // Module returns the Confluence module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "confluence",
-		Description: "Confluence API - Wiki操作（スペース、ページ、検索、コメント、ラベル）",
-		APIVersion:  confluenceAPIVersion,
-		TestedAt:    "2026-01-10",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "confluence",
+		Description:       "Confluence API - Wiki操作（スペース、ページ、検索、コメント、ラベル）",
+		APIVersion:        confluenceAPIVersion,
+		TestedAt:          "2026-01-10",
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		Prompts:           prompts,
+		PromptHandlers:    promptHandlers,
+		Completers:        completers,
+	}.WithTools(tools...).WithPagination("list_spaces", "get_pages", "search", "get_page_comments")
 }
 
-var tools = []modules.Tool{
-	{
-		Name:        "list_spaces",
-		Description: "List all Confluence spaces accessible to the current user.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"limit": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 25",
-				},
-				"cursor": {
-					Type:        "string",
-					Description: "Pagination cursor for next page",
-				},
-			},
-		},
-	},
-	{
-		Name:        "get_space",
-		Description: "Get details of a specific Confluence space by ID or key.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"space_id_or_key": {
-					Type:        "string",
-					Description: "Space ID (numeric) or key (e.g., 'MYSPACE')",
-				},
-			},
-			Required: []string{"space_id_or_key"},
-		},
-	},
-	{
-		Name:        "get_pages",
-		Description: "List pages in a Confluence space.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"space_id": {
-					Type:        "string",
-					Description: "Space ID (numeric). Use get_space to get ID from key.",
-				},
-				"limit": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 25",
-				},
-				"cursor": {
-					Type:        "string",
-					Description: "Pagination cursor for next page",
-				},
-			},
-			Required: []string{"space_id"},
-		},
-	},
-	{
-		Name:        "get_page",
-		Description: "Get a Confluence page by ID with its content.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-				"body_format": {
-					Type:        "string",
-					Description: "Body format: storage (XHTML) or atlas_doc_format. Default: storage",
-				},
-			},
-			Required: []string{"page_id"},
-		},
-	},
-	{
-		Name:        "create_page",
-		Description: "Create a new Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"space_id": {
-					Type:        "string",
-					Description: "Space ID (numeric)",
-				},
-				"title": {
-					Type:        "string",
-					Description: "Page title",
-				},
-				"body": {
-					Type:        "string",
-					Description: "Page body in storage format (XHTML)",
-				},
-				"parent_id": {
-					Type:        "string",
-					Description: "Parent page ID for nested pages",
-				},
-			},
-			Required: []string{"space_id", "title", "body"},
-		},
-	},
-	{
-		Name:        "update_page",
-		Description: "Update an existing Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-				"title": {
-					Type:        "string",
-					Description: "New page title",
-				},
-				"body": {
-					Type:        "string",
-					Description: "New page body in storage format (XHTML)",
-				},
-				"version": {
-					Type:        "number",
-					Description: "Current version number (must be incremented)",
-				},
-			},
-			Required: []string{"page_id", "title", "body", "version"},
-		},
-	},
-	{
-		Name:        "delete_page",
-		Description: "Delete a Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-			},
-			Required: []string{"page_id"},
-		},
-	},
-	{
-		Name:        "search",
-		Description: "Search Confluence content using CQL (Confluence Query Language). Example: 'type=page AND space=MYSPACE AND text~\"keyword\"'",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"cql": {
-					Type:        "string",
-					Description: "CQL query string",
-				},
-				"limit": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 25",
-				},
-				"start": {
-					Type:        "number",
-					Description: "Starting index for pagination. Default: 0",
-				},
-			},
-			Required: []string{"cql"},
-		},
-	},
-	{
-		Name:        "get_page_comments",
-		Description: "Get comments on a Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-				"limit": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 25",
-				},
-				"cursor": {
-					Type:        "string",
-					Description: "Pagination cursor for next page",
-				},
-			},
-			Required: []string{"page_id"},
-		},
-	},
-	{
-		Name:        "add_page_comment",
-		Description: "Add a comment to a Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-				"body": {
-					Type:        "string",
-					Description: "Comment body in storage format (XHTML)",
-				},
-			},
-			Required: []string{"page_id", "body"},
-		},
-	},
+var tools = []modules.TypedTool{
+	modules.NewTool("list_spaces", "List all Confluence spaces accessible to the current user.", listSpaces),
+	modules.NewTool("get_space", "Get details of a specific Confluence space by ID or key.", getSpace),
+	modules.NewTool("get_pages", "List pages in a Confluence space.", getPages),
+	modules.NewTool("get_page", "Get a Confluence page by ID with its content.", getPage),
+	modules.NewTool("create_page", "Create a new Confluence page.", createPage),
+	modules.NewTool("update_page", "Update an existing Confluence page.", updatePage),
+	modules.NewTool("delete_page", "Delete a Confluence page.", deletePage),
+	modules.NewTool("search", "Search Confluence content using CQL (Confluence Query Language). Example: 'type=page AND space=MYSPACE AND text~\"keyword\"'", search),
+	modules.NewTool("get_page_comments", "Get comments on a Confluence page.", getPageComments),
+	modules.NewTool("add_page_comment", "Add a comment to a Confluence page.", addPageComment),
+	modules.NewTool("get_page_labels", "Get labels on a Confluence page.", getPageLabels),
+	modules.NewTool("add_page_label", "Add a label to a Confluence page.", addPageLabel),
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "get_page_labels",
-		Description: "Get labels on a Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-			},
-			Required: []string{"page_id"},
-		},
+		URITemplate: "confluence://page/{id}",
+		Name:        "page",
+		Description: "A Confluence page with its body in storage format",
+		MimeType:    "application/json",
 	},
+}
+
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"page": readPageResource,
+}
+
+var prompts = []modules.Prompt{
 	{
-		Name:        "add_page_label",
-		Description: "Add a label to a Confluence page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "Page ID",
-				},
-				"label": {
-					Type:        "string",
-					Description: "Label name",
-				},
-			},
-			Required: []string{"page_id", "label"},
+		Name:        "summarize_space",
+		Description: "Summarize a Confluence space from its recently updated pages",
+		Arguments: []modules.PromptArgument{
+			{Name: "key", Description: "Space key (e.g. MYSPACE)", Required: true},
 		},
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"list_spaces":       listSpaces,
-	"get_space":         getSpace,
-	"get_pages":         getPages,
-	"get_page":          getPage,
-	"create_page":       createPage,
-	"update_page":       updatePage,
-	"delete_page":       deletePage,
-	"search":            search,
-	"get_page_comments": getPageComments,
-	"add_page_comment":  addPageComment,
-	"get_page_labels":   getPageLabels,
-	"add_page_label":    addPageLabel,
+var promptHandlers = map[string]modules.PromptHandler{
+	"summarize_space": summarizeSpacePrompt,
+}
+
+var completers = map[string]modules.Completer{
+	"key": completeSpaceKey,
 }
 
 // =============================================================================
-// Spaces
+// Params
 // =============================================================================
 
-func listSpaces(params map[string]interface{}) (string, error) {
+// cursorParams pages through the v2 API; it is embedded in its list tools
+type cursorParams struct {
+	Limit  int    `json:"limit,omitempty" jsonschema:"default=25,minimum=1,maximum=250" description:"Maximum results to return. Default: 25"`
+	Cursor string `json:"cursor,omitempty" description:"Pagination cursor for next page"`
+}
+
+type listSpacesParams struct {
+	cursorParams
+}
+
+type getSpaceParams struct {
+	SpaceIDOrKey string `json:"space_id_or_key" jsonschema:"required" description:"Space ID (numeric) or key (e.g., 'MYSPACE')"`
+}
+
+type getPagesParams struct {
+	SpaceID string `json:"space_id" jsonschema:"required" description:"Space ID (numeric). Use get_space to get ID from key."`
+	cursorParams
+}
+
+// pageParams identifies a page
+type pageParams struct {
+	PageID string `json:"page_id" jsonschema:"required" description:"Page ID"`
+}
+
+type getPageParams struct {
+	pageParams
+	BodyFormat string `json:"body_format,omitempty" jsonschema:"enum=storage|atlas_doc_format,default=storage" description:"Body format: storage (XHTML) or atlas_doc_format. Default: storage"`
+}
+
+type createPageParams struct {
+	SpaceID  string `json:"space_id" jsonschema:"required" description:"Space ID (numeric)"`
+	Title    string `json:"title" jsonschema:"required" description:"Page title"`
+	Body     string `json:"body" jsonschema:"required" description:"Page body in storage format (XHTML)"`
+	ParentID string `json:"parent_id,omitempty" description:"Parent page ID for nested pages"`
+}
+
+type updatePageParams struct {
+	pageParams
+	Title   string `json:"title" jsonschema:"required" description:"New page title"`
+	Body    string `json:"body" jsonschema:"required" description:"New page body in storage format (XHTML)"`
+	Version int    `json:"version" jsonschema:"required,minimum=1" description:"Current version number (must be incremented)"`
+}
+
+type searchParams struct {
+	CQL   string `json:"cql" jsonschema:"required" description:"CQL query string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"default=25,minimum=1,maximum=250" description:"Maximum results to return. Default: 25"`
+	Start int    `json:"start,omitempty" jsonschema:"default=0,minimum=0" description:"Starting index for pagination. Default: 0"`
+}
+
+type getPageCommentsParams struct {
+	pageParams
+	cursorParams
+}
+
+type addPageCommentParams struct {
+	pageParams
+	Body string `json:"body" jsonschema:"required" description:"Comment body in storage format (XHTML)"`
+}
+
+type addPageLabelParams struct {
+	pageParams
+	Label string `json:"label" jsonschema:"required" description:"Label name"`
+}
+
+// query returns the limit and cursor as query parameters
+func (p cursorParams) query() url.Values {
 	query := url.Values{}
 
 	limit := 25
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
+	if p.Limit > 0 {
+		limit = p.Limit
 	}
 	query.Set("limit", fmt.Sprintf("%d", limit))
 
-	if cursor, ok := params["cursor"].(string); ok && cursor != "" {
-		query.Set("cursor", cursor)
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
 	}
+	return query
+}
 
-	endpoint := fmt.Sprintf("%s/spaces?%s", baseURLV2(), query.Encode())
+// =============================================================================
+// Spaces
+// =============================================================================
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+func listSpaces(ctx context.Context, params listSpacesParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/spaces?%s", baseURLV2(), params.query().Encode())
+
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -335,25 +216,20 @@ func listSpaces(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getSpace(params map[string]interface{}) (string, error) {
-	spaceIDOrKey, ok := params["space_id_or_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("space_id_or_key must be a string")
-	}
-
+func getSpace(ctx context.Context, params getSpaceParams) (string, error) {
 	// Check if it's numeric (space ID) or a key
 	numericRegex := regexp.MustCompile(`^\d+$`)
 	var endpoint string
 
-	if numericRegex.MatchString(spaceIDOrKey) {
+	if numericRegex.MatchString(params.SpaceIDOrKey) {
 		// Use V2 API for numeric ID
-		endpoint = fmt.Sprintf("%s/spaces/%s", baseURLV2(), spaceIDOrKey)
+		endpoint = fmt.Sprintf("%s/spaces/%s", baseURLV2(), params.SpaceIDOrKey)
 	} else {
 		// Use V1 API for key
-		endpoint = fmt.Sprintf("%s/space/%s", baseURLV1(), spaceIDOrKey)
+		endpoint = fmt.Sprintf("%s/space/%s", baseURLV1(), params.SpaceIDOrKey)
 	}
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -365,27 +241,10 @@ func getSpace(params map[string]interface{}) (string, error) {
 // Pages
 // =============================================================================
 
-func getPages(params map[string]interface{}) (string, error) {
-	spaceID, ok := params["space_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("space_id must be a string")
-	}
+func getPages(ctx context.Context, params getPagesParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/spaces/%s/pages?%s", baseURLV2(), params.SpaceID, params.query().Encode())
 
-	query := url.Values{}
-
-	limit := 25
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
-	}
-	query.Set("limit", fmt.Sprintf("%d", limit))
-
-	if cursor, ok := params["cursor"].(string); ok && cursor != "" {
-		query.Set("cursor", cursor)
-	}
-
-	endpoint := fmt.Sprintf("%s/spaces/%s/pages?%s", baseURLV2(), spaceID, query.Encode())
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -393,20 +252,15 @@ func getPages(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getPage(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
+func getPage(ctx context.Context, params getPageParams) (string, error) {
 	bodyFormat := "storage"
-	if bf, ok := params["body_format"].(string); ok && bf != "" {
-		bodyFormat = bf
+	if params.BodyFormat != "" {
+		bodyFormat = params.BodyFormat
 	}
 
-	endpoint := fmt.Sprintf("%s/pages/%s?body-format=%s", baseURLV2(), pageID, bodyFormat)
+	endpoint := fmt.Sprintf("%s/pages/%s?body-format=%s", baseURLV2(), params.PageID, bodyFormat)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -414,39 +268,24 @@ func getPage(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func createPage(params map[string]interface{}) (string, error) {
-	spaceID, ok := params["space_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("space_id must be a string")
-	}
-
-	title, ok := params["title"].(string)
-	if !ok {
-		return "", fmt.Errorf("title must be a string")
-	}
-
-	body, ok := params["body"].(string)
-	if !ok {
-		return "", fmt.Errorf("body must be a string")
-	}
-
+func createPage(ctx context.Context, params createPageParams) (string, error) {
 	payload := map[string]interface{}{
-		"spaceId": spaceID,
-		"title":   title,
+		"spaceId": params.SpaceID,
+		"title":   params.Title,
 		"status":  "current",
 		"body": map[string]interface{}{
 			"representation": "storage",
-			"value":          body,
+			"value":          params.Body,
 		},
 	}
 
-	if parentID, ok := params["parent_id"].(string); ok && parentID != "" {
-		payload["parentId"] = parentID
+	if params.ParentID != "" {
+		payload["parentId"] = params.ParentID
 	}
 
 	endpoint := baseURLV2() + "/pages"
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
@@ -454,63 +293,40 @@ func createPage(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func updatePage(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	title, ok := params["title"].(string)
-	if !ok {
-		return "", fmt.Errorf("title must be a string")
-	}
-
-	body, ok := params["body"].(string)
-	if !ok {
-		return "", fmt.Errorf("body must be a string")
-	}
-
-	version, ok := params["version"].(float64)
-	if !ok {
-		return "", fmt.Errorf("version must be a number")
-	}
-
+func updatePage(ctx context.Context, params updatePageParams) (string, error) {
 	payload := map[string]interface{}{
-		"id":     pageID,
-		"title":  title,
+		"id":     params.PageID,
+		"title":  params.Title,
 		"status": "current",
 		"body": map[string]interface{}{
 			"representation": "storage",
-			"value":          body,
+			"value":          params.Body,
 		},
 		"version": map[string]interface{}{
-			"number": int(version),
+			"number": params.Version,
 		},
 	}
 
-	endpoint := fmt.Sprintf("%s/pages/%s", baseURLV2(), pageID)
+	endpoint := fmt.Sprintf("%s/pages/%s", baseURLV2(), params.PageID)
 
-	respBody, err := client.DoJSON("PUT", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "PUT", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
 
+	modules.NotifyResourceUpdated(ctx, "confluence://page/"+params.PageID)
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func deletePage(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/pages/%s", baseURLV2(), pageID)
+func deletePage(ctx context.Context, params pageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s", baseURLV2(), params.PageID)
 
-	_, err := client.DoJSON("DELETE", endpoint, headers(), nil)
+	_, err := client.DoJSON(ctx, "DELETE", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
 
+	modules.NotifyResourceUpdated(ctx, "confluence://page/"+params.PageID)
 	return `{"deleted": true}`, nil
 }
 
@@ -518,30 +334,20 @@ func deletePage(params map[string]interface{}) (string, error) {
 // Search (CQL) - uses V1 API
 // =============================================================================
 
-func search(params map[string]interface{}) (string, error) {
-	cql, ok := params["cql"].(string)
-	if !ok {
-		return "", fmt.Errorf("cql must be a string")
-	}
-
+func search(ctx context.Context, params searchParams) (string, error) {
 	query := url.Values{}
-	query.Set("cql", cql)
+	query.Set("cql", params.CQL)
 
 	limit := 25
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
+	if params.Limit > 0 {
+		limit = params.Limit
 	}
 	query.Set("limit", fmt.Sprintf("%d", limit))
-
-	start := 0
-	if s, ok := params["start"].(float64); ok {
-		start = int(s)
-	}
-	query.Set("start", fmt.Sprintf("%d", start))
+	query.Set("start", fmt.Sprintf("%d", params.Start))
 
 	endpoint := fmt.Sprintf("%s/search?%s", baseURLV1(), query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -553,27 +359,10 @@ func search(params map[string]interface{}) (string, error) {
 // Comments
 // =============================================================================
 
-func getPageComments(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	query := url.Values{}
-
-	limit := 25
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
-	}
-	query.Set("limit", fmt.Sprintf("%d", limit))
-
-	if cursor, ok := params["cursor"].(string); ok && cursor != "" {
-		query.Set("cursor", cursor)
-	}
+func getPageComments(ctx context.Context, params getPageCommentsParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s/footer-comments?%s", baseURLV2(), params.PageID, params.query().Encode())
 
-	endpoint := fmt.Sprintf("%s/pages/%s/footer-comments?%s", baseURLV2(), pageID, query.Encode())
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -581,27 +370,17 @@ func getPageComments(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addPageComment(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	body, ok := params["body"].(string)
-	if !ok {
-		return "", fmt.Errorf("body must be a string")
-	}
-
+func addPageComment(ctx context.Context, params addPageCommentParams) (string, error) {
 	payload := map[string]interface{}{
 		"body": map[string]interface{}{
 			"representation": "storage",
-			"value":          body,
+			"value":          params.Body,
 		},
 	}
 
-	endpoint := fmt.Sprintf("%s/pages/%s/footer-comments", baseURLV2(), pageID)
+	endpoint := fmt.Sprintf("%s/pages/%s/footer-comments", baseURLV2(), params.PageID)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
@@ -613,15 +392,10 @@ func addPageComment(params map[string]interface{}) (string, error) {
 // Labels
 // =============================================================================
 
-func getPageLabels(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/pages/%s/labels", baseURLV2(), pageID)
+func getPageLabels(ctx context.Context, params pageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s/labels", baseURLV2(), params.PageID)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -629,27 +403,86 @@ func getPageLabels(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addPageLabel(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
+func addPageLabel(ctx context.Context, params addPageLabelParams) (string, error) {
+	payload := map[string]interface{}{
+		"name": params.Label,
 	}
 
-	label, ok := params["label"].(string)
-	if !ok {
-		return "", fmt.Errorf("label must be a string")
+	endpoint := fmt.Sprintf("%s/pages/%s/labels", baseURLV2(), params.PageID)
+
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
+	if err != nil {
+		return "", err
 	}
 
-	payload := map[string]interface{}{
-		"name": label,
+	return httpclient.PrettyJSON(respBody), nil
+}
+
+// =============================================================================
+// Resources
+// =============================================================================
+
+func readPageResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	text, err := getPage(ctx, getPageParams{pageParams: pageParams{PageID: vars["id"]}})
+	if err != nil {
+		return nil, err
 	}
+	return []modules.ResourceContents{{
+		URI:      "confluence://page/" + vars["id"],
+		MimeType: "application/json",
+		Text:     text,
+	}}, nil
+}
 
-	endpoint := fmt.Sprintf("%s/pages/%s/labels", baseURLV2(), pageID)
+// =============================================================================
+// Prompts
+// =============================================================================
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+func summarizeSpacePrompt(ctx context.Context, args map[string]string) (*modules.PromptResult, error) {
+	space, err := getSpace(ctx, getSpaceParams{SpaceIDOrKey: args["key"]})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	pages, err := search(ctx, searchParams{
+		CQL:   fmt.Sprintf("space = %q AND type = page ORDER BY lastmodified DESC", args["key"]),
+		Limit: 25,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &modules.PromptResult{
+		Description: "Summarize space " + args["key"],
+		Messages: []modules.PromptMessage{
+			modules.UserMessage("Space:\n" + space),
+			modules.UserMessage("Recently updated pages:\n" + pages),
+			modules.UserMessage(`Summarize this Confluence space.
+1. Describe its purpose and main topics.
+2. Highlight what changed recently.
+3. Point out pages that look outdated or duplicated.
+Read individual pages with confluence://page/{id} when more detail is needed.`),
+		},
+	}, nil
+}
+
+func completeSpaceKey(ctx context.Context, args map[string]string) ([]string, error) {
+	text, err := listSpaces(ctx, listSpacesParams{cursorParams{Limit: 250}})
+	if err != nil {
+		return nil, err
 	}
 
-	return httpclient.PrettyJSON(respBody), nil
+	var result struct {
+		Results []struct {
+			Key string `json:"key"`
+		} `json:"results"`
+	}
+	if err := httpclient.UnmarshalJSON([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse spaces: %w", err)
+	}
+
+	keys := make([]string, 0, len(result.Results))
+	for _, space := range result.Results {
+		keys = append(keys, space.Key)
+	}
+	return keys, nil
 }
@@ -0,0 +1,39 @@
+package modules
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// MarshalJSON always emits "text" for text blocks, even when it is empty,
+// since the field is required for them.
+func (c ContentBlock) MarshalJSON() ([]byte, error) {
+	type block ContentBlock
+	if c.Type == "text" {
+		return json.Marshal(struct {
+			block
+			Text string `json:"text"`
+		}{block(c), c.Text})
+	}
+	return json.Marshal(block(c))
+}
+
+// TextContent returns a text content block
+func TextContent(text string) ContentBlock {
+	return ContentBlock{Type: "text", Text: text}
+}
+
+// ImageContent returns an image content block holding data base64-encoded
+func ImageContent(data []byte, mimeType string) ContentBlock {
+	return ContentBlock{Type: "image", Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// EmbeddedResource returns a content block embedding the resource contents
+func EmbeddedResource(contents ResourceContents) ContentBlock {
+	return ContentBlock{Type: "resource", Resource: &contents}
+}
+
+// ResourceLink returns a content block pointing at a resource the client can read later
+func ResourceLink(uri, name, description, mimeType string) ContentBlock {
+	return ContentBlock{Type: "resource_link", URI: uri, Name: name, Description: description, MimeType: mimeType}
+}
@@ -0,0 +1,192 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var compactors = map[string]modules.Compactor{
+	"get_pr":   compactPR,
+	"list_prs": compactPRList,
+}
+
+type ghUser struct {
+	Login string `json:"login"`
+}
+
+type pullRequest struct {
+	Number    int     `json:"number"`
+	Title     string  `json:"title"`
+	State     string  `json:"state"`
+	Draft     bool    `json:"draft"`
+	Merged    bool    `json:"merged"`
+	Mergeable *bool   `json:"mergeable"`
+	User      *ghUser `json:"user"`
+	Body      string  `json:"body"`
+	HTMLURL   string  `json:"html_url"`
+	Head      struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	RequestedReviewers []ghUser `json:"requested_reviewers"`
+	Additions          int      `json:"additions"`
+	Deletions          int      `json:"deletions"`
+	ChangedFiles       int      `json:"changed_files"`
+	Commits            int      `json:"commits"`
+	UpdatedAt          string   `json:"updated_at"`
+}
+
+func (pr pullRequest) stateText() string {
+	switch {
+	case pr.Merged:
+		return "merged"
+	case pr.Draft && pr.State == "open":
+		return "draft"
+	}
+	return pr.State
+}
+
+func (pr pullRequest) author() string {
+	if pr.User == nil {
+		return "-"
+	}
+	return pr.User.Login
+}
+
+// compactPR renders a pull request with the state of its head commit's checks
+func compactPR(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var pr pullRequest
+	if err := json.Unmarshal([]byte(raw), &pr); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d %s [%s]\n", pr.Number, pr.Title, pr.stateText())
+	fmt.Fprintf(&b, "%s -> %s by %s, updated %s\n", pr.Head.Ref, pr.Base.Ref, pr.author(), shortDate(pr.UpdatedAt))
+	fmt.Fprintf(&b, "%d commits, %d files, +%d -%d", pr.Commits, pr.ChangedFiles, pr.Additions, pr.Deletions)
+	if pr.Mergeable != nil {
+		fmt.Fprintf(&b, ", mergeable: %t", *pr.Mergeable)
+	}
+	b.WriteString("\n")
+	if len(pr.Labels) > 0 {
+		labels := make([]string, len(pr.Labels))
+		for i, label := range pr.Labels {
+			labels[i] = label.Name
+		}
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(labels, ", "))
+	}
+	if len(pr.RequestedReviewers) > 0 {
+		reviewers := make([]string, len(pr.RequestedReviewers))
+		for i, reviewer := range pr.RequestedReviewers {
+			reviewers[i] = reviewer.Login
+		}
+		fmt.Fprintf(&b, "Review requested: %s\n", strings.Join(reviewers, ", "))
+	}
+
+	owner, _ := params["owner"].(string)
+	repo, _ := params["repo"].(string)
+	if pr.Head.SHA != "" {
+		fmt.Fprintf(&b, "Checks: %s\n", checksSummary(ctx, owner, repo, pr.Head.SHA))
+	}
+
+	if body := strings.TrimSpace(pr.Body); body != "" {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	fmt.Fprintf(&b, "\n%s", pr.HTMLURL)
+	return b.String(), nil
+}
+
+// checksSummary counts the check runs of a commit by outcome and names the
+// ones that did not pass
+func checksSummary(ctx context.Context, owner, repo, sha string) string {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs?per_page=100", githubAPIBase, owner, repo, sha)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
+	if err != nil {
+		return fmt.Sprintf("unavailable (%v)", err)
+	}
+
+	var result struct {
+		TotalCount int `json:"total_count"`
+		CheckRuns  []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Sprintf("unavailable (%v)", err)
+	}
+	if result.TotalCount == 0 {
+		return "none"
+	}
+
+	counts := map[string]int{}
+	var notPassed []string
+	for _, run := range result.CheckRuns {
+		outcome := run.Conclusion
+		if run.Status != "completed" {
+			outcome = "pending"
+		}
+		counts[outcome]++
+		switch outcome {
+		case "success", "skipped", "neutral":
+		default:
+			notPassed = append(notPassed, fmt.Sprintf("%s (%s)", run.Name, outcome))
+		}
+	}
+
+	outcomes := make([]string, 0, len(counts))
+	for outcome := range counts {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Strings(outcomes)
+	parts := make([]string, len(outcomes))
+	for i, outcome := range outcomes {
+		parts[i] = fmt.Sprintf("%d %s", counts[outcome], outcome)
+	}
+	summary := strings.Join(parts, ", ")
+	if len(notPassed) > 0 {
+		summary += "; " + strings.Join(notPassed, ", ")
+	}
+	return summary
+}
+
+// compactPRList renders pull requests as a table
+func compactPRList(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var prs []pullRequest
+	if err := json.Unmarshal([]byte(raw), &prs); err != nil {
+		return "", err
+	}
+	if len(prs) == 0 {
+		return "No pull requests", nil
+	}
+
+	rows := make([][]string, len(prs))
+	for i, pr := range prs {
+		rows[i] = []string{
+			fmt.Sprintf("#%d", pr.Number), pr.stateText(), pr.Title, pr.author(),
+			pr.Head.Ref + " -> " + pr.Base.Ref, shortDate(pr.UpdatedAt),
+		}
+	}
+	return strings.TrimSuffix(modules.MarkdownTable(
+		[]string{"PR", "State", "Title", "Author", "Branch", "Updated"}, rows), "\n"), nil
+}
+
+// shortDate cuts an ISO 8601 timestamp to its date
+func shortDate(ts string) string {
+	if len(ts) < 10 {
+		return ts
+	}
+	return ts[:10]
+}
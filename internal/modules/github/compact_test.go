@@ -0,0 +1,34 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// checkSummary runs compact on raw and checks that the summary has every
+// want and is shorter than the payload
+func checkSummary(t *testing.T, compact modules.Compactor, raw string, want ...string) {
+	t.Helper()
+	got, err := compact(context.Background(), nil, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in:\n%s", w, got)
+		}
+	}
+	if len(got) >= len(raw) {
+		t.Error("summary is not shorter than the payload")
+	}
+}
+
+func TestCompactPRList(t *testing.T) {
+	raw := `[{"number":5,"title":"Fix","state":"open","draft":true,"user":{"login":"ann"},"head":{"ref":"fix"},"base":{"ref":"main"},"updated_at":"2026-01-02T03:04:05Z"}]`
+	checkSummary(t, compactPRList, raw,
+		"| #5 | draft | Fix | ann | fix -> main | 2026-01-02 |",
+	)
+}
@@ -1,22 +1,38 @@
 package github
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"mime"
 	"net/url"
 	"os"
+	"path"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
 	"github.com/shibaleo/go-mcp-dev/internal/modules"
 )
 
 const (
-	githubAPIBase   = "https://api.github.com"
+	githubAPIBase    = "https://api.github.com"
 	githubAPIVersion = "2022-11-28"
 )
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("github"), httpclient.WithCache(httpclient.DefaultCachePolicy))
+
+// rateLimit stays clear of GitHub's secondary limits; the hourly quota is
+// followed through the X-RateLimit headers of each response
+var rateLimit = httpclient.RateLimit{Rate: 10, Burst: 20}
+
+// Listings are paged through the Link header; search wraps its items in an
+// object
+var (
+	arrayPages  = httpclient.LinkHeader("")
+	searchPages = httpclient.LinkHeader("items")
+)
 
 func getToken() string {
 	return os.Getenv("GITHUB_TOKEN")
@@ -33,653 +49,276 @@ func headers() map[string]string {
 // Module returns the GitHub module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "github",
-		Description: "GitHub API - リポジトリ、Issue、PR、Actions、検索",
-		APIVersion:  githubAPIVersion,
-		TestedAt:    "2026-01-10",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "github",
+		Description:       "GitHub API - リポジトリ、Issue、PR、Actions、検索",
+		APIVersion:        githubAPIVersion,
+		TestedAt:          "2026-01-10",
+		Compactors:        compactors,
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		Prompts:           prompts,
+		PromptHandlers:    promptHandlers,
+		Completers:        completers,
+	}.WithTools(tools...).WithPagination(
+		"list_repos", "list_branches", "list_commits", "list_issues", "list_prs",
+		"list_pr_commits", "list_pr_files", "list_pr_reviews",
+		"search_repos", "search_code", "search_issues",
+		"list_workflows", "list_workflow_runs",
+	)
 }
 
-var tools = []modules.Tool{
+var tools = []modules.TypedTool{
 	// User
-	{
-		Name:        "get_user",
-		Description: "Get information about the authenticated GitHub user.",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
-	},
+	modules.NewTool("get_user", "Get information about the authenticated GitHub user.", getUser),
 	// Repositories
-	{
-		Name:        "list_repos",
-		Description: "List repositories for the authenticated user.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"type": {
-					Type:        "string",
-					Description: "Type of repositories (all, owner, public, private). Default: owner",
-				},
-				"sort": {
-					Type:        "string",
-					Description: "Sort by (created, updated, pushed, full_name). Default: updated",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-		},
-	},
-	{
-		Name:        "get_repo",
-		Description: "Get details of a specific repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
-	},
-	{
-		Name:        "list_branches",
-		Description: "List branches in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
-	},
-	{
-		Name:        "list_commits",
-		Description: "List commits in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"sha": {
-					Type:        "string",
-					Description: "Branch name or commit SHA to filter by",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
-	},
-	{
-		Name:        "get_file_content",
-		Description: "Get the content of a file in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"path": {
-					Type:        "string",
-					Description: "File path",
-				},
-				"ref": {
-					Type:        "string",
-					Description: "Branch name or commit SHA",
-				},
-			},
-			Required: []string{"owner", "repo", "path"},
-		},
-	},
+	modules.NewTool("list_repos", "List repositories for the authenticated user.", listRepos),
+	modules.NewTool("get_repo", "Get details of a specific repository.", getRepo),
+	modules.NewTool("list_branches", "List branches in a repository.", listBranches),
+	modules.NewTool("list_commits", "List commits in a repository.", listCommits),
+	modules.NewTool("get_file_content", "Get the content of a file in a repository.", getFileContent),
 	// Issues
-	{
-		Name:        "list_issues",
-		Description: "List issues in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"state": {
-					Type:        "string",
-					Description: "Issue state (open, closed, all). Default: open",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
-	},
-	{
-		Name:        "get_issue",
-		Description: "Get details of a specific issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"issue_number": {
-					Type:        "number",
-					Description: "Issue number",
-				},
-			},
-			Required: []string{"owner", "repo", "issue_number"},
-		},
-	},
-	{
-		Name:        "create_issue",
-		Description: "Create a new issue in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"title": {
-					Type:        "string",
-					Description: "Issue title",
-				},
-				"body": {
-					Type:        "string",
-					Description: "Issue body",
-				},
-				"labels": {
-					Type:        "array",
-					Description: "Labels to assign",
-				},
-				"assignees": {
-					Type:        "array",
-					Description: "Users to assign",
-				},
-			},
-			Required: []string{"owner", "repo", "title"},
-		},
-	},
-	{
-		Name:        "update_issue",
-		Description: "Update an existing issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"issue_number": {
-					Type:        "number",
-					Description: "Issue number",
-				},
-				"title": {
-					Type:        "string",
-					Description: "New title",
-				},
-				"body": {
-					Type:        "string",
-					Description: "New body",
-				},
-				"state": {
-					Type:        "string",
-					Description: "New state (open, closed)",
-				},
-				"labels": {
-					Type:        "array",
-					Description: "Labels to set",
-				},
-				"assignees": {
-					Type:        "array",
-					Description: "Users to assign",
-				},
-			},
-			Required: []string{"owner", "repo", "issue_number"},
-		},
-	},
-	{
-		Name:        "add_issue_comment",
-		Description: "Add a comment to an issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"issue_number": {
-					Type:        "number",
-					Description: "Issue number",
-				},
-				"body": {
-					Type:        "string",
-					Description: "Comment body",
-				},
-			},
-			Required: []string{"owner", "repo", "issue_number", "body"},
-		},
-	},
+	modules.NewTool("list_issues", "List issues in a repository.", listIssues),
+	modules.NewTool("get_issue", "Get details of a specific issue.", getIssue),
+	modules.NewTool("create_issue", "Create a new issue in a repository.", createIssue),
+	modules.NewTool("update_issue", "Update an existing issue.", updateIssue),
+	modules.NewTool("add_issue_comment", "Add a comment to an issue.", addIssueComment),
 	// Pull Requests
-	{
-		Name:        "list_prs",
-		Description: "List pull requests in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"state": {
-					Type:        "string",
-					Description: "PR state (open, closed, all). Default: open",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
-	},
-	{
-		Name:        "get_pr",
-		Description: "Get details of a specific pull request.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"pr_number": {
-					Type:        "number",
-					Description: "PR number",
-				},
-			},
-			Required: []string{"owner", "repo", "pr_number"},
-		},
-	},
-	{
-		Name:        "create_pr",
-		Description: "Create a new pull request.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"title": {
-					Type:        "string",
-					Description: "PR title",
-				},
-				"head": {
-					Type:        "string",
-					Description: "Branch with changes",
-				},
-				"base": {
-					Type:        "string",
-					Description: "Branch to merge into",
-				},
-				"body": {
-					Type:        "string",
-					Description: "PR description",
-				},
-				"draft": {
-					Type:        "boolean",
-					Description: "Create as draft PR",
-				},
-			},
-			Required: []string{"owner", "repo", "title", "head", "base"},
-		},
-	},
-	{
-		Name:        "list_pr_commits",
-		Description: "List commits in a pull request.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"pr_number": {
-					Type:        "number",
-					Description: "PR number",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-			},
-			Required: []string{"owner", "repo", "pr_number"},
-		},
-	},
-	{
-		Name:        "list_pr_files",
-		Description: "List files changed in a pull request.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"pr_number": {
-					Type:        "number",
-					Description: "PR number",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-			},
-			Required: []string{"owner", "repo", "pr_number"},
-		},
-	},
-	{
-		Name:        "list_pr_reviews",
-		Description: "List reviews on a pull request.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"pr_number": {
-					Type:        "number",
-					Description: "PR number",
-				},
-			},
-			Required: []string{"owner", "repo", "pr_number"},
-		},
-	},
+	modules.NewTool("list_prs", "List pull requests in a repository.", listPRs),
+	withOutputSchema(modules.NewTool("get_pr", "Get details of a specific pull request.", getPR), prOutputSchema),
+	modules.NewTool("create_pr", "Create a new pull request.", createPR),
+	modules.NewTool("list_pr_commits", "List commits in a pull request.", listPRCommits),
+	modules.NewTool("list_pr_files", "List files changed in a pull request.", listPRFiles),
+	modules.NewTool("list_pr_reviews", "List reviews on a pull request.", listPRReviews),
 	// Search
-	{
-		Name:        "search_repos",
-		Description: "Search for repositories.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"query": {
-					Type:        "string",
-					Description: "Search query",
-				},
-				"sort": {
-					Type:        "string",
-					Description: "Sort by (stars, forks, help-wanted-issues, updated)",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"query"},
-		},
-	},
-	{
-		Name:        "search_code",
-		Description: "Search for code across repositories.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"query": {
-					Type:        "string",
-					Description: "Search query (e.g., 'addClass in:file language:js repo:jquery/jquery')",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"query"},
-		},
-	},
-	{
-		Name:        "search_issues",
-		Description: "Search for issues and pull requests.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"query": {
-					Type:        "string",
-					Description: "Search query (e.g., 'repo:owner/repo is:open is:issue')",
-				},
-				"sort": {
-					Type:        "string",
-					Description: "Sort by (comments, reactions, created, updated)",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-				"page": {
-					Type:        "number",
-					Description: "Page number. Default: 1",
-				},
-			},
-			Required: []string{"query"},
-		},
-	},
+	modules.NewTool("search_repos", "Search for repositories.", searchRepos),
+	modules.NewTool("search_code", "Search for code across repositories.", searchCode),
+	modules.NewTool("search_issues", "Search for issues and pull requests.", searchIssues),
 	// Actions
-	{
-		Name:        "list_workflows",
-		Description: "List workflows in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
+	modules.NewTool("list_workflows", "List workflows in a repository.", listWorkflows),
+	modules.NewTool("list_workflow_runs", "List workflow runs in a repository.", listWorkflowRuns),
+	modules.NewTool("get_workflow_run", "Get details of a specific workflow run.", getWorkflowRun),
+}
+
+// prOutputSchema describes the fields of get_pr's structuredContent, which
+// the registry parses from the returned pull request
+var prOutputSchema = &modules.InputSchema{
+	Type: "object",
+	Properties: map[string]modules.Property{
+		"number":   {Type: "number", Description: "PR number"},
+		"title":    {Type: "string", Description: "PR title"},
+		"state":    {Type: "string", Description: "open or closed"},
+		"body":     {Type: "string", Description: "PR description"},
+		"html_url": {Type: "string", Description: "URL of the PR on github.com"},
+		"user":     {Type: "object", Description: "Author"},
+		"head":     {Type: "object", Description: "Source branch (ref, sha, repo)"},
+		"base":     {Type: "object", Description: "Target branch (ref, sha, repo)"},
+		"draft":    {Type: "boolean", Description: "Whether the PR is a draft"},
+		"merged":   {Type: "boolean", Description: "Whether the PR has been merged"},
 	},
+	Required: []string{"number", "title", "state", "html_url"},
+}
+
+func withOutputSchema(t modules.TypedTool, schema *modules.InputSchema) modules.TypedTool {
+	t.Tool.OutputSchema = schema
+	return t
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "list_workflow_runs",
-		Description: "List workflow runs in a repository.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"workflow_id": {
-					Type:        "string",
-					Description: "Workflow ID or file name to filter by",
-				},
-				"status": {
-					Type:        "string",
-					Description: "Filter by status (queued, in_progress, completed)",
-				},
-				"per_page": {
-					Type:        "number",
-					Description: "Results per page. Default: 30",
-				},
-			},
-			Required: []string{"owner", "repo"},
-		},
+		URITemplate: "github://{owner}/{repo}/blob/{ref}/{path}",
+		Name:        "file",
+		Description: "A file in a repository at a branch, tag or commit SHA. Write a / in the ref as %2F",
 	},
+}
+
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"file": readFileResource,
+}
+
+var prompts = []modules.Prompt{
 	{
-		Name:        "get_workflow_run",
-		Description: "Get details of a specific workflow run.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"owner": {
-					Type:        "string",
-					Description: "Repository owner",
-				},
-				"repo": {
-					Type:        "string",
-					Description: "Repository name",
-				},
-				"run_id": {
-					Type:        "number",
-					Description: "Workflow run ID",
-				},
-			},
-			Required: []string{"owner", "repo", "run_id"},
+		Name:        "review_pr",
+		Description: "Review a GitHub pull request: correctness, risks and suggested changes",
+		Arguments: []modules.PromptArgument{
+			{Name: "owner", Description: "Repository owner", Required: true},
+			{Name: "repo", Description: "Repository name", Required: true},
+			{Name: "number", Description: "PR number", Required: true},
 		},
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"get_user":           getUser,
-	"list_repos":         listRepos,
-	"get_repo":           getRepo,
-	"list_branches":      listBranches,
-	"list_commits":       listCommits,
-	"get_file_content":   getFileContent,
-	"list_issues":        listIssues,
-	"get_issue":          getIssue,
-	"create_issue":       createIssue,
-	"update_issue":       updateIssue,
-	"add_issue_comment":  addIssueComment,
-	"list_prs":           listPRs,
-	"get_pr":             getPR,
-	"create_pr":          createPR,
-	"list_pr_commits":    listPRCommits,
-	"list_pr_files":      listPRFiles,
-	"list_pr_reviews":    listPRReviews,
-	"search_repos":       searchRepos,
-	"search_code":        searchCode,
-	"search_issues":      searchIssues,
-	"list_workflows":     listWorkflows,
-	"list_workflow_runs": listWorkflowRuns,
-	"get_workflow_run":   getWorkflowRun,
+var promptHandlers = map[string]modules.PromptHandler{
+	"review_pr": reviewPRPrompt,
+}
+
+var completers = map[string]modules.Completer{
+	"owner": completeOwner,
+	"repo":  completeRepo,
+}
+
+// =============================================================================
+// Params
+// =============================================================================
+
+type noParams struct{}
+
+// repoParams identifies a repository
+type repoParams struct {
+	Owner string `json:"owner" jsonschema:"required" description:"Repository owner"`
+	Repo  string `json:"repo" jsonschema:"required" description:"Repository name"`
+}
+
+// perPageParams sizes the pages of listings that only the paginator pages
+// through
+type perPageParams struct {
+	PerPage int `json:"per_page,omitempty" jsonschema:"default=30,minimum=1,maximum=100" description:"Results per page. Default: 30"`
+}
+
+// pageParams also lets the caller pick the page
+type pageParams struct {
+	perPageParams
+	Page int `json:"page,omitempty" jsonschema:"default=1,minimum=1" description:"Page number. Default: 1"`
+}
+
+type listReposParams struct {
+	Type string `json:"type,omitempty" jsonschema:"enum=all|owner|public|private|member,default=owner" description:"Type of repositories (all, owner, public, private). Default: owner"`
+	Sort string `json:"sort,omitempty" jsonschema:"enum=created|updated|pushed|full_name,default=updated" description:"Sort by (created, updated, pushed, full_name). Default: updated"`
+	pageParams
+}
+
+type repoPageParams struct {
+	repoParams
+	perPageParams
+}
+
+type listCommitsParams struct {
+	repoParams
+	SHA string `json:"sha,omitempty" description:"Branch name or commit SHA to filter by"`
+	pageParams
+}
+
+type getFileContentParams struct {
+	repoParams
+	Path string `json:"path" jsonschema:"required" description:"File path"`
+	Ref  string `json:"ref,omitempty" description:"Branch name or commit SHA"`
+}
+
+type listIssuesParams struct {
+	repoParams
+	State string `json:"state,omitempty" jsonschema:"enum=open|closed|all,default=open" description:"Issue state (open, closed, all). Default: open"`
+	pageParams
+}
+
+// issueParams identifies an issue
+type issueParams struct {
+	repoParams
+	IssueNumber int `json:"issue_number" jsonschema:"required,minimum=1" description:"Issue number"`
+}
+
+type createIssueParams struct {
+	repoParams
+	Title     string   `json:"title" jsonschema:"required" description:"Issue title"`
+	Body      string   `json:"body,omitempty" description:"Issue body"`
+	Labels    []string `json:"labels,omitempty" description:"Labels to assign"`
+	Assignees []string `json:"assignees,omitempty" description:"Users to assign"`
+}
+
+// updateIssueParams sets the fields that are present, even when empty
+type updateIssueParams struct {
+	issueParams
+	Title     *string  `json:"title,omitempty" description:"New title"`
+	Body      *string  `json:"body,omitempty" description:"New body"`
+	State     *string  `json:"state,omitempty" jsonschema:"enum=open|closed" description:"New state (open, closed)"`
+	Labels    []string `json:"labels,omitempty" description:"Labels to set"`
+	Assignees []string `json:"assignees,omitempty" description:"Users to assign"`
+}
+
+type addIssueCommentParams struct {
+	issueParams
+	Body string `json:"body" jsonschema:"required" description:"Comment body"`
+}
+
+type listPRsParams struct {
+	repoParams
+	State string `json:"state,omitempty" jsonschema:"enum=open|closed|all,default=open" description:"PR state (open, closed, all). Default: open"`
+	pageParams
+}
+
+// prParams identifies a pull request
+type prParams struct {
+	repoParams
+	PRNumber int `json:"pr_number" jsonschema:"required,minimum=1" description:"PR number"`
+}
+
+type createPRParams struct {
+	repoParams
+	Title string `json:"title" jsonschema:"required" description:"PR title"`
+	Head  string `json:"head" jsonschema:"required" description:"Branch with changes"`
+	Base  string `json:"base" jsonschema:"required" description:"Branch to merge into"`
+	Body  string `json:"body,omitempty" description:"PR description"`
+	Draft *bool  `json:"draft,omitempty" description:"Create as draft PR"`
+}
+
+type prPageParams struct {
+	prParams
+	perPageParams
+}
+
+type searchReposParams struct {
+	Query string `json:"query" jsonschema:"required" description:"Search query"`
+	Sort  string `json:"sort,omitempty" jsonschema:"enum=stars|forks|help-wanted-issues|updated" description:"Sort by (stars, forks, help-wanted-issues, updated)"`
+	pageParams
+}
+
+type searchCodeParams struct {
+	Query string `json:"query" jsonschema:"required" description:"Search query (e.g., 'addClass in:file language:js repo:jquery/jquery')"`
+	pageParams
+}
+
+type searchIssuesParams struct {
+	Query string `json:"query" jsonschema:"required" description:"Search query (e.g., 'repo:owner/repo is:open is:issue')"`
+	Sort  string `json:"sort,omitempty" jsonschema:"enum=comments|reactions|created|updated" description:"Sort by (comments, reactions, created, updated)"`
+	pageParams
+}
+
+type listWorkflowRunsParams struct {
+	repoParams
+	WorkflowID string `json:"workflow_id,omitempty" description:"Workflow ID or file name to filter by"`
+	Status     string `json:"status,omitempty" jsonschema:"enum=queued|in_progress|completed" description:"Filter by status (queued, in_progress, completed)"`
+	perPageParams
+}
+
+// runParams identifies a workflow run
+type runParams struct {
+	repoParams
+	RunID int `json:"run_id" jsonschema:"required,minimum=1" description:"Workflow run ID"`
+}
+
+// perPage returns PerPage, or GitHub's page size of 30 when it is unset
+func (p perPageParams) perPage() int {
+	if p.PerPage > 0 {
+		return p.PerPage
+	}
+	return 30
+}
+
+// page returns Page, or the first page when it is unset
+func (p pageParams) page() int {
+	if p.Page > 0 {
+		return p.Page
+	}
+	return 1
 }
 
 // =============================================================================
 // User
 // =============================================================================
 
-func getUser(params map[string]interface{}) (string, error) {
+func getUser(ctx context.Context, params noParams) (string, error) {
 	endpoint := githubAPIBase + "/user"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -691,36 +330,27 @@ func getUser(params map[string]interface{}) (string, error) {
 // Repositories
 // =============================================================================
 
-func listRepos(params map[string]interface{}) (string, error) {
+func listRepos(ctx context.Context, params listReposParams) (string, error) {
 	query := url.Values{}
 
-	if t, ok := params["type"].(string); ok && t != "" {
-		query.Set("type", t)
+	if params.Type != "" {
+		query.Set("type", params.Type)
 	} else {
 		query.Set("type", "owner")
 	}
 
-	if sort, ok := params["sort"].(string); ok && sort != "" {
-		query.Set("sort", sort)
+	if params.Sort != "" {
+		query.Set("sort", params.Sort)
 	} else {
 		query.Set("sort", "updated")
 	}
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-	query.Set("per_page", fmt.Sprintf("%d", perPage))
-
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	query.Set("page", fmt.Sprintf("%d", page))
+	query.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	query.Set("page", fmt.Sprintf("%d", params.page()))
 
 	endpoint := fmt.Sprintf("%s/user/repos?%s", githubAPIBase, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -728,20 +358,10 @@ func listRepos(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getRepo(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
+func getRepo(ctx context.Context, params repoParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s", githubAPIBase, params.Owner, params.Repo)
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s", githubAPIBase, owner, repo)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(httpclient.Cacheable(ctx), "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -749,25 +369,10 @@ func getRepo(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listBranches(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
+func listBranches(ctx context.Context, params repoPageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/branches?per_page=%d", githubAPIBase, params.Owner, params.Repo, params.perPage())
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/branches?per_page=%d", githubAPIBase, owner, repo, perPage)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -775,38 +380,19 @@ func listBranches(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listCommits(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
+func listCommits(ctx context.Context, params listCommitsParams) (string, error) {
 	query := url.Values{}
 
-	if sha, ok := params["sha"].(string); ok && sha != "" {
-		query.Set("sha", sha)
-	}
-
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
+	if params.SHA != "" {
+		query.Set("sha", params.SHA)
 	}
-	query.Set("per_page", fmt.Sprintf("%d", perPage))
 
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	query.Set("page", fmt.Sprintf("%d", page))
+	query.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	query.Set("page", fmt.Sprintf("%d", params.page()))
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/commits?%s", githubAPIBase, owner, repo, query.Encode())
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/commits?%s", githubAPIBase, params.Owner, params.Repo, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -814,89 +400,94 @@ func listCommits(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getFileContent(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
+func getFileContent(ctx context.Context, params getFileContentParams) (*modules.ToolCallResult, error) {
+	file, err := fetchFile(ctx, params.Owner, params.Repo, params.Path, params.Ref)
+	if err != nil {
+		return nil, err
 	}
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
+	if file.meta == nil {
+		return textResult(httpclient.PrettyJSON(file.raw)), nil
 	}
 
-	path, ok := params["path"].(string)
-	if !ok {
-		return "", fmt.Errorf("path must be a string")
+	if file.data != nil {
+		// Images are returned as image blocks the client can render
+		if mimeType := mime.TypeByExtension(path.Ext(params.Path)); strings.HasPrefix(mimeType, "image/") {
+			delete(file.meta, "content")
+			delete(file.meta, "encoding")
+			return &modules.ToolCallResult{
+				Content: []modules.ContentBlock{
+					modules.ImageContent(file.data, mimeType),
+					modules.TextContent(httpclient.PrettyJSONFromInterface(file.meta)),
+				},
+			}, nil
+		}
+		file.meta["content"] = string(file.data)
+		file.meta["encoding"] = "utf-8"
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/contents/%s", githubAPIBase, owner, repo, path)
+	return textResult(httpclient.PrettyJSONFromInterface(file.meta)), nil
+}
+
+// fileContent is a response of the repository contents API
+type fileContent struct {
+	raw  []byte                 // response body as returned by the API
+	meta map[string]interface{} // parsed response; nil for directory listings
+	data []byte                 // decoded file content; nil unless base64 encoded
+}
+
+func fetchFile(ctx context.Context, owner, repo, filePath, ref string) (*fileContent, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/contents/%s", githubAPIBase, owner, repo, filePath)
 
-	if ref, ok := params["ref"].(string); ok && ref != "" {
+	if ref != "" {
 		endpoint += "?ref=" + url.QueryEscape(ref)
 	}
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	file := &fileContent{raw: respBody}
+	if err := httpclient.UnmarshalJSON(respBody, &file.meta); err != nil {
+		return file, nil
 	}
 
 	// Try to decode base64 content
-	var result map[string]interface{}
-	if err := httpclient.UnmarshalJSON(respBody, &result); err == nil {
-		if content, ok := result["content"].(string); ok {
-			if encoding, ok := result["encoding"].(string); ok && encoding == "base64" {
-				decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
-				if err == nil {
-					result["content"] = string(decoded)
-					result["encoding"] = "utf-8"
-				}
+	if content, ok := file.meta["content"].(string); ok {
+		if encoding, ok := file.meta["encoding"].(string); ok && encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
+			if err == nil {
+				file.data = decoded
 			}
 		}
-		return httpclient.PrettyJSONFromInterface(result), nil
 	}
+	return file, nil
+}
 
-	return httpclient.PrettyJSON(respBody), nil
+func textResult(text string) *modules.ToolCallResult {
+	return &modules.ToolCallResult{Content: []modules.ContentBlock{modules.TextContent(text)}}
 }
 
 // =============================================================================
 // Issues
 // =============================================================================
 
-func listIssues(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
+func listIssues(ctx context.Context, params listIssuesParams) (string, error) {
 	query := url.Values{}
 
-	if state, ok := params["state"].(string); ok && state != "" {
-		query.Set("state", state)
+	if params.State != "" {
+		query.Set("state", params.State)
 	} else {
 		query.Set("state", "open")
 	}
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-	query.Set("per_page", fmt.Sprintf("%d", perPage))
-
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	query.Set("page", fmt.Sprintf("%d", page))
+	query.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	query.Set("page", fmt.Sprintf("%d", params.page()))
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues?%s", githubAPIBase, owner, repo, query.Encode())
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues?%s", githubAPIBase, params.Owner, params.Repo, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -904,25 +495,10 @@ func listIssues(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getIssue(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
+func getIssue(ctx context.Context, params issueParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d", githubAPIBase, params.Owner, params.Repo, params.IssueNumber)
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	issueNumber, ok := params["issue_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("issue_number must be a number")
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d", githubAPIBase, owner, repo, int(issueNumber))
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -930,41 +506,26 @@ func getIssue(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func createIssue(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	title, ok := params["title"].(string)
-	if !ok {
-		return "", fmt.Errorf("title must be a string")
-	}
-
+func createIssue(ctx context.Context, params createIssueParams) (string, error) {
 	body := map[string]interface{}{
-		"title": title,
+		"title": params.Title,
 	}
 
-	if b, ok := params["body"].(string); ok {
-		body["body"] = b
+	if params.Body != "" {
+		body["body"] = params.Body
 	}
 
-	if labels, ok := params["labels"].([]interface{}); ok {
-		body["labels"] = labels
+	if params.Labels != nil {
+		body["labels"] = params.Labels
 	}
 
-	if assignees, ok := params["assignees"].([]interface{}); ok {
-		body["assignees"] = assignees
+	if params.Assignees != nil {
+		body["assignees"] = params.Assignees
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues", githubAPIBase, owner, repo)
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues", githubAPIBase, params.Owner, params.Repo)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -972,47 +533,32 @@ func createIssue(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func updateIssue(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	issueNumber, ok := params["issue_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("issue_number must be a number")
-	}
-
+func updateIssue(ctx context.Context, params updateIssueParams) (string, error) {
 	body := make(map[string]interface{})
 
-	if title, ok := params["title"].(string); ok {
-		body["title"] = title
+	if params.Title != nil {
+		body["title"] = *params.Title
 	}
 
-	if b, ok := params["body"].(string); ok {
-		body["body"] = b
+	if params.Body != nil {
+		body["body"] = *params.Body
 	}
 
-	if state, ok := params["state"].(string); ok {
-		body["state"] = state
+	if params.State != nil {
+		body["state"] = *params.State
 	}
 
-	if labels, ok := params["labels"].([]interface{}); ok {
-		body["labels"] = labels
+	if params.Labels != nil {
+		body["labels"] = params.Labels
 	}
 
-	if assignees, ok := params["assignees"].([]interface{}); ok {
-		body["assignees"] = assignees
+	if params.Assignees != nil {
+		body["assignees"] = params.Assignees
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d", githubAPIBase, owner, repo, int(issueNumber))
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d", githubAPIBase, params.Owner, params.Repo, params.IssueNumber)
 
-	respBody, err := client.DoJSON("PATCH", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "PATCH", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -1020,30 +566,10 @@ func updateIssue(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addIssueComment(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	issueNumber, ok := params["issue_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("issue_number must be a number")
-	}
-
-	body, ok := params["body"].(string)
-	if !ok {
-		return "", fmt.Errorf("body must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", githubAPIBase, owner, repo, int(issueNumber))
+func addIssueComment(ctx context.Context, params addIssueCommentParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", githubAPIBase, params.Owner, params.Repo, params.IssueNumber)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), map[string]string{"body": body})
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), map[string]string{"body": params.Body})
 	if err != nil {
 		return "", err
 	}
@@ -1055,40 +581,21 @@ func addIssueComment(params map[string]interface{}) (string, error) {
 // Pull Requests
 // =============================================================================
 
-func listPRs(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
+func listPRs(ctx context.Context, params listPRsParams) (string, error) {
 	query := url.Values{}
 
-	if state, ok := params["state"].(string); ok && state != "" {
-		query.Set("state", state)
+	if params.State != "" {
+		query.Set("state", params.State)
 	} else {
 		query.Set("state", "open")
 	}
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-	query.Set("per_page", fmt.Sprintf("%d", perPage))
-
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	query.Set("page", fmt.Sprintf("%d", page))
+	query.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	query.Set("page", fmt.Sprintf("%d", params.page()))
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls?%s", githubAPIBase, owner, repo, query.Encode())
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls?%s", githubAPIBase, params.Owner, params.Repo, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -1096,25 +603,10 @@ func listPRs(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getPR(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	prNumber, ok := params["pr_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("pr_number must be a number")
-	}
+func getPR(ctx context.Context, params prParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", githubAPIBase, params.Owner, params.Repo, params.PRNumber)
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", githubAPIBase, owner, repo, int(prNumber))
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -1122,49 +614,24 @@ func getPR(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func createPR(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	title, ok := params["title"].(string)
-	if !ok {
-		return "", fmt.Errorf("title must be a string")
-	}
-
-	head, ok := params["head"].(string)
-	if !ok {
-		return "", fmt.Errorf("head must be a string")
-	}
-
-	base, ok := params["base"].(string)
-	if !ok {
-		return "", fmt.Errorf("base must be a string")
-	}
-
+func createPR(ctx context.Context, params createPRParams) (string, error) {
 	body := map[string]interface{}{
-		"title": title,
-		"head":  head,
-		"base":  base,
+		"title": params.Title,
+		"head":  params.Head,
+		"base":  params.Base,
 	}
 
-	if b, ok := params["body"].(string); ok {
-		body["body"] = b
+	if params.Body != "" {
+		body["body"] = params.Body
 	}
 
-	if draft, ok := params["draft"].(bool); ok {
-		body["draft"] = draft
+	if params.Draft != nil {
+		body["draft"] = *params.Draft
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPIBase, owner, repo)
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPIBase, params.Owner, params.Repo)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -1172,30 +639,10 @@ func createPR(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listPRCommits(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
+func listPRCommits(ctx context.Context, params prPageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/commits?per_page=%d", githubAPIBase, params.Owner, params.Repo, params.PRNumber, params.perPage())
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	prNumber, ok := params["pr_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("pr_number must be a number")
-	}
-
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/commits?per_page=%d", githubAPIBase, owner, repo, int(prNumber), perPage)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -1203,30 +650,10 @@ func listPRCommits(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listPRFiles(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	prNumber, ok := params["pr_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("pr_number must be a number")
-	}
-
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=%d", githubAPIBase, owner, repo, int(prNumber), perPage)
+func listPRFiles(ctx context.Context, params prPageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=%d", githubAPIBase, params.Owner, params.Repo, params.PRNumber, params.perPage())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -1234,25 +661,10 @@ func listPRFiles(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listPRReviews(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
-
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
-	}
-
-	prNumber, ok := params["pr_number"].(float64)
-	if !ok {
-		return "", fmt.Errorf("pr_number must be a number")
-	}
+func listPRReviews(ctx context.Context, params prParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", githubAPIBase, params.Owner, params.Repo, params.PRNumber)
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", githubAPIBase, owner, repo, int(prNumber))
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), arrayPages)
 	if err != nil {
 		return "", err
 	}
@@ -1264,34 +676,20 @@ func listPRReviews(params map[string]interface{}) (string, error) {
 // Search
 // =============================================================================
 
-func searchRepos(params map[string]interface{}) (string, error) {
-	query, ok := params["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query must be a string")
-	}
-
+func searchRepos(ctx context.Context, params searchReposParams) (string, error) {
 	q := url.Values{}
-	q.Set("q", query)
+	q.Set("q", params.Query)
 
-	if sort, ok := params["sort"].(string); ok && sort != "" {
-		q.Set("sort", sort)
+	if params.Sort != "" {
+		q.Set("sort", params.Sort)
 	}
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-	q.Set("per_page", fmt.Sprintf("%d", perPage))
-
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	q.Set("page", fmt.Sprintf("%d", params.page()))
 
 	endpoint := fmt.Sprintf("%s/search/repositories?%s", githubAPIBase, q.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), searchPages)
 	if err != nil {
 		return "", err
 	}
@@ -1299,30 +697,15 @@ func searchRepos(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func searchCode(params map[string]interface{}) (string, error) {
-	query, ok := params["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query must be a string")
-	}
-
+func searchCode(ctx context.Context, params searchCodeParams) (string, error) {
 	q := url.Values{}
-	q.Set("q", query)
-
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
-	}
-	q.Set("per_page", fmt.Sprintf("%d", perPage))
-
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("q", params.Query)
+	q.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	q.Set("page", fmt.Sprintf("%d", params.page()))
 
 	endpoint := fmt.Sprintf("%s/search/code?%s", githubAPIBase, q.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), searchPages)
 	if err != nil {
 		return "", err
 	}
@@ -1330,34 +713,20 @@ func searchCode(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func searchIssues(params map[string]interface{}) (string, error) {
-	query, ok := params["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query must be a string")
-	}
-
+func searchIssues(ctx context.Context, params searchIssuesParams) (string, error) {
 	q := url.Values{}
-	q.Set("q", query)
-
-	if sort, ok := params["sort"].(string); ok && sort != "" {
-		q.Set("sort", sort)
-	}
+	q.Set("q", params.Query)
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
+	if params.Sort != "" {
+		q.Set("sort", params.Sort)
 	}
-	q.Set("per_page", fmt.Sprintf("%d", perPage))
 
-	page := 1
-	if p, ok := params["page"].(float64); ok {
-		page = int(p)
-	}
-	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("per_page", fmt.Sprintf("%d", params.perPage()))
+	q.Set("page", fmt.Sprintf("%d", params.page()))
 
 	endpoint := fmt.Sprintf("%s/search/issues?%s", githubAPIBase, q.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), searchPages)
 	if err != nil {
 		return "", err
 	}
@@ -1369,25 +738,34 @@ func searchIssues(params map[string]interface{}) (string, error) {
 // Actions
 // =============================================================================
 
-func listWorkflows(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
+func listWorkflows(ctx context.Context, params repoPageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/actions/workflows?per_page=%d", githubAPIBase, params.Owner, params.Repo, params.perPage())
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.LinkHeader("workflows"))
+	if err != nil {
+		return "", err
 	}
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
+	return httpclient.PrettyJSON(respBody), nil
+}
+
+func listWorkflowRuns(ctx context.Context, params listWorkflowRunsParams) (string, error) {
+	query := url.Values{}
+
+	if params.Status != "" {
+		query.Set("status", params.Status)
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/actions/workflows?per_page=%d", githubAPIBase, owner, repo, perPage)
+	query.Set("per_page", fmt.Sprintf("%d", params.perPage()))
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	var endpoint string
+	if params.WorkflowID != "" {
+		endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%s/runs?%s", githubAPIBase, params.Owner, params.Repo, params.WorkflowID, query.Encode())
+	} else {
+		endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/runs?%s", githubAPIBase, params.Owner, params.Repo, query.Encode())
+	}
+
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.LinkHeader("workflow_runs"))
 	if err != nil {
 		return "", err
 	}
@@ -1395,68 +773,138 @@ func listWorkflows(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listWorkflowRuns(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
-	}
+func getWorkflowRun(ctx context.Context, params runParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d", githubAPIBase, params.Owner, params.Repo, params.RunID)
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
+	if err != nil {
+		return "", err
 	}
 
-	query := url.Values{}
+	return httpclient.PrettyJSON(respBody), nil
+}
 
-	if status, ok := params["status"].(string); ok && status != "" {
-		query.Set("status", status)
-	}
+// =============================================================================
+// Resources
+// =============================================================================
 
-	perPage := 30
-	if pp, ok := params["per_page"].(float64); ok {
-		perPage = int(pp)
+func readFileResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	file, err := fetchFile(ctx, vars["owner"], vars["repo"], vars["path"], vars["ref"])
+	if err != nil {
+		return nil, err
+	}
+	if file.data == nil {
+		return nil, fmt.Errorf("not a file: %s", vars["path"])
 	}
-	query.Set("per_page", fmt.Sprintf("%d", perPage))
 
-	var endpoint string
-	if workflowID, ok := params["workflow_id"].(string); ok && workflowID != "" {
-		endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%s/runs?%s", githubAPIBase, owner, repo, workflowID, query.Encode())
-	} else if workflowID, ok := params["workflow_id"].(float64); ok {
-		endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%d/runs?%s", githubAPIBase, owner, repo, int(workflowID), query.Encode())
+	contents := modules.ResourceContents{
+		URI:      fmt.Sprintf("github://%s/%s/blob/%s/%s", vars["owner"], vars["repo"], url.PathEscape(vars["ref"]), vars["path"]),
+		MimeType: mime.TypeByExtension(path.Ext(vars["path"])),
+	}
+	if utf8.Valid(file.data) && !strings.HasPrefix(contents.MimeType, "image/") {
+		if contents.MimeType == "" {
+			contents.MimeType = "text/plain"
+		}
+		contents.Text = string(file.data)
 	} else {
-		endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/runs?%s", githubAPIBase, owner, repo, query.Encode())
+		if contents.MimeType == "" {
+			contents.MimeType = "application/octet-stream"
+		}
+		contents.Blob = base64.StdEncoding.EncodeToString(file.data)
 	}
+	return []modules.ResourceContents{contents}, nil
+}
+
+// =============================================================================
+// Prompts
+// =============================================================================
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+func reviewPRPrompt(ctx context.Context, args map[string]string) (*modules.PromptResult, error) {
+	number, err := strconv.Atoi(args["number"])
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("number must be a PR number: %s", args["number"])
 	}
+	params := prParams{repoParams{args["owner"], args["repo"]}, number}
 
-	return httpclient.PrettyJSON(respBody), nil
+	pr, err := getPR(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	files, err := listPRFiles(ctx, prPageParams{prParams: params})
+	if err != nil {
+		return nil, err
+	}
+
+	ref := fmt.Sprintf("%s/%s#%d", args["owner"], args["repo"], number)
+	return &modules.PromptResult{
+		Description: "Review " + ref,
+		Messages: []modules.PromptMessage{
+			modules.UserMessage("Pull request " + ref + ":\n" + pr),
+			modules.UserMessage("Changed files:\n" + files),
+			modules.UserMessage(`Review this pull request.
+1. Summarize what it changes and why.
+2. Point out bugs, edge cases and risky changes, citing file and line.
+3. Note missing tests or documentation.
+4. Conclude with approve, comment or request changes.`),
+		},
+	}, nil
 }
 
-func getWorkflowRun(params map[string]interface{}) (string, error) {
-	owner, ok := params["owner"].(string)
-	if !ok {
-		return "", fmt.Errorf("owner must be a string")
+// repos returns the repositories of the authenticated user as owner/name pairs
+func repos(ctx context.Context) ([][2]string, error) {
+	params := listReposParams{Type: "all"}
+	params.PerPage = 100
+	text, err := listRepos(httpclient.Cacheable(ctx), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	}
+	if err := httpclient.UnmarshalJSON([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse repositories: %w", err)
 	}
 
-	repo, ok := params["repo"].(string)
-	if !ok {
-		return "", fmt.Errorf("repo must be a string")
+	pairs := make([][2]string, 0, len(result))
+	for _, repo := range result {
+		pairs = append(pairs, [2]string{repo.Owner.Login, repo.Name})
 	}
+	return pairs, nil
+}
 
-	runID, ok := params["run_id"].(float64)
-	if !ok {
-		return "", fmt.Errorf("run_id must be a number")
+func completeOwner(ctx context.Context, args map[string]string) ([]string, error) {
+	pairs, err := repos(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d", githubAPIBase, owner, repo, int(runID))
+	seen := make(map[string]bool)
+	owners := make([]string, 0)
+	for _, pair := range pairs {
+		if !seen[pair[0]] {
+			seen[pair[0]] = true
+			owners = append(owners, pair[0])
+		}
+	}
+	return owners, nil
+}
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+// completeRepo suggests repository names, limited to the owner when given
+func completeRepo(ctx context.Context, args map[string]string) ([]string, error) {
+	pairs, err := repos(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return httpclient.PrettyJSON(respBody), nil
+	names := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		if owner := args["owner"]; owner == "" || strings.EqualFold(owner, pair[0]) {
+			names = append(names, pair[1])
+		}
+	}
+	return names, nil
 }
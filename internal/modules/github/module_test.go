@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// stubAPI sends the module's requests to handler instead of api.github.com
+func stubAPI(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	target, _ := url.Parse(server.URL)
+
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		return original.RoundTrip(req)
+	})
+	t.Cleanup(func() {
+		http.DefaultTransport = original
+		server.Close()
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestGetPR_CompactViewKeepsStructuredContent(t *testing.T) {
+	stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/octo/hello/pulls/7" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number":7,"title":"Fix it","state":"open","html_url":"https://github.com/octo/hello/pull/7","user":{"login":"ann"},"head":{"ref":"fix","sha":"abc1234"},"base":{"ref":"main"}}`))
+	})
+
+	registry := modules.NewRegistry()
+	if err := registry.Register(Module()); err != nil {
+		t.Fatal(err)
+	}
+	result, err := registry.CallModuleTool(context.Background(), "github", "get_pr", map[string]interface{}{
+		"owner": "octo", "repo": "hello", "pr_number": float64(7),
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("get_pr failed: %v %+v", err, result)
+	}
+
+	structured, ok := result.StructuredContent.(map[string]interface{})
+	if !ok || structured["title"] != "Fix it" || structured["html_url"] == nil {
+		t.Errorf("structuredContent = %+v", result.StructuredContent)
+	}
+	if text := result.Content[0].Text; len(text) == 0 || text[0] == '{' {
+		t.Errorf("expected the compact summary, got %q", text)
+	}
+}
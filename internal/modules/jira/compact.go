@@ -0,0 +1,252 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+var compactors = map[string]modules.Compactor{
+	"get_issue": compactIssue,
+	"search":    compactSearch,
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+type user struct {
+	DisplayName string `json:"displayName"`
+}
+
+type comment struct {
+	Author  *user           `json:"author"`
+	Created string          `json:"created"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Status      *named          `json:"status"`
+		Priority    *named          `json:"priority"`
+		IssueType   *named          `json:"issuetype"`
+		Assignee    *user           `json:"assignee"`
+		Reporter    *user           `json:"reporter"`
+		Labels      []string        `json:"labels"`
+		Created     string          `json:"created"`
+		Updated     string          `json:"updated"`
+		Description json.RawMessage `json:"description"`
+		Parent      *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		Comment *struct {
+			Total    int       `json:"total"`
+			Comments []comment `json:"comments"`
+		} `json:"comment"`
+	} `json:"fields"`
+}
+
+// compactIssue renders an issue as a short header, its description as plain
+// text and its comments
+func compactIssue(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var is issue
+	if err := json.Unmarshal([]byte(raw), &is); err != nil {
+		return "", err
+	}
+	f := is.Fields
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", is.Key, f.Summary)
+	fmt.Fprintf(&b, "Type: %s | Status: %s | Priority: %s\n", nameOf(f.IssueType), nameOf(f.Status), nameOf(f.Priority))
+	fmt.Fprintf(&b, "Assignee: %s | Reporter: %s\n", userName(f.Assignee), userName(f.Reporter))
+	fmt.Fprintf(&b, "Created: %s | Updated: %s\n", shortTime(f.Created), shortTime(f.Updated))
+	if f.Parent != nil {
+		fmt.Fprintf(&b, "Parent: %s\n", f.Parent.Key)
+	}
+	if len(f.Labels) > 0 {
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(f.Labels, ", "))
+	}
+
+	if desc := adfText(f.Description); desc != "" {
+		fmt.Fprintf(&b, "\nDescription:\n%s\n", desc)
+	}
+
+	if f.Comment != nil && len(f.Comment.Comments) > 0 {
+		fmt.Fprintf(&b, "\nComments (%d):\n", f.Comment.Total)
+		for _, c := range f.Comment.Comments {
+			fmt.Fprintf(&b, "- %s (%s): %s\n", userName(c.Author), shortTime(c.Created),
+				strings.ReplaceAll(adfText(c.Body), "\n", "\n  "))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// compactSearch renders search results as a table
+func compactSearch(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var result struct {
+		Issues        []issue `json:"issues"`
+		Total         *int    `json:"total"`
+		IsLast        *bool   `json:"isLast"`
+		NextPageToken string  `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return "", err
+	}
+
+	rows := make([][]string, len(result.Issues))
+	for i, is := range result.Issues {
+		f := is.Fields
+		rows[i] = []string{is.Key, nameOf(f.Status), nameOf(f.Priority), userName(f.Assignee), shortTime(f.Updated), f.Summary}
+	}
+
+	summary := fmt.Sprintf("%d issues", len(result.Issues))
+	if result.Total != nil {
+		summary = fmt.Sprintf("%d of %d issues", len(result.Issues), *result.Total)
+	}
+	if result.NextPageToken != "" {
+		summary += fmt.Sprintf(" (more available, next_page_token: %s)", result.NextPageToken)
+	} else if result.IsLast != nil && !*result.IsLast {
+		summary += " (more available)"
+	}
+	if len(rows) == 0 {
+		return summary, nil
+	}
+	return summary + "\n\n" + strings.TrimSuffix(modules.MarkdownTable(
+		[]string{"Key", "Status", "Priority", "Assignee", "Updated", "Summary"}, rows), "\n"), nil
+}
+
+func nameOf(n *named) string {
+	if n == nil || n.Name == "" {
+		return "-"
+	}
+	return n.Name
+}
+
+func userName(u *user) string {
+	if u == nil || u.DisplayName == "" {
+		return "Unassigned"
+	}
+	return u.DisplayName
+}
+
+// shortTime cuts "2026-01-10T12:34:56.000+0900" to "2026-01-10 12:34"
+func shortTime(ts string) string {
+	if len(ts) < 16 {
+		return ts
+	}
+	return strings.Replace(ts[:16], "T", " ", 1)
+}
+
+// adfNode is a node of the Atlassian Document Format
+type adfNode struct {
+	Type    string                 `json:"type"`
+	Text    string                 `json:"text"`
+	Attrs   map[string]interface{} `json:"attrs"`
+	Content []adfNode              `json:"content"`
+	Marks   []struct {
+		Type string `json:"type"`
+	} `json:"marks"`
+}
+
+// adfText converts an ADF document to Markdown-ish plain text. A plain
+// string, as older APIs return, is passed through.
+func adfText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var doc adfNode
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	writeADF(&b, doc, "")
+	return strings.TrimSpace(b.String())
+}
+
+func writeADF(b *strings.Builder, node adfNode, prefix string) {
+	switch node.Type {
+	case "text":
+		text := node.Text
+		for _, mark := range node.Marks {
+			if mark.Type == "code" {
+				text = "`" + text + "`"
+			}
+		}
+		b.WriteString(text)
+	case "hardBreak":
+		b.WriteString("\n" + prefix)
+	case "mention", "emoji", "status":
+		b.WriteString(adfAttr(node, "text", "shortName"))
+	case "inlineCard", "blockCard":
+		b.WriteString(adfAttr(node, "url"))
+	case "rule":
+		b.WriteString(prefix + "---\n\n")
+	case "paragraph":
+		b.WriteString(prefix)
+		writeADFChildren(b, node, prefix)
+		b.WriteString("\n\n")
+	case "heading":
+		level, _ := node.Attrs["level"].(float64)
+		b.WriteString(prefix + strings.Repeat("#", max(int(level), 1)) + " ")
+		writeADFChildren(b, node, prefix)
+		b.WriteString("\n\n")
+	case "bulletList", "orderedList":
+		for i, item := range node.Content {
+			marker := "- "
+			if node.Type == "orderedList" {
+				marker = fmt.Sprintf("%d. ", i+1)
+			}
+			var inner strings.Builder
+			writeADFChildren(&inner, item, "")
+			lines := strings.Split(strings.TrimSpace(inner.String()), "\n")
+			for j, line := range lines {
+				if j == 0 {
+					b.WriteString(prefix + marker + line + "\n")
+				} else if line != "" {
+					b.WriteString(prefix + "  " + line + "\n")
+				}
+			}
+		}
+		b.WriteString("\n")
+	case "codeBlock":
+		b.WriteString(prefix + "```\n")
+		writeADFChildren(b, node, prefix)
+		b.WriteString("\n" + prefix + "```\n\n")
+	case "blockquote", "panel":
+		writeADFChildren(b, node, prefix+"> ")
+	case "tableRow":
+		cells := make([]string, len(node.Content))
+		for i, cell := range node.Content {
+			var inner strings.Builder
+			writeADFChildren(&inner, cell, "")
+			cells[i] = strings.Join(strings.Fields(inner.String()), " ")
+		}
+		b.WriteString(prefix + "| " + strings.Join(cells, " | ") + " |\n")
+	default:
+		writeADFChildren(b, node, prefix)
+	}
+}
+
+func writeADFChildren(b *strings.Builder, node adfNode, prefix string) {
+	for _, child := range node.Content {
+		writeADF(b, child, prefix)
+	}
+}
+
+func adfAttr(node adfNode, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := node.Attrs[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package jira
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// checkSummary runs compact on raw and checks that the summary has every
+// want and is shorter than the payload
+func checkSummary(t *testing.T, compact modules.Compactor, raw string, want ...string) {
+	t.Helper()
+	got, err := compact(context.Background(), nil, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in:\n%s", w, got)
+		}
+	}
+	if len(got) >= len(raw) {
+		t.Error("summary is not shorter than the payload")
+	}
+}
+
+func TestCompactIssue(t *testing.T) {
+	raw := `{"key":"PROJ-7","self":"https://x/rest/api/3/issue/1","fields":{
+			"summary":"Login fails","status":{"name":"In Progress"},"priority":{"name":"High"},
+			"issuetype":{"name":"Bug"},"assignee":null,"reporter":{"displayName":"Bob"},
+			"created":"2026-01-10T12:34:56.000+0900","updated":"2026-01-11T08:00:00.000+0900",
+			"description":{"type":"doc","version":1,"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"Steps:"}]},
+				{"type":"orderedList","content":[
+					{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"open "},{"type":"text","text":"/login","marks":[{"type":"code"}]}]}]},
+					{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"submit"}]}]}]}]},
+			"comment":{"total":1,"comments":[{"author":{"displayName":"Ann"},"created":"2026-01-12T09:00:00.000+0900",
+				"body":{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Reproduced"}]}]}}]}}}`
+	checkSummary(t, compactIssue, raw,
+		"PROJ-7: Login fails\nType: Bug | Status: In Progress | Priority: High\nAssignee: Unassigned | Reporter: Bob",
+		"Description:\nSteps:\n\n1. open `/login`\n2. submit",
+		"Comments (1):\n- Ann (2026-01-12 09:00): Reproduced",
+	)
+}
+
+func TestCompactSearch(t *testing.T) {
+	raw := `{"issues":[{"key":"PROJ-1","fields":{"summary":"A | B","status":{"name":"Done"},"assignee":{"displayName":"Ann"},"updated":"2026-01-01T00:00:00.000+0000"}}],"isLast":false,"nextPageToken":"CAEaAggD"}`
+	checkSummary(t, compactSearch, raw,
+		"1 issues (more available, next_page_token: CAEaAggD)",
+		"| PROJ-1 | Done | - | Ann | 2026-01-01 00:00 | A \\| B |",
+	)
+}
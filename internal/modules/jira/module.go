@@ -1,6 +1,7 @@
 package jira
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/url"
@@ -15,7 +16,11 @@ const (
 	jiraAPIVersion = "3" // Jira Cloud REST API version
 )
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("jira"), httpclient.WithCache(httpclient.DefaultCachePolicy))
+
+// rateLimit stays clear of Atlassian Cloud's cost-based limits, which the
+// Retry-After of a 429 covers beyond that
+var rateLimit = httpclient.RateLimit{Rate: 10, Burst: 10}
 
 func getDomain() string {
 	return os.Getenv("JIRA_DOMAIN")
@@ -44,325 +49,174 @@ func baseURL() string {
 // Module returns the Jira module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "jira",
-		Description: "Jira API - Issue/Project操作（検索、作成、更新、コメント、ワークログ）",
-		APIVersion:  jiraAPIVersion,
-		TestedAt:    "2026-01-10",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "jira",
+		Description:       "Jira API - Issue/Project操作（検索、作成、更新、コメント、ワークログ）",
+		APIVersion:        jiraAPIVersion,
+		TestedAt:          "2026-01-10",
+		Compactors:        compactors,
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		ListResources:     listResources,
+		Prompts:           prompts,
+		PromptHandlers:    promptHandlers,
+		Completers:        completers,
+	}.WithTools(tools...).WithPagination("list_projects", "search", "get_comments", "get_worklogs")
 }
 
-var tools = []modules.Tool{
-	{
-		Name:        "get_myself",
-		Description: "Get information about the current Jira user (myself).",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
-	},
-	{
-		Name:        "list_projects",
-		Description: "List all Jira projects accessible to the current user.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"start_at": {
-					Type:        "number",
-					Description: "Starting index for pagination. Default: 0",
-				},
-				"max_results": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 50",
-				},
-			},
-		},
-	},
-	{
-		Name:        "get_project",
-		Description: "Get details of a specific Jira project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_key": {
-					Type:        "string",
-					Description: "Project key (e.g., 'PROJ') or ID",
-				},
-			},
-			Required: []string{"project_key"},
-		},
-	},
-	{
-		Name:        "search",
-		Description: "Search for Jira issues using JQL (Jira Query Language). Example JQL: 'project = PROJ AND status = \"In Progress\"'",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"jql": {
-					Type:        "string",
-					Description: "JQL query string. Example: 'project = PROJ AND status != Done ORDER BY created DESC'",
-				},
-				"start_at": {
-					Type:        "number",
-					Description: "Starting index for pagination. Default: 0",
-				},
-				"max_results": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 50",
-				},
-				"fields": {
-					Type:        "array",
-					Description: "Fields to return. Default: summary, status, priority, assignee, created, updated",
-				},
-			},
-			Required: []string{"jql"},
-		},
-	},
-	{
-		Name:        "get_issue",
-		Description: "Get details of a specific Jira issue by key or ID.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123') or ID",
-				},
-				"fields": {
-					Type:        "array",
-					Description: "Specific fields to return. If not specified, returns common fields.",
-				},
-			},
-			Required: []string{"issue_key"},
-		},
-	},
-	{
-		Name:        "create_issue",
-		Description: "Create a new Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_key": {
-					Type:        "string",
-					Description: "Project key (e.g., 'PROJ')",
-				},
-				"issue_type": {
-					Type:        "string",
-					Description: "Issue type (e.g., 'Task', 'Bug', 'Story', 'Epic')",
-				},
-				"summary": {
-					Type:        "string",
-					Description: "Issue summary/title",
-				},
-				"description": {
-					Type:        "string",
-					Description: "Issue description",
-				},
-				"assignee_account_id": {
-					Type:        "string",
-					Description: "Assignee's Atlassian account ID",
-				},
-				"priority": {
-					Type:        "string",
-					Description: "Priority name (e.g., 'High', 'Medium', 'Low')",
-				},
-				"labels": {
-					Type:        "array",
-					Description: "Labels to add to the issue",
-				},
-				"parent_key": {
-					Type:        "string",
-					Description: "Parent issue key for subtasks",
-				},
-			},
-			Required: []string{"project_key", "issue_type", "summary"},
-		},
-	},
-	{
-		Name:        "update_issue",
-		Description: "Update an existing Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"summary": {
-					Type:        "string",
-					Description: "New summary/title",
-				},
-				"description": {
-					Type:        "string",
-					Description: "New description",
-				},
-				"assignee_account_id": {
-					Type:        "string",
-					Description: "New assignee's Atlassian account ID",
-				},
-				"priority": {
-					Type:        "string",
-					Description: "New priority name",
-				},
-				"labels": {
-					Type:        "array",
-					Description: "New labels (replaces existing)",
-				},
-			},
-			Required: []string{"issue_key"},
-		},
-	},
-	{
-		Name:        "get_transitions",
-		Description: "Get available transitions for an issue. Use this to find valid transition IDs before changing issue status.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-			},
-			Required: []string{"issue_key"},
-		},
-	},
-	{
-		Name:        "transition_issue",
-		Description: "Transition an issue to a new status. Use get_transitions first to get valid transition IDs.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"transition_id": {
-					Type:        "string",
-					Description: "Transition ID (get from get_transitions)",
-				},
-				"comment": {
-					Type:        "string",
-					Description: "Optional comment to add with the transition",
-				},
-			},
-			Required: []string{"issue_key", "transition_id"},
-		},
-	},
+var tools = []modules.TypedTool{
+	modules.NewTool("get_myself", "Get information about the current Jira user (myself).", getMyself),
+	modules.NewTool("list_projects", "List all Jira projects accessible to the current user.", listProjects),
+	modules.NewTool("get_project", "Get details of a specific Jira project.", getProject),
+	modules.NewTool("search", "Search for Jira issues using JQL (Jira Query Language). Example JQL: 'project = PROJ AND status = \"In Progress\"'", search),
+	modules.NewTool("get_issue", "Get details of a specific Jira issue by key or ID.", getIssue),
+	modules.NewTool("create_issue", "Create a new Jira issue.", createIssue),
+	modules.NewTool("update_issue", "Update an existing Jira issue.", updateIssue),
+	modules.NewTool("get_transitions", "Get available transitions for an issue. Use this to find valid transition IDs before changing issue status.", getTransitions),
+	modules.NewTool("transition_issue", "Transition an issue to a new status. Use get_transitions first to get valid transition IDs.", transitionIssue),
+	modules.NewTool("get_comments", "Get comments on a Jira issue.", getComments),
+	modules.NewTool("add_comment", "Add a comment to a Jira issue.", addComment),
+	modules.NewTool("get_worklogs", "Get work logs for a Jira issue.", getWorklogs),
+	modules.NewTool("add_worklog", "Add a work log to a Jira issue.", addWorklog),
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "get_comments",
-		Description: "Get comments on a Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"start_at": {
-					Type:        "number",
-					Description: "Starting index for pagination. Default: 0",
-				},
-				"max_results": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 50",
-				},
-			},
-			Required: []string{"issue_key"},
-		},
+		URITemplate: "jira://issue/{key}",
+		Name:        "issue",
+		Description: "A Jira issue by key (e.g. PROJ-123)",
+		MimeType:    "application/json",
 	},
 	{
-		Name:        "add_comment",
-		Description: "Add a comment to a Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"body": {
-					Type:        "string",
-					Description: "Comment text",
-				},
-			},
-			Required: []string{"issue_key", "body"},
-		},
-	},
-	{
-		Name:        "get_worklogs",
-		Description: "Get work logs for a Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"start_at": {
-					Type:        "number",
-					Description: "Starting index for pagination. Default: 0",
-				},
-				"max_results": {
-					Type:        "number",
-					Description: "Maximum results to return. Default: 50",
-				},
-			},
-			Required: []string{"issue_key"},
-		},
+		URITemplate: "jira://project/{project}",
+		Name:        "project",
+		Description: "A Jira project by key",
+		MimeType:    "application/json",
 	},
+}
+
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"issue":   readIssueResource,
+	"project": readProjectResource,
+}
+
+var prompts = []modules.Prompt{
 	{
-		Name:        "add_worklog",
-		Description: "Add a work log to a Jira issue.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"issue_key": {
-					Type:        "string",
-					Description: "Issue key (e.g., 'PROJ-123')",
-				},
-				"time_spent_seconds": {
-					Type:        "number",
-					Description: "Time spent in seconds",
-				},
-				"started": {
-					Type:        "string",
-					Description: "Start time in ISO 8601 format (e.g., '2024-01-15T10:00:00.000+0900'). Defaults to now.",
-				},
-				"comment": {
-					Type:        "string",
-					Description: "Work log comment",
-				},
-			},
-			Required: []string{"issue_key", "time_spent_seconds"},
+		Name:        "triage_issue",
+		Description: "Triage a Jira issue: assess priority, missing information and next steps",
+		Arguments: []modules.PromptArgument{
+			{Name: "key", Description: "Issue key (e.g. PROJ-123)", Required: true},
 		},
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"get_myself":       getMyself,
-	"list_projects":    listProjects,
-	"get_project":      getProject,
-	"search":           search,
-	"get_issue":        getIssue,
-	"create_issue":     createIssue,
-	"update_issue":     updateIssue,
-	"get_transitions":  getTransitions,
-	"transition_issue": transitionIssue,
-	"get_comments":     getComments,
-	"add_comment":      addComment,
-	"get_worklogs":     getWorklogs,
-	"add_worklog":      addWorklog,
+var promptHandlers = map[string]modules.PromptHandler{
+	"triage_issue": triageIssuePrompt,
+}
+
+var completers = map[string]modules.Completer{
+	"key":     completeIssueKey,
+	"project": completeProjectKey,
+}
+
+// =============================================================================
+// Params
+// =============================================================================
+
+type noParams struct{}
+
+// pageParams pages through startAt-based listings; it is embedded in their
+// tools
+type pageParams struct {
+	StartAt    int `json:"start_at,omitempty" jsonschema:"default=0,minimum=0" description:"Starting index for pagination. Default: 0"`
+	MaxResults int `json:"max_results,omitempty" jsonschema:"default=50,minimum=1" description:"Maximum results to return. Default: 50"`
+}
+
+// issueParams identifies an issue
+type issueParams struct {
+	IssueKey string `json:"issue_key" jsonschema:"required" description:"Issue key (e.g., 'PROJ-123')"`
+}
+
+type listProjectsParams struct {
+	pageParams
+}
+
+type getProjectParams struct {
+	ProjectKey string `json:"project_key" jsonschema:"required" description:"Project key (e.g., 'PROJ') or ID"`
+}
+
+type searchParams struct {
+	JQL string `json:"jql" jsonschema:"required" description:"JQL query string. Example: 'project = PROJ AND status != Done ORDER BY created DESC'"`
+	pageParams
+	Fields        []string `json:"fields,omitempty" description:"Fields to return. Default: summary, status, priority, assignee, created, updated"`
+	NextPageToken string   `json:"next_page_token,omitempty" description:"Token of the next page, from a previous search"`
+}
+
+type getIssueParams struct {
+	IssueKey string   `json:"issue_key" jsonschema:"required" description:"Issue key (e.g., 'PROJ-123') or ID"`
+	Fields   []string `json:"fields,omitempty" description:"Specific fields to return. If not specified, returns common fields."`
+}
+
+type createIssueParams struct {
+	ProjectKey        string   `json:"project_key" jsonschema:"required" description:"Project key (e.g., 'PROJ')"`
+	IssueType         string   `json:"issue_type" jsonschema:"required" description:"Issue type (e.g., 'Task', 'Bug', 'Story', 'Epic')"`
+	Summary           string   `json:"summary" jsonschema:"required" description:"Issue summary/title"`
+	Description       string   `json:"description,omitempty" description:"Issue description"`
+	AssigneeAccountID string   `json:"assignee_account_id,omitempty" description:"Assignee's Atlassian account ID"`
+	Priority          string   `json:"priority,omitempty" description:"Priority name (e.g., 'High', 'Medium', 'Low')"`
+	Labels            []string `json:"labels,omitempty" description:"Labels to add to the issue"`
+	ParentKey         string   `json:"parent_key,omitempty" description:"Parent issue key for subtasks"`
+}
+
+// updateIssueParams sets the fields that are present, even when empty
+type updateIssueParams struct {
+	issueParams
+	Summary           *string  `json:"summary,omitempty" description:"New summary/title"`
+	Description       *string  `json:"description,omitempty" description:"New description"`
+	AssigneeAccountID *string  `json:"assignee_account_id,omitempty" description:"New assignee's Atlassian account ID"`
+	Priority          *string  `json:"priority,omitempty" description:"New priority name"`
+	Labels            []string `json:"labels,omitempty" description:"New labels (replaces existing)"`
+}
+
+type transitionIssueParams struct {
+	issueParams
+	TransitionID string `json:"transition_id" jsonschema:"required" description:"Transition ID (get from get_transitions)"`
+	Comment      string `json:"comment,omitempty" description:"Optional comment to add with the transition"`
+}
+
+type issuePageParams struct {
+	issueParams
+	pageParams
+}
+
+type addCommentParams struct {
+	issueParams
+	Body string `json:"body" jsonschema:"required" description:"Comment text"`
+}
+
+type addWorklogParams struct {
+	issueParams
+	TimeSpentSeconds int    `json:"time_spent_seconds" jsonschema:"required,minimum=60" description:"Time spent in seconds"`
+	Started          string `json:"started,omitempty" jsonschema:"format=date-time" description:"Start time in ISO 8601 format (e.g., '2024-01-15T10:00:00.000+0900'). Defaults to now."`
+	Comment          string `json:"comment,omitempty" description:"Work log comment"`
+}
+
+// maxResults returns MaxResults, or Jira's page size of 50 when it is unset
+func (p pageParams) maxResults() int {
+	if p.MaxResults > 0 {
+		return p.MaxResults
+	}
+	return 50
 }
 
 // =============================================================================
 // User
 // =============================================================================
 
-func getMyself(params map[string]interface{}) (string, error) {
+func getMyself(ctx context.Context, params noParams) (string, error) {
 	endpoint := baseURL() + "/myself"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -374,20 +228,10 @@ func getMyself(params map[string]interface{}) (string, error) {
 // Projects
 // =============================================================================
 
-func listProjects(params map[string]interface{}) (string, error) {
-	startAt := 0
-	if sa, ok := params["start_at"].(float64); ok {
-		startAt = int(sa)
-	}
+func listProjects(ctx context.Context, params listProjectsParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/project/search?startAt=%d&maxResults=%d", baseURL(), params.StartAt, params.maxResults())
 
-	maxResults := 50
-	if mr, ok := params["max_results"].(float64); ok {
-		maxResults = int(mr)
-	}
-
-	endpoint := fmt.Sprintf("%s/project/search?startAt=%d&maxResults=%d", baseURL(), startAt, maxResults)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(httpclient.Cacheable(ctx), httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.JiraPages("values"))
 	if err != nil {
 		return "", err
 	}
@@ -395,15 +239,10 @@ func listProjects(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getProject(params map[string]interface{}) (string, error) {
-	projectKey, ok := params["project_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_key must be a string")
-	}
+func getProject(ctx context.Context, params getProjectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/project/%s", baseURL(), params.ProjectKey)
 
-	endpoint := fmt.Sprintf("%s/project/%s", baseURL(), projectKey)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -415,44 +254,25 @@ func getProject(params map[string]interface{}) (string, error) {
 // Issues
 // =============================================================================
 
-func search(params map[string]interface{}) (string, error) {
-	jql, ok := params["jql"].(string)
-	if !ok {
-		return "", fmt.Errorf("jql must be a string")
-	}
-
+func search(ctx context.Context, params searchParams) (string, error) {
 	query := url.Values{}
-	query.Set("jql", jql)
+	query.Set("jql", params.JQL)
+	query.Set("startAt", fmt.Sprintf("%d", params.StartAt))
+	query.Set("maxResults", fmt.Sprintf("%d", params.maxResults()))
 
-	startAt := 0
-	if sa, ok := params["start_at"].(float64); ok {
-		startAt = int(sa)
+	if params.NextPageToken != "" {
+		query.Set("nextPageToken", params.NextPageToken)
 	}
-	query.Set("startAt", fmt.Sprintf("%d", startAt))
 
-	maxResults := 50
-	if mr, ok := params["max_results"].(float64); ok {
-		maxResults = int(mr)
-	}
-	query.Set("maxResults", fmt.Sprintf("%d", maxResults))
-
-	if fields, ok := params["fields"].([]interface{}); ok && len(fields) > 0 {
-		fieldStrs := make([]string, 0, len(fields))
-		for _, f := range fields {
-			if fs, ok := f.(string); ok {
-				fieldStrs = append(fieldStrs, fs)
-			}
-		}
-		if len(fieldStrs) > 0 {
-			query.Set("fields", joinStrings(fieldStrs, ","))
-		}
+	if len(params.Fields) > 0 {
+		query.Set("fields", joinStrings(params.Fields, ","))
 	} else {
 		query.Set("fields", "summary,status,priority,assignee,created,updated")
 	}
 
 	endpoint := fmt.Sprintf("%s/search/jql?%s", baseURL(), query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.JiraPages("issues"))
 	if err != nil {
 		return "", err
 	}
@@ -460,23 +280,10 @@ func search(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getIssue(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
+func getIssue(ctx context.Context, params getIssueParams) (string, error) {
 	query := url.Values{}
-	if fields, ok := params["fields"].([]interface{}); ok && len(fields) > 0 {
-		fieldStrs := make([]string, 0, len(fields))
-		for _, f := range fields {
-			if fs, ok := f.(string); ok {
-				fieldStrs = append(fieldStrs, fs)
-			}
-		}
-		if len(fieldStrs) > 0 {
-			query.Set("fields", joinStrings(fieldStrs, ","))
-		}
+	if len(params.Fields) > 0 {
+		query.Set("fields", joinStrings(params.Fields, ","))
 	}
 
 	queryStr := ""
@@ -484,9 +291,9 @@ func getIssue(params map[string]interface{}) (string, error) {
 		queryStr = "?" + query.Encode()
 	}
 
-	endpoint := fmt.Sprintf("%s/issue/%s%s", baseURL(), issueKey, queryStr)
+	endpoint := fmt.Sprintf("%s/issue/%s%s", baseURL(), params.IssueKey, queryStr)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -494,59 +301,38 @@ func getIssue(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func createIssue(params map[string]interface{}) (string, error) {
-	projectKey, ok := params["project_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_key must be a string")
-	}
-
-	issueType, ok := params["issue_type"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_type must be a string")
-	}
-
-	summary, ok := params["summary"].(string)
-	if !ok {
-		return "", fmt.Errorf("summary must be a string")
-	}
-
+func createIssue(ctx context.Context, params createIssueParams) (string, error) {
 	fields := map[string]interface{}{
-		"project":   map[string]string{"key": projectKey},
-		"issuetype": map[string]string{"name": issueType},
-		"summary":   summary,
+		"project":   map[string]string{"key": params.ProjectKey},
+		"issuetype": map[string]string{"name": params.IssueType},
+		"summary":   params.Summary,
 	}
 
-	if description, ok := params["description"].(string); ok && description != "" {
-		fields["description"] = adfDocument(description)
+	if params.Description != "" {
+		fields["description"] = adfDocument(params.Description)
 	}
 
-	if assigneeID, ok := params["assignee_account_id"].(string); ok && assigneeID != "" {
-		fields["assignee"] = map[string]string{"accountId": assigneeID}
+	if params.AssigneeAccountID != "" {
+		fields["assignee"] = map[string]string{"accountId": params.AssigneeAccountID}
 	}
 
-	if priority, ok := params["priority"].(string); ok && priority != "" {
-		fields["priority"] = map[string]string{"name": priority}
+	if params.Priority != "" {
+		fields["priority"] = map[string]string{"name": params.Priority}
 	}
 
-	if labels, ok := params["labels"].([]interface{}); ok && len(labels) > 0 {
-		labelStrs := make([]string, 0, len(labels))
-		for _, l := range labels {
-			if ls, ok := l.(string); ok {
-				labelStrs = append(labelStrs, ls)
-			}
-		}
-		fields["labels"] = labelStrs
+	if len(params.Labels) > 0 {
+		fields["labels"] = params.Labels
 	}
 
-	if parentKey, ok := params["parent_key"].(string); ok && parentKey != "" {
-		fields["parent"] = map[string]string{"key": parentKey}
+	if params.ParentKey != "" {
+		fields["parent"] = map[string]string{"key": params.ParentKey}
 	}
 
 	body := map[string]interface{}{"fields": fields}
 
 	endpoint := baseURL() + "/issue"
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -554,65 +340,50 @@ func createIssue(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func updateIssue(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
+func updateIssue(ctx context.Context, params updateIssueParams) (string, error) {
 	fields := make(map[string]interface{})
 
-	if summary, ok := params["summary"].(string); ok {
-		fields["summary"] = summary
+	if params.Summary != nil {
+		fields["summary"] = *params.Summary
 	}
 
-	if description, ok := params["description"].(string); ok {
-		fields["description"] = adfDocument(description)
+	if params.Description != nil {
+		fields["description"] = adfDocument(*params.Description)
 	}
 
-	if assigneeID, ok := params["assignee_account_id"].(string); ok {
-		fields["assignee"] = map[string]string{"accountId": assigneeID}
+	if params.AssigneeAccountID != nil {
+		fields["assignee"] = map[string]string{"accountId": *params.AssigneeAccountID}
 	}
 
-	if priority, ok := params["priority"].(string); ok {
-		fields["priority"] = map[string]string{"name": priority}
+	if params.Priority != nil {
+		fields["priority"] = map[string]string{"name": *params.Priority}
 	}
 
-	if labels, ok := params["labels"].([]interface{}); ok {
-		labelStrs := make([]string, 0, len(labels))
-		for _, l := range labels {
-			if ls, ok := l.(string); ok {
-				labelStrs = append(labelStrs, ls)
-			}
-		}
-		fields["labels"] = labelStrs
+	if params.Labels != nil {
+		fields["labels"] = params.Labels
 	}
 
 	body := map[string]interface{}{"fields": fields}
 
-	endpoint := fmt.Sprintf("%s/issue/%s", baseURL(), issueKey)
+	endpoint := fmt.Sprintf("%s/issue/%s", baseURL(), params.IssueKey)
 
-	_, err := client.DoJSON("PUT", endpoint, headers(), body)
+	_, err := client.DoJSON(ctx, "PUT", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf(`{"updated": true, "issue_key": "%s"}`, issueKey), nil
+	modules.NotifyResourceUpdated(ctx, "jira://issue/"+params.IssueKey)
+	return fmt.Sprintf(`{"updated": true, "issue_key": "%s"}`, params.IssueKey), nil
 }
 
 // =============================================================================
 // Transitions
 // =============================================================================
 
-func getTransitions(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/issue/%s/transitions", baseURL(), issueKey)
+func getTransitions(ctx context.Context, params issueParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/issue/%s/transitions", baseURL(), params.IssueKey)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -620,66 +391,42 @@ func getTransitions(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func transitionIssue(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	transitionID, ok := params["transition_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("transition_id must be a string")
-	}
-
+func transitionIssue(ctx context.Context, params transitionIssueParams) (string, error) {
 	body := map[string]interface{}{
-		"transition": map[string]string{"id": transitionID},
+		"transition": map[string]string{"id": params.TransitionID},
 	}
 
-	if comment, ok := params["comment"].(string); ok && comment != "" {
+	if params.Comment != "" {
 		body["update"] = map[string]interface{}{
 			"comment": []map[string]interface{}{
 				{
 					"add": map[string]interface{}{
-						"body": adfDocument(comment),
+						"body": adfDocument(params.Comment),
 					},
 				},
 			},
 		}
 	}
 
-	endpoint := fmt.Sprintf("%s/issue/%s/transitions", baseURL(), issueKey)
+	endpoint := fmt.Sprintf("%s/issue/%s/transitions", baseURL(), params.IssueKey)
 
-	_, err := client.DoJSON("POST", endpoint, headers(), body)
+	_, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf(`{"transitioned": true, "issue_key": "%s", "transition_id": "%s"}`, issueKey, transitionID), nil
+	modules.NotifyResourceUpdated(ctx, "jira://issue/"+params.IssueKey)
+	return fmt.Sprintf(`{"transitioned": true, "issue_key": "%s", "transition_id": "%s"}`, params.IssueKey, params.TransitionID), nil
 }
 
 // =============================================================================
 // Comments
 // =============================================================================
 
-func getComments(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	startAt := 0
-	if sa, ok := params["start_at"].(float64); ok {
-		startAt = int(sa)
-	}
-
-	maxResults := 50
-	if mr, ok := params["max_results"].(float64); ok {
-		maxResults = int(mr)
-	}
+func getComments(ctx context.Context, params issuePageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/issue/%s/comment?startAt=%d&maxResults=%d", baseURL(), params.IssueKey, params.StartAt, params.maxResults())
 
-	endpoint := fmt.Sprintf("%s/issue/%s/comment?startAt=%d&maxResults=%d", baseURL(), issueKey, startAt, maxResults)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.JiraPages("comments"))
 	if err != nil {
 		return "", err
 	}
@@ -687,24 +434,14 @@ func getComments(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addComment(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	body, ok := params["body"].(string)
-	if !ok {
-		return "", fmt.Errorf("body must be a string")
-	}
-
+func addComment(ctx context.Context, params addCommentParams) (string, error) {
 	payload := map[string]interface{}{
-		"body": adfDocument(body),
+		"body": adfDocument(params.Body),
 	}
 
-	endpoint := fmt.Sprintf("%s/issue/%s/comment", baseURL(), issueKey)
+	endpoint := fmt.Sprintf("%s/issue/%s/comment", baseURL(), params.IssueKey)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
@@ -716,25 +453,10 @@ func addComment(params map[string]interface{}) (string, error) {
 // Worklogs
 // =============================================================================
 
-func getWorklogs(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	startAt := 0
-	if sa, ok := params["start_at"].(float64); ok {
-		startAt = int(sa)
-	}
-
-	maxResults := 50
-	if mr, ok := params["max_results"].(float64); ok {
-		maxResults = int(mr)
-	}
-
-	endpoint := fmt.Sprintf("%s/issue/%s/worklog?startAt=%d&maxResults=%d", baseURL(), issueKey, startAt, maxResults)
+func getWorklogs(ctx context.Context, params issuePageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/issue/%s/worklog?startAt=%d&maxResults=%d", baseURL(), params.IssueKey, params.StartAt, params.maxResults())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), httpclient.JiraPages("worklogs"))
 	if err != nil {
 		return "", err
 	}
@@ -742,32 +464,22 @@ func getWorklogs(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addWorklog(params map[string]interface{}) (string, error) {
-	issueKey, ok := params["issue_key"].(string)
-	if !ok {
-		return "", fmt.Errorf("issue_key must be a string")
-	}
-
-	timeSpentSeconds, ok := params["time_spent_seconds"].(float64)
-	if !ok {
-		return "", fmt.Errorf("time_spent_seconds must be a number")
-	}
-
+func addWorklog(ctx context.Context, params addWorklogParams) (string, error) {
 	payload := map[string]interface{}{
-		"timeSpentSeconds": int(timeSpentSeconds),
+		"timeSpentSeconds": params.TimeSpentSeconds,
 	}
 
-	if started, ok := params["started"].(string); ok && started != "" {
-		payload["started"] = started
+	if params.Started != "" {
+		payload["started"] = params.Started
 	}
 
-	if comment, ok := params["comment"].(string); ok && comment != "" {
-		payload["comment"] = adfDocument(comment)
+	if params.Comment != "" {
+		payload["comment"] = adfDocument(params.Comment)
 	}
 
-	endpoint := fmt.Sprintf("%s/issue/%s/worklog", baseURL(), issueKey)
+	endpoint := fmt.Sprintf("%s/issue/%s/worklog", baseURL(), params.IssueKey)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
@@ -809,3 +521,156 @@ func joinStrings(strs []string, sep string) string {
 	}
 	return result
 }
+
+// =============================================================================
+// Resources
+// =============================================================================
+
+func readIssueResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	text, err := getIssue(ctx, getIssueParams{IssueKey: vars["key"]})
+	if err != nil {
+		return nil, err
+	}
+	return []modules.ResourceContents{{
+		URI:      "jira://issue/" + vars["key"],
+		MimeType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+func readProjectResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	text, err := getProject(ctx, getProjectParams{ProjectKey: vars["project"]})
+	if err != nil {
+		return nil, err
+	}
+	return []modules.ResourceContents{{
+		URI:      "jira://project/" + vars["project"],
+		MimeType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+// listResources lists issues updated during the last week
+func listResources(ctx context.Context) ([]modules.Resource, error) {
+	if getDomain() == "" {
+		return nil, nil
+	}
+
+	query := url.Values{}
+	query.Set("jql", "updated >= -7d ORDER BY updated DESC")
+	query.Set("maxResults", "20")
+	query.Set("fields", "summary")
+
+	respBody, err := client.DoJSON(ctx, "GET", fmt.Sprintf("%s/search/jql?%s", baseURL(), query.Encode()), headers(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary string `json:"summary"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := httpclient.UnmarshalJSON(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	resources := make([]modules.Resource, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		resources = append(resources, modules.Resource{
+			URI:         "jira://issue/" + issue.Key,
+			Name:        issue.Key,
+			Description: issue.Fields.Summary,
+			MimeType:    "application/json",
+		})
+	}
+	return resources, nil
+}
+
+// =============================================================================
+// Prompts
+// =============================================================================
+
+func triageIssuePrompt(ctx context.Context, args map[string]string) (*modules.PromptResult, error) {
+	contents, err := readIssueResource(ctx, map[string]string{"key": args["key"]})
+	if err != nil {
+		return nil, err
+	}
+
+	return &modules.PromptResult{
+		Description: "Triage " + args["key"],
+		Messages: []modules.PromptMessage{
+			modules.ResourceMessage(contents[0]),
+			modules.UserMessage(fmt.Sprintf(`Triage Jira issue %s shown above.
+1. Summarize the problem in one or two sentences.
+2. Suggest a priority and explain why.
+3. List information that is missing to start working on it.
+4. Propose the next steps and who should own them.`, args["key"])),
+		},
+	}, nil
+}
+
+// completeIssueKey suggests project key prefixes and recently updated issues
+func completeIssueKey(ctx context.Context, args map[string]string) ([]string, error) {
+	keys, err := projectKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		keys[i] = key + "-"
+	}
+
+	query := url.Values{}
+	query.Set("jql", "updated >= -30d ORDER BY updated DESC")
+	query.Set("maxResults", "100")
+	query.Set("fields", "summary")
+
+	respBody, err := client.DoJSON(ctx, "GET", fmt.Sprintf("%s/search/jql?%s", baseURL(), query.Encode()), headers(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := httpclient.UnmarshalJSON(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for _, issue := range result.Issues {
+		keys = append(keys, issue.Key)
+	}
+	return keys, nil
+}
+
+func completeProjectKey(ctx context.Context, args map[string]string) ([]string, error) {
+	return projectKeys(ctx)
+}
+
+// projectKeys returns the keys of the projects visible to the user
+func projectKeys(ctx context.Context) ([]string, error) {
+	text, err := listProjects(ctx, listProjectsParams{pageParams{MaxResults: 100}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Values []struct {
+			Key string `json:"key"`
+		} `json:"values"`
+	}
+	if err := httpclient.UnmarshalJSON([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+
+	keys := make([]string, 0, len(result.Values))
+	for _, project := range result.Values {
+		keys = append(keys, project.Key)
+	}
+	return keys, nil
+}
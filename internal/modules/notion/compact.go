@@ -0,0 +1,256 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// maxBlockDepth bounds how deep compactPageContent fetches nested blocks
+const maxBlockDepth = 3
+
+var compactors = map[string]modules.Compactor{
+	"get_page_content": compactPageContent,
+}
+
+type richText struct {
+	PlainText   string `json:"plain_text"`
+	Href        string `json:"href"`
+	Annotations struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Strikethrough bool `json:"strikethrough"`
+		Code          bool `json:"code"`
+	} `json:"annotations"`
+}
+
+// blockData is the type-specific part of a block; which fields are set
+// depends on the block type
+type blockData struct {
+	RichText []richText   `json:"rich_text"`
+	Checked  bool         `json:"checked"`
+	Language string       `json:"language"`
+	Title    string       `json:"title"`
+	URL      string       `json:"url"`
+	Caption  []richText   `json:"caption"`
+	Cells    [][]richText `json:"cells"`
+	External *struct {
+		URL string `json:"url"`
+	} `json:"external"`
+	File *struct {
+		URL string `json:"url"`
+	} `json:"file"`
+	Expression string `json:"expression"`
+}
+
+type block struct {
+	ID          string
+	Type        string
+	HasChildren bool
+	Data        blockData
+	Children    []block
+}
+
+func (b *block) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	json.Unmarshal(raw["id"], &b.ID)
+	json.Unmarshal(raw["type"], &b.Type)
+	json.Unmarshal(raw["has_children"], &b.HasChildren)
+	if typed, ok := raw[b.Type]; ok {
+		return json.Unmarshal(typed, &b.Data)
+	}
+	return nil
+}
+
+type blockList struct {
+	Results    []block `json:"results"`
+	HasMore    bool    `json:"has_more"`
+	NextCursor string  `json:"next_cursor"`
+}
+
+// compactPageContent renders a page's block tree as Markdown, fetching the
+// children of nested blocks up to maxBlockDepth levels
+func compactPageContent(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+	var list blockList
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return "", err
+	}
+	if err := fetchChildren(ctx, list.Results, 1); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	writeBlocks(&b, list.Results, "")
+	text := strings.TrimSpace(b.String())
+	if list.HasMore {
+		text += fmt.Sprintf("\n\n(More blocks follow; next_cursor %s)", list.NextCursor)
+	}
+	return text, nil
+}
+
+func fetchChildren(ctx context.Context, blocks []block, depth int) error {
+	if depth > maxBlockDepth {
+		return nil
+	}
+	for i := range blocks {
+		// Child pages and databases are separate documents, not nested content
+		if !blocks[i].HasChildren || blocks[i].Type == "child_page" || blocks[i].Type == "child_database" {
+			continue
+		}
+		endpoint := fmt.Sprintf("%s/blocks/%s/children?page_size=100", notionAPIBase, blocks[i].ID)
+		respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
+		if err != nil {
+			return err
+		}
+		var children blockList
+		if err := json.Unmarshal(respBody, &children); err != nil {
+			return err
+		}
+		blocks[i].Children = children.Results
+		if err := fetchChildren(ctx, blocks[i].Children, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBlocks(b *strings.Builder, blocks []block, indent string) {
+	numbered := 0
+	for _, blk := range blocks {
+		if blk.Type == "numbered_list_item" {
+			numbered++
+		} else {
+			numbered = 0
+		}
+		writeBlock(b, blk, indent, numbered)
+	}
+}
+
+func writeBlock(b *strings.Builder, blk block, indent string, number int) {
+	text := richTextMarkdown(blk.Data.RichText)
+	childIndent := indent + "  "
+
+	switch blk.Type {
+	case "paragraph":
+		b.WriteString(indent + text + "\n\n")
+	case "heading_1":
+		b.WriteString(indent + "# " + text + "\n\n")
+	case "heading_2":
+		b.WriteString(indent + "## " + text + "\n\n")
+	case "heading_3":
+		b.WriteString(indent + "### " + text + "\n\n")
+	case "bulleted_list_item":
+		b.WriteString(indent + "- " + text + "\n")
+	case "numbered_list_item":
+		fmt.Fprintf(b, "%s%d. %s\n", indent, number, text)
+	case "to_do":
+		mark := " "
+		if blk.Data.Checked {
+			mark = "x"
+		}
+		b.WriteString(indent + "- [" + mark + "] " + text + "\n")
+	case "toggle":
+		b.WriteString(indent + "- " + text + "\n")
+	case "quote", "callout":
+		b.WriteString(indent + "> " + text + "\n\n")
+	case "code":
+		b.WriteString(indent + "```" + blk.Data.Language + "\n" + plainText(blk.Data.RichText) + "\n" + indent + "```\n\n")
+	case "equation":
+		b.WriteString(indent + "$$" + blk.Data.Expression + "$$\n\n")
+	case "divider":
+		b.WriteString(indent + "---\n\n")
+	case "child_page":
+		b.WriteString(indent + "[page] " + blk.Data.Title + " (" + blk.ID + ")\n\n")
+	case "child_database":
+		b.WriteString(indent + "[database] " + blk.Data.Title + " (" + blk.ID + ")\n\n")
+	case "bookmark", "embed", "link_preview":
+		b.WriteString(indent + blk.Data.URL + "\n\n")
+	case "image", "file", "pdf", "video", "audio":
+		url := blk.Data.URL
+		if blk.Data.External != nil {
+			url = blk.Data.External.URL
+		} else if blk.Data.File != nil {
+			url = blk.Data.File.URL
+		}
+		fmt.Fprintf(b, "%s[%s: %s](%s)\n\n", indent, blk.Type, richTextMarkdown(blk.Data.Caption), url)
+	case "table":
+		writeTable(b, blk.Children, indent)
+		return
+	case "column_list", "column", "synced_block":
+		childIndent = indent
+	default:
+		if text != "" {
+			b.WriteString(indent + text + "\n\n")
+		} else {
+			b.WriteString(indent + "[" + blk.Type + "]\n\n")
+		}
+	}
+
+	writeBlocks(b, blk.Children, childIndent)
+}
+
+func writeTable(b *strings.Builder, rows []block, indent string) {
+	if len(rows) == 0 {
+		return
+	}
+	cells := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		line := make([]string, len(row.Data.Cells))
+		for i, cell := range row.Data.Cells {
+			line[i] = richTextMarkdown(cell)
+		}
+		cells = append(cells, line)
+	}
+	// The first row serves as the header
+	table := modules.MarkdownTable(cells[0], cells[1:])
+	for _, line := range strings.SplitAfter(table, "\n") {
+		if line != "" {
+			b.WriteString(indent + line)
+		}
+	}
+	b.WriteString("\n")
+}
+
+func plainText(texts []richText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		b.WriteString(t.PlainText)
+	}
+	return b.String()
+}
+
+func richTextMarkdown(texts []richText) string {
+	var b strings.Builder
+	for _, t := range texts {
+		s := t.PlainText
+		if strings.TrimSpace(s) == "" {
+			b.WriteString(s)
+			continue
+		}
+		a := t.Annotations
+		switch {
+		case a.Code:
+			s = "`" + s + "`"
+		case a.Bold && a.Italic:
+			s = "***" + s + "***"
+		case a.Bold:
+			s = "**" + s + "**"
+		case a.Italic:
+			s = "*" + s + "*"
+		}
+		if a.Strikethrough {
+			s = "~~" + s + "~~"
+		}
+		if t.Href != "" {
+			s = "[" + s + "](" + t.Href + ")"
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package notion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// checkSummary runs compact on raw and checks that the summary has every
+// want and is shorter than the payload
+func checkSummary(t *testing.T, compact modules.Compactor, raw string, want ...string) {
+	t.Helper()
+	got, err := compact(context.Background(), nil, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in:\n%s", w, got)
+		}
+	}
+	if len(got) >= len(raw) {
+		t.Error("summary is not shorter than the payload")
+	}
+}
+
+func TestCompactPageContent(t *testing.T) {
+	raw := `{"results":[
+			{"id":"1","type":"heading_2","heading_2":{"rich_text":[{"plain_text":"Plan"}]}},
+			{"id":"2","type":"to_do","to_do":{"checked":true,"rich_text":[{"plain_text":"ship","annotations":{"bold":true}}]}},
+			{"id":"3","type":"child_page","has_children":true,"child_page":{"title":"Notes"}}],
+			"has_more":true,"next_cursor":"abc"}`
+	checkSummary(t, compactPageContent, raw,
+		"## Plan\n\n- [x] **ship**\n[page] Notes (3)",
+		"next_cursor abc",
+	)
+}
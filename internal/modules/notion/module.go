@@ -1,6 +1,7 @@
 package notion
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -11,11 +12,17 @@ import (
 )
 
 const (
-	notionAPIBase  = "https://api.notion.com/v1"
-	notionVersion  = "2022-06-28"
+	notionAPIBase = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
 )
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("notion"), httpclient.WithCache(httpclient.DefaultCachePolicy))
+
+// rateLimit follows Notion's average of 3 requests per second per integration
+var rateLimit = httpclient.RateLimit{Rate: 3, Burst: 3}
+
+// pages follows next_cursor through the results of list endpoints
+var pages = httpclient.NextCursor("results")
 
 func getToken() string {
 	return os.Getenv("NOTION_TOKEN")
@@ -31,311 +38,174 @@ func headers() map[string]string {
 // Module returns the Notion module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "notion",
-		Description: "Notion API - ページ・データベース・ブロック操作",
-		APIVersion:  notionVersion,
-		TestedAt:    "2026-01-10",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "notion",
+		Description:       "Notion API - ページ・データベース・ブロック操作",
+		APIVersion:        notionVersion,
+		TestedAt:          "2026-01-10",
+		Compactors:        compactors,
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		ListResources:     listResources,
+	}.WithTools(tools...).WithPagination("search", "query_database", "list_comments", "list_users")
 }
 
-var tools = []modules.Tool{
+var tools = []modules.TypedTool{
 	// Search
-	{
-		Name:        "search",
-		Description: "Search pages and databases in Notion by title. Returns pages and databases shared with the integration.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"query": {
-					Type:        "string",
-					Description: "Search query to match against page/database titles. If empty, returns all shared content.",
-				},
-				"filter_type": {
-					Type:        "string",
-					Description: "Filter results to only pages or only databases (page or database)",
-				},
-				"page_size": {
-					Type:        "number",
-					Description: "Number of results to return (max 100, default 10)",
-				},
-			},
-		},
-	},
+	modules.NewTool("search", "Search pages and databases in Notion by title. Returns pages and databases shared with the integration.", search),
 	// Pages
-	{
-		Name:        "get_page",
-		Description: "Retrieve a Notion page by ID. Returns page properties and metadata.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "The ID of the page to retrieve (UUID format)",
-				},
-			},
-			Required: []string{"page_id"},
-		},
-	},
-	{
-		Name:        "get_page_content",
-		Description: "Get the content (blocks) of a Notion page. Use this to read the actual text content.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "The ID of the page to get content from",
-				},
-				"page_size": {
-					Type:        "number",
-					Description: "Number of blocks to return (max 100, default 50)",
-				},
-			},
-			Required: []string{"page_id"},
-		},
-	},
-	{
-		Name:        "create_page",
-		Description: "Create a new page in Notion. Can create as a child of another page or in a database.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"parent_page_id": {
-					Type:        "string",
-					Description: "Parent page ID (use this OR parent_database_id)",
-				},
-				"parent_database_id": {
-					Type:        "string",
-					Description: "Parent database ID (use this OR parent_page_id)",
-				},
-				"title": {
-					Type:        "string",
-					Description: "Page title",
-				},
-				"properties": {
-					Type:        "object",
-					Description: "Page properties (for database pages). Keys are property names.",
-				},
-			},
-			Required: []string{"title"},
-		},
-	},
-	{
-		Name:        "update_page",
-		Description: "Update a Notion page's properties.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "The ID of the page to update",
-				},
-				"properties": {
-					Type:        "object",
-					Description: "Properties to update. Keys are property names.",
-				},
-			},
-			Required: []string{"page_id", "properties"},
-		},
-	},
+	modules.NewTool("get_page", "Retrieve a Notion page by ID. Returns page properties and metadata.", getPage),
+	modules.NewTool("get_page_content", "Get the content (blocks) of a Notion page. Use this to read the actual text content.", getPageContent),
+	modules.NewTool("create_page", "Create a new page in Notion. Can create as a child of another page or in a database.", createPage),
+	modules.NewTool("update_page", "Update a Notion page's properties.", updatePage),
 	// Databases
-	{
-		Name:        "get_database",
-		Description: "Retrieve a Notion database schema and metadata.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"database_id": {
-					Type:        "string",
-					Description: "The ID of the database to retrieve",
-				},
-			},
-			Required: []string{"database_id"},
-		},
-	},
-	{
-		Name:        "query_database",
-		Description: "Query a Notion database with optional filters and sorts. Returns pages in the database.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"database_id": {
-					Type:        "string",
-					Description: "The ID of the database to query",
-				},
-				"filter": {
-					Type:        "object",
-					Description: "Filter object (Notion filter format)",
-				},
-				"sorts": {
-					Type:        "array",
-					Description: "Sort specifications array",
-				},
-				"page_size": {
-					Type:        "number",
-					Description: "Number of results to return (max 100, default 10)",
-				},
-			},
-			Required: []string{"database_id"},
-		},
-	},
+	modules.NewTool("get_database", "Retrieve a Notion database schema and metadata.", getDatabase),
+	modules.NewTool("query_database", "Query a Notion database with optional filters and sorts. Returns pages in the database.", queryDatabase),
 	// Blocks
-	{
-		Name:        "append_blocks",
-		Description: "Append content blocks to a page or block. Use to add text, headings, lists, etc.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"block_id": {
-					Type:        "string",
-					Description: "The ID of the page or block to append to",
-				},
-				"blocks": {
-					Type:        "array",
-					Description: "Array of block objects to append. Each block needs type (paragraph, heading_1, heading_2, heading_3, bulleted_list_item, numbered_list_item, to_do, toggle, code, quote, callout, divider) and content (text).",
-				},
-			},
-			Required: []string{"block_id", "blocks"},
-		},
-	},
-	{
-		Name:        "delete_block",
-		Description: "Delete a block from Notion. This also deletes all children of the block.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"block_id": {
-					Type:        "string",
-					Description: "The ID of the block to delete",
-				},
-			},
-			Required: []string{"block_id"},
-		},
-	},
+	modules.NewTool("append_blocks", "Append content blocks to a page or block. Use to add text, headings, lists, etc.", appendBlocks),
+	modules.NewTool("delete_block", "Delete a block from Notion. This also deletes all children of the block.", deleteBlock),
 	// Comments
-	{
-		Name:        "list_comments",
-		Description: "List comments on a Notion page or block.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"block_id": {
-					Type:        "string",
-					Description: "The ID of the page or block to get comments from",
-				},
-				"page_size": {
-					Type:        "number",
-					Description: "Number of comments to return (max 100, default 50)",
-				},
-			},
-			Required: []string{"block_id"},
-		},
-	},
-	{
-		Name:        "add_comment",
-		Description: "Add a comment to a Notion page.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_id": {
-					Type:        "string",
-					Description: "The ID of the page to comment on",
-				},
-				"content": {
-					Type:        "string",
-					Description: "Comment text content",
-				},
-			},
-			Required: []string{"page_id", "content"},
-		},
-	},
+	modules.NewTool("list_comments", "List comments on a Notion page or block.", listComments),
+	modules.NewTool("add_comment", "Add a comment to a Notion page.", addComment),
 	// Users
+	modules.NewTool("list_users", "List all users in the Notion workspace.", listUsers),
+	modules.NewTool("get_user", "Get information about a Notion user.", getUser),
+	modules.NewTool("get_bot_user", "Get information about the current integration bot user.", getBotUser),
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "list_users",
-		Description: "List all users in the Notion workspace.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"page_size": {
-					Type:        "number",
-					Description: "Number of users to return (max 100, default 50)",
-				},
-			},
-		},
-	},
-	{
-		Name:        "get_user",
-		Description: "Get information about a Notion user.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"user_id": {
-					Type:        "string",
-					Description: "The ID of the user to retrieve",
-				},
-			},
-			Required: []string{"user_id"},
-		},
-	},
-	{
-		Name:        "get_bot_user",
-		Description: "Get information about the current integration bot user.",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
+		URITemplate: "notion://page/{id}",
+		Name:        "page",
+		Description: "The content blocks of a Notion page",
+		MimeType:    "application/json",
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"search":           search,
-	"get_page":         getPage,
-	"get_page_content": getPageContent,
-	"create_page":      createPage,
-	"update_page":      updatePage,
-	"get_database":     getDatabase,
-	"query_database":   queryDatabase,
-	"append_blocks":    appendBlocks,
-	"delete_block":     deleteBlock,
-	"list_comments":    listComments,
-	"add_comment":      addComment,
-	"list_users":       listUsers,
-	"get_user":         getUser,
-	"get_bot_user":     getBotUser,
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"page": readPageResource,
+}
+
+// =============================================================================
+// Params
+// =============================================================================
+
+type noParams struct{}
+
+type searchParams struct {
+	Query      string `json:"query,omitempty" description:"Search query to match against page/database titles. If empty, returns all shared content."`
+	FilterType string `json:"filter_type,omitempty" jsonschema:"enum=page|database" description:"Filter results to only pages or only databases (page or database)"`
+	PageSize   int    `json:"page_size,omitempty" jsonschema:"default=10,minimum=1,maximum=100" description:"Number of results to return (max 100, default 10)"`
+}
+
+type getPageParams struct {
+	PageID string `json:"page_id" jsonschema:"required" description:"The ID of the page to retrieve (UUID format)"`
+}
+
+type getPageContentParams struct {
+	PageID   string `json:"page_id" jsonschema:"required" description:"The ID of the page to get content from"`
+	PageSize int    `json:"page_size,omitempty" jsonschema:"default=50,minimum=1,maximum=100" description:"Number of blocks to return (max 100, default 50)"`
+}
+
+type createPageParams struct {
+	ParentPageID     string                 `json:"parent_page_id,omitempty" description:"Parent page ID (use this OR parent_database_id)"`
+	ParentDatabaseID string                 `json:"parent_database_id,omitempty" description:"Parent database ID (use this OR parent_page_id)"`
+	Title            string                 `json:"title" jsonschema:"required" description:"Page title"`
+	Properties       map[string]interface{} `json:"properties,omitempty" description:"Page properties (for database pages). Keys are property names."`
+}
+
+type updatePageParams struct {
+	PageID     string                 `json:"page_id" jsonschema:"required" description:"The ID of the page to update"`
+	Properties map[string]interface{} `json:"properties" jsonschema:"required" description:"Properties to update. Keys are property names."`
+}
+
+type getDatabaseParams struct {
+	DatabaseID string `json:"database_id" jsonschema:"required" description:"The ID of the database to retrieve"`
+}
+
+type sortSpec struct {
+	Property  string `json:"property,omitempty" description:"Property name to sort by"`
+	Timestamp string `json:"timestamp,omitempty" jsonschema:"enum=created_time|last_edited_time"`
+	Direction string `json:"direction,omitempty" jsonschema:"enum=ascending|descending"`
+}
+
+type queryDatabaseParams struct {
+	DatabaseID string                 `json:"database_id" jsonschema:"required" description:"The ID of the database to query"`
+	Filter     map[string]interface{} `json:"filter,omitempty" description:"Filter object (Notion filter format)"`
+	Sorts      []sortSpec             `json:"sorts,omitempty" description:"Sort specifications array"`
+	PageSize   int                    `json:"page_size,omitempty" jsonschema:"default=10,minimum=1,maximum=100" description:"Number of results to return (max 100, default 10)"`
+}
+
+type blockSpec struct {
+	Type     string `json:"type" jsonschema:"required,enum=paragraph|heading_1|heading_2|heading_3|bulleted_list_item|numbered_list_item|to_do|toggle|code|quote|callout|divider"`
+	Content  string `json:"content,omitempty" description:"Text content of the block"`
+	Checked  bool   `json:"checked,omitempty" description:"Whether a to_do block is checked"`
+	Language string `json:"language,omitempty" description:"Language of a code block"`
+}
+
+type appendBlocksParams struct {
+	BlockID string      `json:"block_id" jsonschema:"required" description:"The ID of the page or block to append to"`
+	Blocks  []blockSpec `json:"blocks" jsonschema:"required" description:"Array of block objects to append. Each block needs type (paragraph, heading_1, heading_2, heading_3, bulleted_list_item, numbered_list_item, to_do, toggle, code, quote, callout, divider) and content (text)."`
+}
+
+type deleteBlockParams struct {
+	BlockID string `json:"block_id" jsonschema:"required" description:"The ID of the block to delete"`
+}
+
+type listCommentsParams struct {
+	BlockID  string `json:"block_id" jsonschema:"required" description:"The ID of the page or block to get comments from"`
+	PageSize int    `json:"page_size,omitempty" jsonschema:"default=50,minimum=1,maximum=100" description:"Number of comments to return (max 100, default 50)"`
+}
+
+type addCommentParams struct {
+	PageID  string `json:"page_id" jsonschema:"required" description:"The ID of the page to comment on"`
+	Content string `json:"content" jsonschema:"required" description:"Comment text content"`
+}
+
+type listUsersParams struct {
+	PageSize int `json:"page_size,omitempty" jsonschema:"default=50,minimum=1,maximum=100" description:"Number of users to return (max 100, default 50)"`
+}
+
+type getUserParams struct {
+	UserID string `json:"user_id" jsonschema:"required" description:"The ID of the user to retrieve"`
+}
+
+// pageSize returns size, or fallback when it is unset, capped at Notion's
+// maximum of 100
+func pageSize(size, fallback int) int {
+	if size <= 0 {
+		return fallback
+	}
+	if size > 100 {
+		return 100
+	}
+	return size
 }
 
 // =============================================================================
 // Search
 // =============================================================================
 
-func search(params map[string]interface{}) (string, error) {
+func search(ctx context.Context, params searchParams) (string, error) {
 	endpoint := notionAPIBase + "/search"
 
 	body := make(map[string]interface{})
 
-	if query, ok := params["query"].(string); ok && query != "" {
-		body["query"] = query
+	if params.Query != "" {
+		body["query"] = params.Query
 	}
 
-	if filterType, ok := params["filter_type"].(string); ok && filterType != "" {
+	if params.FilterType != "" {
 		body["filter"] = map[string]interface{}{
 			"property": "object",
-			"value":    filterType,
+			"value":    params.FilterType,
 		}
 	}
 
-	pageSize := 10
-	if ps, ok := params["page_size"].(float64); ok {
-		pageSize = int(ps)
-		if pageSize > 100 {
-			pageSize = 100
-		}
-	}
-	body["page_size"] = pageSize
+	body["page_size"] = pageSize(params.PageSize, 10)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	// Search is a read sent as POST, so it is safe to retry
+	respBody, err := client.Paginate(httpclient.Idempotent(ctx), httpclient.PageRequest{Method: "POST", URL: endpoint, Body: body}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -347,15 +217,10 @@ func search(params map[string]interface{}) (string, error) {
 // Pages
 // =============================================================================
 
-func getPage(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/pages/%s", notionAPIBase, pageID)
+func getPage(ctx context.Context, params getPageParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/pages/%s", notionAPIBase, params.PageID)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -363,23 +228,10 @@ func getPage(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getPageContent(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
+func getPageContent(ctx context.Context, params getPageContentParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/blocks/%s/children?page_size=%d", notionAPIBase, params.PageID, pageSize(params.PageSize, 50))
 
-	pageSize := 50
-	if ps, ok := params["page_size"].(float64); ok {
-		pageSize = int(ps)
-		if pageSize > 100 {
-			pageSize = 100
-		}
-	}
-
-	endpoint := fmt.Sprintf("%s/blocks/%s/children?page_size=%d", notionAPIBase, pageID, pageSize)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -387,29 +239,23 @@ func getPageContent(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func createPage(params map[string]interface{}) (string, error) {
-	title, ok := params["title"].(string)
-	if !ok {
-		return "", fmt.Errorf("title must be a string")
-	}
-
-	parentPageID, hasParentPage := params["parent_page_id"].(string)
-	parentDatabaseID, hasParentDB := params["parent_database_id"].(string)
+func createPage(ctx context.Context, params createPageParams) (string, error) {
+	title := params.Title
 
-	if !hasParentPage && !hasParentDB {
+	if params.ParentPageID == "" && params.ParentDatabaseID == "" {
 		return "", fmt.Errorf("either parent_page_id or parent_database_id is required")
 	}
 
 	body := make(map[string]interface{})
 
-	if hasParentDB && parentDatabaseID != "" {
+	if params.ParentDatabaseID != "" {
 		body["parent"] = map[string]interface{}{
-			"database_id": parentDatabaseID,
+			"database_id": params.ParentDatabaseID,
 		}
 		// For database pages, set title in Name or Title property
 		properties := make(map[string]interface{})
-		if props, ok := params["properties"].(map[string]interface{}); ok {
-			properties = props
+		if params.Properties != nil {
+			properties = params.Properties
 		}
 		// Add Name property with title if not already set
 		if _, hasName := properties["Name"]; !hasName {
@@ -424,7 +270,7 @@ func createPage(params map[string]interface{}) (string, error) {
 		body["properties"] = properties
 	} else {
 		body["parent"] = map[string]interface{}{
-			"page_id": parentPageID,
+			"page_id": params.ParentPageID,
 		}
 		body["properties"] = map[string]interface{}{
 			"title": map[string]interface{}{
@@ -437,7 +283,7 @@ func createPage(params map[string]interface{}) (string, error) {
 
 	endpoint := notionAPIBase + "/pages"
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -445,24 +291,14 @@ func createPage(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func updatePage(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	properties, ok := params["properties"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("properties must be an object")
-	}
-
+func updatePage(ctx context.Context, params updatePageParams) (string, error) {
 	body := map[string]interface{}{
-		"properties": properties,
+		"properties": params.Properties,
 	}
 
-	endpoint := fmt.Sprintf("%s/pages/%s", notionAPIBase, pageID)
+	endpoint := fmt.Sprintf("%s/pages/%s", notionAPIBase, params.PageID)
 
-	respBody, err := client.DoJSON("PATCH", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "PATCH", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -474,15 +310,10 @@ func updatePage(params map[string]interface{}) (string, error) {
 // Databases
 // =============================================================================
 
-func getDatabase(params map[string]interface{}) (string, error) {
-	databaseID, ok := params["database_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("database_id must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/databases/%s", notionAPIBase, databaseID)
+func getDatabase(ctx context.Context, params getDatabaseParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/databases/%s", notionAPIBase, params.DatabaseID)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(httpclient.Cacheable(ctx), "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -490,34 +321,22 @@ func getDatabase(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func queryDatabase(params map[string]interface{}) (string, error) {
-	databaseID, ok := params["database_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("database_id must be a string")
-	}
-
+func queryDatabase(ctx context.Context, params queryDatabaseParams) (string, error) {
 	body := make(map[string]interface{})
 
-	if filter, ok := params["filter"].(map[string]interface{}); ok {
-		body["filter"] = filter
+	if params.Filter != nil {
+		body["filter"] = params.Filter
 	}
 
-	if sorts, ok := params["sorts"].([]interface{}); ok {
-		body["sorts"] = sorts
+	if params.Sorts != nil {
+		body["sorts"] = params.Sorts
 	}
 
-	pageSize := 10
-	if ps, ok := params["page_size"].(float64); ok {
-		pageSize = int(ps)
-		if pageSize > 100 {
-			pageSize = 100
-		}
-	}
-	body["page_size"] = pageSize
+	body["page_size"] = pageSize(params.PageSize, 10)
 
-	endpoint := fmt.Sprintf("%s/databases/%s/query", notionAPIBase, databaseID)
+	endpoint := fmt.Sprintf("%s/databases/%s/query", notionAPIBase, params.DatabaseID)
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.Paginate(httpclient.Idempotent(ctx), httpclient.PageRequest{Method: "POST", URL: endpoint, Body: body}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -529,29 +348,14 @@ func queryDatabase(params map[string]interface{}) (string, error) {
 // Blocks
 // =============================================================================
 
-func appendBlocks(params map[string]interface{}) (string, error) {
-	blockID, ok := params["block_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("block_id must be a string")
-	}
-
-	blocksInput, ok := params["blocks"].([]interface{})
-	if !ok {
-		return "", fmt.Errorf("blocks must be an array")
-	}
-
-	children := make([]map[string]interface{}, 0, len(blocksInput))
-
-	for _, b := range blocksInput {
-		block, ok := b.(map[string]interface{})
-		if !ok {
-			continue
-		}
+func appendBlocks(ctx context.Context, params appendBlocksParams) (string, error) {
+	children := make([]map[string]interface{}, 0, len(params.Blocks))
 
-		blockType, _ := block["type"].(string)
-		content, _ := block["content"].(string)
-		checked, _ := block["checked"].(bool)
-		language, _ := block["language"].(string)
+	for _, block := range params.Blocks {
+		blockType := block.Type
+		content := block.Content
+		checked := block.Checked
+		language := block.Language
 
 		richText := []map[string]interface{}{}
 		if content != "" {
@@ -654,25 +458,43 @@ func appendBlocks(params map[string]interface{}) (string, error) {
 		"children": children,
 	}
 
-	endpoint := fmt.Sprintf("%s/blocks/%s/children", notionAPIBase, blockID)
+	endpoint := fmt.Sprintf("%s/blocks/%s/children", notionAPIBase, params.BlockID)
 
-	respBody, err := client.DoJSON("PATCH", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "PATCH", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
 
+	if pageID := appendedToPage(respBody); pageID != "" {
+		modules.NotifyResourceUpdated(ctx, "notion://page/"+pageID)
+	}
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func deleteBlock(params map[string]interface{}) (string, error) {
-	blockID, ok := params["block_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("block_id must be a string")
+// appendedToPage returns the ID of the page the appended blocks sit directly
+// under, or "" when they were nested inside another block
+func appendedToPage(respBody []byte) string {
+	var result struct {
+		Results []struct {
+			Parent struct {
+				Type   string `json:"type"`
+				PageID string `json:"page_id"`
+			} `json:"parent"`
+		} `json:"results"`
+	}
+	if err := httpclient.UnmarshalJSON(respBody, &result); err != nil || len(result.Results) == 0 {
+		return ""
 	}
+	if parent := result.Results[0].Parent; parent.Type == "page_id" {
+		return parent.PageID
+	}
+	return ""
+}
 
-	endpoint := fmt.Sprintf("%s/blocks/%s", notionAPIBase, blockID)
+func deleteBlock(ctx context.Context, params deleteBlockParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/blocks/%s", notionAPIBase, params.BlockID)
 
-	respBody, err := client.DoJSON("DELETE", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "DELETE", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -684,27 +506,14 @@ func deleteBlock(params map[string]interface{}) (string, error) {
 // Comments
 // =============================================================================
 
-func listComments(params map[string]interface{}) (string, error) {
-	blockID, ok := params["block_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("block_id must be a string")
-	}
-
-	pageSize := 50
-	if ps, ok := params["page_size"].(float64); ok {
-		pageSize = int(ps)
-		if pageSize > 100 {
-			pageSize = 100
-		}
-	}
-
+func listComments(ctx context.Context, params listCommentsParams) (string, error) {
 	query := url.Values{}
-	query.Set("block_id", blockID)
-	query.Set("page_size", fmt.Sprintf("%d", pageSize))
+	query.Set("block_id", params.BlockID)
+	query.Set("page_size", fmt.Sprintf("%d", pageSize(params.PageSize, 50)))
 
 	endpoint := fmt.Sprintf("%s/comments?%s", notionAPIBase, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -712,29 +521,19 @@ func listComments(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func addComment(params map[string]interface{}) (string, error) {
-	pageID, ok := params["page_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("page_id must be a string")
-	}
-
-	content, ok := params["content"].(string)
-	if !ok {
-		return "", fmt.Errorf("content must be a string")
-	}
-
+func addComment(ctx context.Context, params addCommentParams) (string, error) {
 	body := map[string]interface{}{
 		"parent": map[string]interface{}{
-			"page_id": pageID,
+			"page_id": params.PageID,
 		},
 		"rich_text": []map[string]interface{}{
-			{"text": map[string]interface{}{"content": content}},
+			{"text": map[string]interface{}{"content": params.Content}},
 		},
 	}
 
 	endpoint := notionAPIBase + "/comments"
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), body)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), body)
 	if err != nil {
 		return "", err
 	}
@@ -746,18 +545,10 @@ func addComment(params map[string]interface{}) (string, error) {
 // Users
 // =============================================================================
 
-func listUsers(params map[string]interface{}) (string, error) {
-	pageSize := 50
-	if ps, ok := params["page_size"].(float64); ok {
-		pageSize = int(ps)
-		if pageSize > 100 {
-			pageSize = 100
-		}
-	}
-
-	endpoint := fmt.Sprintf("%s/users?page_size=%d", notionAPIBase, pageSize)
+func listUsers(ctx context.Context, params listUsersParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/users?page_size=%d", notionAPIBase, pageSize(params.PageSize, 50))
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: endpoint}, headers(), pages)
 	if err != nil {
 		return "", err
 	}
@@ -765,15 +556,10 @@ func listUsers(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getUser(params map[string]interface{}) (string, error) {
-	userID, ok := params["user_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("user_id must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/users/%s", notionAPIBase, userID)
+func getUser(ctx context.Context, params getUserParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/users/%s", notionAPIBase, params.UserID)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -781,10 +567,10 @@ func getUser(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getBotUser(params map[string]interface{}) (string, error) {
+func getBotUser(ctx context.Context, params noParams) (string, error) {
 	endpoint := notionAPIBase + "/users/me"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -794,3 +580,72 @@ func getBotUser(params map[string]interface{}) (string, error) {
 
 // Ensure json package is used
 var _ = json.Marshal
+
+// =============================================================================
+// Resources
+// =============================================================================
+
+func readPageResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	text, err := getPageContent(ctx, getPageContentParams{PageID: vars["id"]})
+	if err != nil {
+		return nil, err
+	}
+	return []modules.ResourceContents{{
+		URI:      "notion://page/" + vars["id"],
+		MimeType: "application/json",
+		Text:     text,
+	}}, nil
+}
+
+// listResources lists the most recently edited pages
+func listResources(ctx context.Context) ([]modules.Resource, error) {
+	if getToken() == "" {
+		return nil, nil
+	}
+
+	body := map[string]interface{}{
+		"filter":    map[string]interface{}{"property": "object", "value": "page"},
+		"sort":      map[string]interface{}{"timestamp": "last_edited_time", "direction": "descending"},
+		"page_size": 20,
+	}
+	respBody, err := client.DoJSON(httpclient.Idempotent(ctx), "POST", notionAPIBase+"/search", headers(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Results []struct {
+			ID         string `json:"id"`
+			Properties map[string]struct {
+				Type  string `json:"type"`
+				Title []struct {
+					PlainText string `json:"plain_text"`
+				} `json:"title"`
+			} `json:"properties"`
+		} `json:"results"`
+	}
+	if err := httpclient.UnmarshalJSON(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	resources := make([]modules.Resource, 0, len(result.Results))
+	for _, page := range result.Results {
+		name := page.ID
+		for _, property := range page.Properties {
+			if property.Type != "title" || len(property.Title) == 0 {
+				continue
+			}
+			title := ""
+			for _, part := range property.Title {
+				title += part.PlainText
+			}
+			name = title
+		}
+		resources = append(resources, modules.Resource{
+			URI:      "notion://page/" + page.ID,
+			Name:     name,
+			MimeType: "application/json",
+		})
+	}
+	return resources, nil
+}
@@ -0,0 +1,69 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/modules"
+)
+
+// stubAPI sends the module's requests to handler instead of api.notion.com
+func stubAPI(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	target, _ := url.Parse(server.URL)
+
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		return original.RoundTrip(req)
+	})
+	t.Cleanup(func() {
+		http.DefaultTransport = original
+		server.Close()
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAppendBlocks_NotifiesOnlyThePage(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		want   []string
+	}{
+		{"page", `{"type":"page_id","page_id":"page-1"}`, []string{"notion://page/page-1"}},
+		{"nested block", `{"type":"block_id","block_id":"toggle-1"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"object":"list","results":[{"object":"block","id":"new-1","type":"paragraph","parent":` + tt.parent + `}]}`))
+			})
+
+			var notified []string
+			ctx := modules.WithResourceNotifier(context.Background(), func(uri string) {
+				notified = append(notified, uri)
+			})
+			_, err := appendBlocks(ctx, appendBlocksParams{
+				BlockID: "target",
+				Blocks:  []blockSpec{{Type: "paragraph", Content: "hello"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(notified) != len(tt.want) || (len(tt.want) > 0 && notified[0] != tt.want[0]) {
+				t.Errorf("notified %v, want %v", notified, tt.want)
+			}
+		})
+	}
+}
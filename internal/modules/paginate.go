@@ -0,0 +1,136 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
+)
+
+// Reserved params that make paginated tools fetch several pages. Like the
+// shaping params they are stripped before validation.
+const (
+	ParamAll      = "_all"
+	ParamMaxItems = "_max_items"
+)
+
+// pagingNote is appended to the description of paginated tools
+const pagingNote = " Pass _all=true or _max_items=N to fetch and merge several pages."
+
+// PagingSchema describes the pagination params; ValidateParams checks them
+// against it
+var PagingSchema = InputSchema{
+	Type: "object",
+	Properties: map[string]Property{
+		ParamAll: {
+			Type:        "boolean",
+			Description: fmt.Sprintf("全ページを取得（最大%d件）", httpclient.MaxPagedItems),
+		},
+		ParamMaxItems: {
+			Type:        "integer",
+			Description: "この件数が集まるまでページを取得",
+			Minimum:     Min(1),
+			Maximum:     Max(httpclient.MaxPagedItems),
+		},
+	},
+}
+
+// WithPagination marks tools whose handlers fetch listings with
+// httpclient.Client.Paginate, so they accept _all and _max_items
+func (m ModuleDefinition) WithPagination(names ...string) ModuleDefinition {
+	paginated := make(map[string]bool, len(m.Paginated)+len(names))
+	for name := range m.Paginated {
+		paginated[name] = true
+	}
+	for _, name := range names {
+		paginated[name] = true
+	}
+
+	tools := make([]Tool, len(m.Tools))
+	for i, tool := range m.Tools {
+		if paginated[tool.Name] && !strings.HasSuffix(tool.Description, pagingNote) {
+			tool.Description += pagingNote
+		}
+		tools[i] = tool
+	}
+	m.Tools = tools
+	m.Paginated = paginated
+	return m
+}
+
+// splitPagingParams removes the pagination params from params. paging is nil
+// when the call asks for a single page.
+func splitPagingParams(module ModuleDefinition, toolName string, params map[string]interface{}) (map[string]interface{}, *httpclient.Paging, error) {
+	_, all := params[ParamAll]
+	_, maxItems := params[ParamMaxItems]
+	if !all && !maxItems {
+		return params, nil, nil
+	}
+
+	reserved := map[string]interface{}{}
+	rest := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if _, ok := PagingSchema.Properties[name]; ok {
+			reserved[name] = value
+		} else {
+			rest[name] = value
+		}
+	}
+	validated, err := ValidateParams(PagingSchema, reserved)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paging := &httpclient.Paging{}
+	if n, ok := validated[ParamMaxItems].(float64); ok {
+		paging.MaxItems = int(n)
+	} else if all, _ := validated[ParamAll].(bool); !all {
+		return rest, nil, nil
+	}
+
+	if !module.Paginated[toolName] {
+		names := sortedKeys(module.Paginated)
+		message := fmt.Sprintf("tool %s returns a single page", toolName)
+		if len(names) > 0 {
+			message += "; paginated tools: " + strings.Join(names, ", ")
+		}
+		return nil, nil, &ValidationError{Errors: []FieldError{{Field: ParamAll, Message: message}}}
+	}
+	return rest, paging, nil
+}
+
+// trackPaging reports the progress of paging through ReportProgress and
+// returns the context handlers should use
+func trackPaging(ctx context.Context, paging *httpclient.Paging) context.Context {
+	if paging == nil {
+		return ctx
+	}
+	total := paging.MaxItems
+	if total == 0 {
+		total = httpclient.MaxPagedItems
+	}
+	paging.Progress = func(items, pages int) {
+		ReportProgress(ctx, float64(items), float64(total), fmt.Sprintf("Fetched %d items in %d pages", items, pages))
+	}
+	return httpclient.WithPaging(ctx, paging)
+}
+
+// pagingSummary tells the model what a paginated call fetched and which
+// limit stopped it
+func pagingSummary(paging *httpclient.Paging) string {
+	if !paging.Truncated {
+		return fmt.Sprintf("Fetched all %d items in %d pages.", paging.Items, paging.Pages)
+	}
+	switch {
+	case paging.MaxItems > 0 && paging.Items >= paging.MaxItems:
+		return fmt.Sprintf("Fetched %d items in %d pages and stopped at _max_items=%d; more remain. Raise _max_items to fetch more.",
+			paging.Items, paging.Pages, paging.MaxItems)
+	case paging.Items >= httpclient.MaxPagedItems:
+		return fmt.Sprintf("Fetched %d items in %d pages and stopped at the limit of %d items; more remain. Narrow the query.",
+			paging.Items, paging.Pages, httpclient.MaxPagedItems)
+	default:
+		return fmt.Sprintf("Fetched %d items in %d pages and stopped at the limit of %d pages; more remain. Narrow the query or use a larger page size.",
+			paging.Items, paging.Pages, httpclient.MaxPages)
+	}
+}
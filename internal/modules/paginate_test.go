@@ -0,0 +1,115 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
+)
+
+// pagedModule lists 7 records, 3 per page, the way Airtable pages with
+// offset
+func pagedModule(t *testing.T) ModuleDefinition {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var records []int
+		for i := n*3 + 1; i <= 7 && i <= n*3+3; i++ {
+			records = append(records, i)
+		}
+		page := map[string]interface{}{"records": records}
+		if (n+1)*3 < 7 {
+			page["offset"] = strconv.Itoa(n + 1)
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	client := httpclient.New()
+	list := func(ctx context.Context, params map[string]interface{}) (string, error) {
+		body, err := client.Paginate(ctx, httpclient.PageRequest{Method: "GET", URL: server.URL}, nil, httpclient.OffsetToken("records"))
+		return string(body), err
+	}
+	return ModuleDefinition{
+		Name:        "paged",
+		Description: "Pagination test module",
+		Tools: []Tool{
+			{Name: "list", Description: "List records.", InputSchema: InputSchema{Type: "object"}},
+			{Name: "single", Description: "List records without paging.", InputSchema: InputSchema{Type: "object"}},
+		},
+		Handlers: map[string]ToolHandler{"list": list, "single": list},
+	}.WithPagination("list")
+}
+
+func records(t *testing.T, result *ToolCallResult) []int {
+	t.Helper()
+	var page struct {
+		Records []int `json:"records"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &page); err != nil {
+		t.Fatalf("result %q: %v", result.Content[0].Text, err)
+	}
+	return page.Records
+}
+
+func TestPaginate_AllMergesPages(t *testing.T) {
+	result := callTool(t, pagedModule(t), "list", map[string]interface{}{"_all": true})
+
+	if got := records(t, result); len(got) != 7 || got[6] != 7 {
+		t.Fatalf("records = %v", got)
+	}
+	if len(result.Content) != 2 || result.Content[1].Text != "Fetched all 7 items in 3 pages." {
+		t.Errorf("unexpected notes: %+v", result.Content[1:])
+	}
+}
+
+func TestPaginate_MaxItems(t *testing.T) {
+	result := callTool(t, pagedModule(t), "list", map[string]interface{}{"_max_items": 4})
+
+	if got := records(t, result); len(got) != 4 {
+		t.Fatalf("records = %v", got)
+	}
+	// The note names the limit that stopped the paging
+	if len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "stopped at _max_items=4; more remain") {
+		t.Errorf("expected a truncation note, got %+v", result.Content[1:])
+	}
+}
+
+func TestPaginate_FirstPageByDefault(t *testing.T) {
+	module := pagedModule(t)
+	for _, params := range []map[string]interface{}{nil, {"_all": false}} {
+		result := callTool(t, module, "list", params)
+		if got := records(t, result); len(got) != 3 || len(result.Content) != 1 {
+			t.Errorf("params %v: records = %v, content = %+v", params, got, result.Content)
+		}
+	}
+}
+
+func TestPaginate_RejectedByUnpagedTools(t *testing.T) {
+	result := callTool(t, pagedModule(t), "single", map[string]interface{}{"_all": true})
+
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "tool single returns a single page; paginated tools: list") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestPaginate_InvalidMaxItems(t *testing.T) {
+	result := callTool(t, pagedModule(t), "list", map[string]interface{}{"_max_items": 5000})
+
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "_max_items") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestPaginate_DescriptionMentionsPaging(t *testing.T) {
+	for _, tool := range pagedModule(t).Tools {
+		if mentions := strings.Contains(tool.Description, "_all"); mentions != (tool.Name == "list") {
+			t.Errorf("tool %s: description %q", tool.Name, tool.Description)
+		}
+	}
+}
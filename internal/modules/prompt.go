@@ -0,0 +1,103 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prompt is a parameterized workflow a module offers to the user
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument is an argument of a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptMessage is a message of a rendered prompt
+type PromptMessage struct {
+	Role    string       `json:"role"`
+	Content ContentBlock `json:"content"`
+}
+
+// PromptResult is a rendered prompt
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptHandler renders a prompt with the given arguments
+type PromptHandler func(ctx context.Context, args map[string]string) (*PromptResult, error)
+
+// ErrPromptNotFound is returned for unknown prompt names
+var ErrPromptNotFound = errors.New("prompt not found")
+
+// NameSeparator joins a module name with one of its prompt names, e.g.
+// "jira__triage_issue", or with a tool name when tools are exposed directly
+const NameSeparator = "__"
+
+// ListPrompts returns the prompts of all modules, named module__prompt
+func (r *Registry) ListPrompts() []Prompt {
+	prompts := make([]Prompt, 0)
+	for _, module := range r.Modules() {
+		for _, prompt := range module.Prompts {
+			prompt.Name = module.Name + NameSeparator + prompt.Name
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// lookupPrompt resolves a module__prompt name
+func (r *Registry) lookupPrompt(name string) (ModuleDefinition, Prompt, bool) {
+	moduleName, promptName, ok := strings.Cut(name, NameSeparator)
+	if !ok {
+		return ModuleDefinition{}, Prompt{}, false
+	}
+	module, ok := r.Lookup(moduleName)
+	if !ok {
+		return ModuleDefinition{}, Prompt{}, false
+	}
+	for _, prompt := range module.Prompts {
+		if prompt.Name == promptName {
+			return module, prompt, true
+		}
+	}
+	return ModuleDefinition{}, Prompt{}, false
+}
+
+// GetPrompt renders a prompt after checking its required arguments
+func (r *Registry) GetPrompt(ctx context.Context, name string, args map[string]string) (*PromptResult, error) {
+	module, prompt, ok := r.lookupPrompt(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+	handler, ok := module.PromptHandlers[prompt.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+	}
+
+	for _, arg := range prompt.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return nil, fmt.Errorf("missing required argument: %s", arg.Name)
+		}
+	}
+	return handler(ctx, args)
+}
+
+// UserMessage returns a prompt message from the user with text content
+func UserMessage(text string) PromptMessage {
+	return PromptMessage{Role: "user", Content: TextContent(text)}
+}
+
+// ResourceMessage returns a prompt message from the user embedding a resource
+func ResourceMessage(contents ResourceContents) PromptMessage {
+	return PromptMessage{Role: "user", Content: EmbeddedResource(contents)}
+}
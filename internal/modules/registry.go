@@ -1,61 +1,273 @@
 package modules
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
 	"github.com/shibaleo/go-mcp-dev/internal/observability"
 )
 
-// Registry holds all module definitions
-var Registry = make(map[string]ModuleDefinition)
+// Registry holds the module definitions a server exposes. It is safe for
+// concurrent use, so modules can be added and removed while serving.
+type Registry struct {
+	mu        sync.RWMutex
+	modules   map[string]ModuleDefinition
+	listeners map[int]func(ChangeEvent)
+	nextID    int
 
-// Register adds a module to the registry
-func Register(module ModuleDefinition) {
-	Registry[module.Name] = module
+	// version counts changes; the search index is rebuilt when it is stale
+	version      uint64
+	index        *searchIndex
+	indexVersion uint64
 }
 
-// MetaTools returns the two meta tools for lazy loading
-func MetaTools() []Tool {
+// ChangeKind tells what happened to a module
+type ChangeKind int
+
+const (
+	ModuleAdded ChangeKind = iota
+	ModuleRemoved
+)
+
+func (k ChangeKind) String() string {
+	if k == ModuleRemoved {
+		return "removed"
+	}
+	return "added"
+}
+
+// ChangeEvent is delivered to subscribers after a module is added or removed
+type ChangeEvent struct {
+	Kind   ChangeKind
+	Module ModuleDefinition
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		modules:   make(map[string]ModuleDefinition),
+		listeners: make(map[int]func(ChangeEvent)),
+	}
+}
+
+// Register adds a module after checking it with CheckModule. A module with
+// the same name must be unregistered first.
+func (r *Registry) Register(module ModuleDefinition) error {
+	if err := CheckModule(module); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if _, dup := r.modules[module.Name]; dup {
+		r.mu.Unlock()
+		return fmt.Errorf("module %s is already registered", module.Name)
+	}
+	r.modules[module.Name] = module
+	r.version++
+	listeners := r.snapshotListeners()
+	r.mu.Unlock()
+
+	if module.RateLimit != nil {
+		httpclient.SetRateLimit(module.Name, *module.RateLimit)
+	}
+
+	notify(listeners, ChangeEvent{Kind: ModuleAdded, Module: module})
+	return nil
+}
+
+// Unregister removes a module and reports whether it was registered.
+// Tool calls already running in the module are not interrupted.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	module, ok := r.modules[name]
+	if !ok {
+		r.mu.Unlock()
+		return false
+	}
+	delete(r.modules, name)
+	r.version++
+	listeners := r.snapshotListeners()
+	r.mu.Unlock()
+
+	if module.RateLimit != nil {
+		httpclient.RemoveRateLimit(name)
+	}
+
+	notify(listeners, ChangeEvent{Kind: ModuleRemoved, Module: module})
+	return true
+}
+
+// Lookup returns the module registered under name
+func (r *Registry) Lookup(name string) (ModuleDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	module, ok := r.modules[name]
+	return module, ok
+}
+
+// Modules returns the registered modules ordered by name
+func (r *Registry) Modules() []ModuleDefinition {
+	r.mu.RLock()
+	list := make([]ModuleDefinition, 0, len(r.modules))
+	for _, module := range r.modules {
+		list = append(list, module)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SearchTools ranks the tools of every module, or only of module when it is
+// set, against a natural-language query. limit defaults to
+// DefaultSearchLimit and is capped at MaxSearchLimit.
+func (r *Registry) SearchTools(query, module string, limit int) []ToolMatch {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+	return r.toolIndex().search(query, module, limit)
+}
+
+// toolIndex returns the search index, rebuilding it after the registry changed
+func (r *Registry) toolIndex() *searchIndex {
+	r.mu.RLock()
+	idx, version := r.index, r.version
+	fresh := idx != nil && r.indexVersion == version
+	r.mu.RUnlock()
+	if fresh {
+		return idx
+	}
+
+	idx = buildSearchIndex(r.Modules())
+	r.mu.Lock()
+	if r.version == version {
+		r.index, r.indexVersion = idx, version
+	}
+	r.mu.Unlock()
+	return idx
+}
+
+// Subscribe calls fn after every change to the registry until the returned
+// function is called. fn runs on the goroutine that made the change and
+// must not block.
+func (r *Registry) Subscribe(fn func(ChangeEvent)) (unsubscribe func()) {
+	r.mu.Lock()
+	id := r.nextID
+	r.nextID++
+	r.listeners[id] = fn
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.listeners, id)
+		r.mu.Unlock()
+	}
+}
+
+// snapshotListeners copies the listeners so they run without the lock held.
+// The caller must hold r.mu.
+func (r *Registry) snapshotListeners() []func(ChangeEvent) {
+	ids := make([]int, 0, len(r.listeners))
+	for id := range r.listeners {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	listeners := make([]func(ChangeEvent), 0, len(ids))
+	for _, id := range ids {
+		listeners = append(listeners, r.listeners[id])
+	}
+	return listeners
+}
+
+func notify(listeners []func(ChangeEvent), event ChangeEvent) {
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// MetaTools returns the meta tools for lazy loading. Their descriptions
+// list the modules that are actually registered.
+func (r *Registry) MetaTools() []Tool {
+	registered := r.Modules()
+	names := make([]string, 0, len(registered))
+	var catalog strings.Builder
+	for _, module := range registered {
+		names = append(names, module.Name)
+		fmt.Fprintf(&catalog, "- %s: %s\n", module.Name, module.Description)
+	}
+
 	return []Tool{
 		{
-			Name: "get_module_schema",
+			Name:        "get_module_schema",
 			Description: `モジュールのツール定義を取得。重要: 各モジュールにつき1セッション1回のみ呼び出すこと。スキーマは会話履歴にキャッシュされるため、同一モジュールへの2回目以降の呼び出しはcall_module_toolを直接使用すること。`,
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
 					"module": {
 						Type:        "string",
-						Description: "モジュール名(google_calendar, microsoft_todo, notion, rag, supabase, jira, confluence, github)",
+						Description: "モジュール名(" + strings.Join(names, ", ") + ")",
+						Enum:        names,
+					},
+					"format": {
+						Type:        "string",
+						Description: "出力形式。json: 完全なJSON、json_min: 空白なしJSON、typescript: TypeScript風のシグネチャ（最もトークンが少ない）、markdown: パラメータ表",
+						Enum:        SchemaFormats,
+						Default:     string(SchemaFormatJSON),
+					},
+					"tools": {
+						Type:        "array",
+						Description: "取得するツール名（省略時は全ツール）",
+						Items:       &Property{Type: "string"},
 					},
 				},
 				Required: []string{"module"},
 			},
+			Annotations: &ToolAnnotations{
+				Title:          "Get module schema",
+				ReadOnlyHint:   boolPtr(true),
+				IdempotentHint: boolPtr(true),
+				OpenWorldHint:  boolPtr(false),
+			},
 		},
 		{
 			Name: "call_module_tool",
 			Description: `モジュールのツールを呼び出す。
 
 【利用可能モジュール】
-- google_calendar: 予定の取得・作成
-- microsoft_todo: タスク管理
-- notion: ページ・データベース操作
-- rag: ドキュメント検索(セマンティック検索、キーワード検索)
-- supabase: DB操作、マイグレーション、ログ、ストレージ
-- jira: Issue/Project操作（検索、作成、更新、コメント）
-- confluence: Wiki操作（スペース、ページ、検索、ラベル）
-- github: リポジトリ、Issue、PR、Actions、検索
-
+` + catalog.String() + `
 【使い方】
 1. get_module_schema(module) でツール一覧とパラメータを確認
-2. call_module_tool(module, tool_name, params) で実行`,
+2. call_module_tool(module, tool_name, params) で実行
+
+【結果の整形】
+paramsに次の予約パラメータを加えると、結果を絞り込み・整形できる。
+- _fields: 残すフィールドのパス（カンマ区切り、例: "number,title,user.login"）。配列は要素ごとに適用
+- _format: json | yaml | markdown | csv
+- _max_chars: 最大文字数。超えた場合は続きを読むための _offset を案内する
+- _offset: 続きを読む開始位置
+- _view: compact | raw。要約に対応したツールはデフォルトで要約を返す。API応答全体が必要なときは raw
+
+【ページング】
+説明に _all の記載があるツールは、次の予約パラメータで複数ページをまとめて取得できる（最大1000件）。
+- _all: true で全ページを取得
+- _max_items: 取得する最大件数`,
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
 					"module": {
 						Type:        "string",
 						Description: "モジュール名",
+						Enum:        names,
 					},
 					"tool_name": {
 						Type:        "string",
@@ -63,18 +275,59 @@ func MetaTools() []Tool {
 					},
 					"params": {
 						Type:        "object",
-						Description: "ツールパラメータ",
+						Description: "ツールパラメータ（_fields・_format・_max_chars・_offset・_view で結果を整形）",
 					},
 				},
 				Required: []string{"module", "tool_name"},
 			},
+			Annotations: &ToolAnnotations{
+				Title:         "Call module tool",
+				OpenWorldHint: boolPtr(true),
+			},
+		},
+		{
+			Name:        "search_tools",
+			Description: `全モジュールのツールを自然言語で検索し、上位候補のパラメータ要約を返す。どのモジュールのツールを使うべきか分からないときは、get_module_schemaより先にこれを使うこと。結果のmoduleとtoolをそのままcall_module_toolに渡せる。`,
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"query": {
+						Type:        "string",
+						Description: "やりたいこと（例: \"list open pull requests\", \"create a jira issue\"）",
+					},
+					"module": {
+						Type:        "string",
+						Description: "検索対象をこのモジュールに絞る（省略時は全モジュール）",
+						Enum:        names,
+					},
+					"limit": {
+						Type:        "integer",
+						Description: "返す候補の数",
+						Default:     DefaultSearchLimit,
+						Minimum:     Min(1),
+						Maximum:     Max(MaxSearchLimit),
+					},
+				},
+				Required: []string{"query"},
+			},
+			Annotations: &ToolAnnotations{
+				Title:          "Search tools",
+				ReadOnlyHint:   boolPtr(true),
+				IdempotentHint: boolPtr(true),
+				OpenWorldHint:  boolPtr(false),
+			},
 		},
 	}
 }
 
-// GetModuleSchema returns the schema for a module
-func GetModuleSchema(moduleName string) (*ToolCallResult, error) {
-	module, ok := Registry[moduleName]
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// GetModuleSchema returns the schema for a module in the format opts
+// selects, restricted to opts.Tools when that is set
+func (r *Registry) GetModuleSchema(moduleName string, opts SchemaOptions) (*ToolCallResult, error) {
+	module, ok := r.Lookup(moduleName)
 	if !ok {
 		return &ToolCallResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown module: %s", moduleName)}},
@@ -82,31 +335,45 @@ func GetModuleSchema(moduleName string) (*ToolCallResult, error) {
 		}, nil
 	}
 
-	schema := struct {
-		Module      string `json:"module"`
-		Description string `json:"description"`
-		Tools       []Tool `json:"tools"`
-	}{
-		Module:      moduleName,
-		Description: module.Description,
-		Tools:       module.Tools,
+	tools := module.Tools
+	if len(opts.Tools) > 0 {
+		tools = make([]Tool, 0, len(opts.Tools))
+		var unknown []string
+		for _, name := range opts.Tools {
+			if tool, ok := findTool(module, name); ok {
+				tools = append(tools, tool)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			available := make([]string, len(module.Tools))
+			for i, tool := range module.Tools {
+				available[i] = tool.Name
+			}
+			return &ToolCallResult{
+				Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown tools in module %s: %s (available: %s)",
+					moduleName, strings.Join(unknown, ", "), strings.Join(available, ", "))}},
+				IsError: true,
+			}, nil
+		}
 	}
 
-	jsonBytes, err := json.MarshalIndent(schema, "", "  ")
+	text, err := renderSchema(module, tools, opts.Format)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ToolCallResult{
-		Content: []ContentBlock{{Type: "text", Text: string(jsonBytes)}},
+		Content: []ContentBlock{{Type: "text", Text: text}},
 	}, nil
 }
 
 // CallModuleTool executes a tool in a module
-func CallModuleTool(moduleName, toolName string, params map[string]interface{}) (*ToolCallResult, error) {
+func (r *Registry) CallModuleTool(ctx context.Context, moduleName, toolName string, params map[string]interface{}) (*ToolCallResult, error) {
 	start := time.Now()
 
-	module, ok := Registry[moduleName]
+	module, ok := r.Lookup(moduleName)
 	if !ok {
 		return &ToolCallResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown module: %s", moduleName)}},
@@ -114,15 +381,43 @@ func CallModuleTool(moduleName, toolName string, params map[string]interface{})
 		}, nil
 	}
 
-	handler, ok := module.Handlers[toolName]
-	if !ok {
+	params, shape, err := splitShapeParams(params)
+	if err != nil {
+		observability.LogToolCall(moduleName, toolName, time.Since(start).Milliseconds(), "error", err.Error())
+		return validationErrorResult(err), nil
+	}
+	params, paging, err := splitPagingParams(module, toolName, params)
+	if err != nil {
+		observability.LogToolCall(moduleName, toolName, time.Since(start).Milliseconds(), "error", err.Error())
+		return validationErrorResult(err), nil
+	}
+	ctx = trackPaging(ctx, paging)
+
+	if tool, ok := findTool(module, toolName); ok {
+		validated, err := ValidateParams(tool.InputSchema, params)
+		if err != nil {
+			observability.LogToolCall(moduleName, toolName, time.Since(start).Milliseconds(), "error", err.Error())
+			return validationErrorResult(err), nil
+		}
+		params = validated
+	}
+
+	var result *ToolCallResult
+	if handler, ok := module.Handlers[toolName]; ok {
+		var text string
+		text, err = handler(ctx, params)
+		result = &ToolCallResult{Content: []ContentBlock{{Type: "text", Text: text}}}
+	} else if handler, ok := module.ResultHandlers[toolName]; ok {
+		result, err = handler(ctx, params)
+		if err == nil && result == nil {
+			err = fmt.Errorf("tool %s returned no result", toolName)
+		}
+	} else {
 		return &ToolCallResult{
 			Content: []ContentBlock{{Type: "text", Text: fmt.Sprintf("Unknown tool: %s in module %s", toolName, moduleName)}},
 			IsError: true,
 		}, nil
 	}
-
-	result, err := handler(params)
 	durationMs := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -133,8 +428,37 @@ func CallModuleTool(moduleName, toolName string, params map[string]interface{})
 		}, nil
 	}
 
+	tool, _ := findTool(module, toolName)
+	if tool.OutputSchema != nil && result.StructuredContent == nil {
+		result.StructuredContent = structuredFromText(result)
+	}
+	shape.apply(ctx, result, tool.OutputSchema, module.Compactors[toolName], params)
+	if paging != nil && paging.Pages > 0 {
+		result.Content = append(result.Content, TextContent(pagingSummary(paging)))
+	}
+
 	observability.LogToolCall(moduleName, toolName, durationMs, "success", "")
-	return &ToolCallResult{
-		Content: []ContentBlock{{Type: "text", Text: result}},
-	}, nil
+	return result, nil
+}
+
+func findTool(module ModuleDefinition, toolName string) (Tool, bool) {
+	for _, tool := range module.Tools {
+		if tool.Name == toolName {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// structuredFromText recovers structuredContent from a handler that returned
+// a JSON object as text. Anything else yields nil.
+func structuredFromText(result *ToolCallResult) interface{} {
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &object); err != nil {
+		return nil
+	}
+	return object
 }
@@ -0,0 +1,148 @@
+package modules
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// echoModule is the smallest consistent module
+var echoModule = ModuleDefinition{
+	Name:        "echo",
+	Description: "Echo test module",
+	Tools: []Tool{
+		{
+			Name:        "echo",
+			Description: "Echo back the input",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"message": {Type: "string", Description: "Message to echo"},
+				},
+			},
+		},
+	},
+	Handlers: map[string]ToolHandler{
+		"echo": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			msg, _ := params["message"].(string)
+			return "Echo: " + msg, nil
+		},
+	},
+}
+
+// newTestRegistry returns a fresh registry holding modules
+func newTestRegistry(t *testing.T, modules ...ModuleDefinition) *Registry {
+	t.Helper()
+	registry := NewRegistry()
+	for _, module := range modules {
+		if err := registry.Register(module); err != nil {
+			t.Fatalf("register %s: %v", module.Name, err)
+		}
+	}
+	return registry
+}
+
+// callTool calls a tool of module through a registry holding only module
+func callTool(t *testing.T, module ModuleDefinition, tool string, params map[string]interface{}) *ToolCallResult {
+	t.Helper()
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	result, err := newTestRegistry(t, module).CallModuleTool(context.Background(), module.Name, tool, params)
+	if err != nil {
+		t.Fatalf("call %s/%s: %v", module.Name, tool, err)
+	}
+	return result
+}
+
+func TestRegistry_RejectsDuplicateAndInconsistentModules(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(echoModule); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if err := registry.Register(echoModule); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("duplicate register err = %v", err)
+	}
+	if err := registry.Register(ModuleDefinition{Name: "Bad Name"}); err == nil {
+		t.Error("expected an inconsistent module to be rejected")
+	}
+	if _, ok := registry.Lookup("Bad Name"); ok {
+		t.Error("rejected module must not be registered")
+	}
+}
+
+func TestRegistry_UnregisterAndEvents(t *testing.T) {
+	registry := NewRegistry()
+	other := echoModule
+	other.Name = "other"
+
+	var events []string
+	unsubscribe := registry.Subscribe(func(event ChangeEvent) {
+		events = append(events, event.Kind.String()+" "+event.Module.Name)
+	})
+
+	registry.Register(echoModule)
+	registry.Register(other)
+	if !registry.Unregister("echo") {
+		t.Error("expected echo to be unregistered")
+	}
+	if registry.Unregister("echo") {
+		t.Error("second unregister should report false")
+	}
+	unsubscribe()
+	registry.Unregister("other")
+
+	want := []string{"added echo", "added other", "removed echo"}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if len(registry.Modules()) != 0 {
+		t.Errorf("modules = %v", registry.Modules())
+	}
+}
+
+func TestRegistry_ConcurrentUse(t *testing.T) {
+	registry := newTestRegistry(t, echoModule)
+	other := echoModule
+	other.Name = "other"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if registry.Register(other) == nil {
+					registry.Unregister("other")
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				registry.CallModuleTool(context.Background(), "echo", "echo", map[string]interface{}{})
+				registry.MetaTools()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMetaTools_ListRegisteredModules(t *testing.T) {
+	other := echoModule
+	other.Name = "other"
+	other.Description = "Other test module"
+	tools := newTestRegistry(t, echoModule, other).MetaTools()
+
+	if enum := tools[0].InputSchema.Properties["module"].Enum; len(enum) != 2 {
+		t.Errorf("module enum = %v", enum)
+	}
+	if !strings.Contains(tools[1].Description, "- other: Other test module\n") {
+		t.Errorf("call_module_tool description:\n%s", tools[1].Description)
+	}
+	if strings.Contains(tools[1].Description, "google_calendar") {
+		t.Error("description lists a module that is not registered")
+	}
+}
@@ -0,0 +1,142 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ResourceTemplate describes a family of resources addressed by a URI
+// template. Variables are written as {name}; the last one may span slashes.
+// Matched values are percent-decoded, so a slash within another variable is
+// written as %2F (e.g. a branch feature%2Fx).
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// Resource is a concrete resource a client can read
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceHandler reads a resource given the variables matched from its URI
+type ResourceHandler func(ctx context.Context, vars map[string]string) ([]ResourceContents, error)
+
+// ErrResourceNotFound is returned for URIs no module template matches
+var ErrResourceNotFound = errors.New("resource not found")
+
+var templateVarPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// MatchURITemplate matches uri against template and returns the
+// percent-decoded variables. A value with an invalid escape does not match.
+func MatchURITemplate(template, uri string) (map[string]string, bool) {
+	locs := templateVarPattern.FindAllStringSubmatchIndex(template, -1)
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	names := make([]string, 0, len(locs))
+	last := 0
+	for i, loc := range locs {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		if i == len(locs)-1 && loc[1] == len(template) {
+			pattern.WriteString("(.+)")
+		} else {
+			pattern.WriteString("([^/]+)")
+		}
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	match := regexp.MustCompile(pattern.String()).FindStringSubmatch(uri)
+	if match == nil {
+		return nil, false
+	}
+	vars := make(map[string]string, len(names))
+	for i, name := range names {
+		value, err := url.PathUnescape(match[i+1])
+		if err != nil {
+			return nil, false
+		}
+		vars[name] = value
+	}
+	return vars, true
+}
+
+// ResourceTemplates returns the resource templates of all modules
+func (r *Registry) ResourceTemplates() []ResourceTemplate {
+	templates := make([]ResourceTemplate, 0)
+	for _, module := range r.Modules() {
+		templates = append(templates, module.ResourceTemplates...)
+	}
+	return templates
+}
+
+// ListResources returns the concrete resources of all modules that list them.
+// A failing module is skipped so the others can still be listed.
+func (r *Registry) ListResources(ctx context.Context) []Resource {
+	resources := make([]Resource, 0)
+	for _, module := range r.Modules() {
+		if module.ListResources == nil {
+			continue
+		}
+		list, err := module.ListResources(ctx)
+		if err != nil {
+			log.Printf("Failed to list resources: module=%s err=%v", module.Name, err)
+			continue
+		}
+		resources = append(resources, list...)
+	}
+	return resources
+}
+
+// ReadResource reads a resource through the module owning its URI scheme
+func (r *Registry) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	scheme, _, ok := strings.Cut(uri, "://")
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	module, ok := r.Lookup(scheme)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	for _, template := range module.ResourceTemplates {
+		vars, ok := MatchURITemplate(template.URITemplate, uri)
+		if !ok {
+			continue
+		}
+		handler, ok := module.ResourceHandlers[template.Name]
+		if !ok {
+			break
+		}
+		return handler(ctx, vars)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+}
+
+type resourceNotifierKey struct{}
+
+// WithResourceNotifier returns a context on which NotifyResourceUpdated calls fn
+func WithResourceNotifier(ctx context.Context, fn func(uri string)) context.Context {
+	return context.WithValue(ctx, resourceNotifierKey{}, fn)
+}
+
+// NotifyResourceUpdated tells subscribed clients that a resource changed.
+// Tools call it after modifying an entity that is exposed as a resource.
+func NotifyResourceUpdated(ctx context.Context, uri string) {
+	if fn, ok := ctx.Value(resourceNotifierKey{}).(func(uri string)); ok {
+		fn(uri)
+	}
+}
@@ -0,0 +1,236 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SchemaFormat selects how GetModuleSchema renders tool definitions
+type SchemaFormat string
+
+const (
+	// SchemaFormatJSON is the full tool list as indented JSON
+	SchemaFormatJSON SchemaFormat = "json"
+	// SchemaFormatJSONMin is the full tool list as JSON without whitespace
+	SchemaFormatJSONMin SchemaFormat = "json_min"
+	// SchemaFormatTypeScript lists tools as TypeScript-like signatures
+	SchemaFormatTypeScript SchemaFormat = "typescript"
+	// SchemaFormatMarkdown lists tools with a parameter table each
+	SchemaFormatMarkdown SchemaFormat = "markdown"
+)
+
+// SchemaFormats are the accepted SchemaFormat values
+var SchemaFormats = []string{
+	string(SchemaFormatJSON), string(SchemaFormatJSONMin),
+	string(SchemaFormatTypeScript), string(SchemaFormatMarkdown),
+}
+
+// ParseSchemaFormat parses a format name; the empty string means SchemaFormatJSON
+func ParseSchemaFormat(s string) (SchemaFormat, error) {
+	if s == "" {
+		return SchemaFormatJSON, nil
+	}
+	if !contains(SchemaFormats, s) {
+		return "", fmt.Errorf("unknown schema format %q (want %s)", s, strings.Join(SchemaFormats, ", "))
+	}
+	return SchemaFormat(s), nil
+}
+
+// SchemaOptions selects the format of GetModuleSchema and, when Tools is
+// set, the tools it includes
+type SchemaOptions struct {
+	Format SchemaFormat
+	Tools  []string
+}
+
+func renderSchema(module ModuleDefinition, tools []Tool, format SchemaFormat) (string, error) {
+	switch format {
+	case SchemaFormatTypeScript:
+		return renderTypeScript(module, tools), nil
+	case SchemaFormatMarkdown:
+		return renderMarkdown(module, tools), nil
+	}
+
+	schema := struct {
+		Module      string `json:"module"`
+		Description string `json:"description"`
+		Tools       []Tool `json:"tools"`
+	}{
+		Module:      module.Name,
+		Description: module.Description,
+		Tools:       tools,
+	}
+
+	var jsonBytes []byte
+	var err error
+	if format == SchemaFormatJSONMin {
+		jsonBytes, err = json.Marshal(schema)
+	} else {
+		jsonBytes, err = json.MarshalIndent(schema, "", "  ")
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
+
+// renderTypeScript writes one commented function signature per tool, e.g.
+//
+//	/** List pull requests in a repository. */
+//	function list_prs(params: {
+//	  owner: string; // Repository owner
+//	  state?: "open" | "closed" | "all"; // default "open"
+//	})
+func renderTypeScript(module ModuleDefinition, tools []Tool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Module %s: %s\n", module.Name, module.Description)
+
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "\n/** %s */\n", oneLine(tool.Description))
+		fmt.Fprintf(&b, "function %s(params: ", tool.Name)
+		writeTSObject(&b, tool.InputSchema.Properties, tool.InputSchema.Required, "")
+		b.WriteString(")")
+		if tool.OutputSchema != nil {
+			b.WriteString(": ")
+			writeTSObject(&b, tool.OutputSchema.Properties, tool.OutputSchema.Required, "")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeTSObject(b *strings.Builder, props map[string]Property, required []string, indent string) {
+	if len(props) == 0 {
+		b.WriteString("{}")
+		return
+	}
+
+	b.WriteString("{\n")
+	for _, name := range sortedKeys(props) {
+		prop := props[name]
+		optional := "?"
+		if contains(required, name) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "%s  %s%s: ", indent, name, optional)
+		writeTSType(b, prop, indent+"  ")
+		b.WriteString(";")
+		if comment := propertyNotes(prop); comment != "" {
+			b.WriteString(" // " + comment)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+func writeTSType(b *strings.Builder, prop Property, indent string) {
+	switch {
+	case len(prop.Enum) > 0:
+		quoted := make([]string, len(prop.Enum))
+		for i, value := range prop.Enum {
+			quoted[i] = fmt.Sprintf("%q", value)
+		}
+		b.WriteString(strings.Join(quoted, " | "))
+	case prop.Type == "array":
+		if prop.Items == nil {
+			b.WriteString("unknown[]")
+			return
+		}
+		if len(prop.Items.Enum) > 1 {
+			b.WriteString("(")
+			writeTSType(b, *prop.Items, indent)
+			b.WriteString(")[]")
+			return
+		}
+		writeTSType(b, *prop.Items, indent)
+		b.WriteString("[]")
+	case prop.Type == "object" && len(prop.Properties) > 0:
+		writeTSObject(b, prop.Properties, prop.Required, indent)
+	case prop.Type == "object":
+		b.WriteString("Record<string, unknown>")
+	case prop.Type == "integer" || prop.Type == "number":
+		b.WriteString("number")
+	case prop.Type == "string" || prop.Type == "boolean":
+		b.WriteString(prop.Type)
+	default:
+		b.WriteString("unknown")
+	}
+}
+
+// renderMarkdown writes a section per tool with a table of its parameters.
+// Nested parameters are flattened into dotted names such as sort[].field.
+func renderMarkdown(module ModuleDefinition, tools []Tool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", module.Name, module.Description)
+
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", tool.Name, tool.Description)
+		if len(tool.InputSchema.Properties) == 0 {
+			b.WriteString("\nNo parameters.\n")
+			continue
+		}
+		b.WriteString("\n| Param | Type | Required | Notes |\n|---|---|---|---|\n")
+		writeMarkdownRows(&b, tool.InputSchema.Properties, tool.InputSchema.Required, "")
+	}
+	return b.String()
+}
+
+func writeMarkdownRows(b *strings.Builder, props map[string]Property, required []string, prefix string) {
+	for _, name := range sortedKeys(props) {
+		prop := props[name]
+		req := ""
+		if contains(required, name) {
+			req = "yes"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", prefix+name, markdownCell(compactType(prop)), req, markdownCell(propertyNotes(prop)))
+
+		switch {
+		case prop.Type == "object":
+			writeMarkdownRows(b, prop.Properties, prop.Required, prefix+name+".")
+		case prop.Type == "array" && prop.Items != nil:
+			writeMarkdownRows(b, prop.Items.Properties, prop.Items.Required, prefix+name+"[].")
+		}
+	}
+}
+
+// propertyNotes joins a property's description with the constraints the
+// compact formats do not otherwise show
+func propertyNotes(prop Property) string {
+	var notes []string
+	desc := oneLine(prop.Description)
+	if desc != "" {
+		notes = append(notes, desc)
+	}
+	// Many descriptions already end in "Default: x"
+	if prop.Default != nil && !strings.Contains(strings.ToLower(desc), "default") {
+		data, _ := json.Marshal(prop.Default)
+		notes = append(notes, "default "+string(data))
+	}
+	if prop.Format != "" {
+		notes = append(notes, "format "+prop.Format)
+	}
+	switch {
+	case prop.Minimum != nil && prop.Maximum != nil:
+		notes = append(notes, formatNumber(*prop.Minimum)+".."+formatNumber(*prop.Maximum))
+	case prop.Minimum != nil:
+		notes = append(notes, ">= "+formatNumber(*prop.Minimum))
+	case prop.Maximum != nil:
+		notes = append(notes, "<= "+formatNumber(*prop.Maximum))
+	}
+	if prop.MinItems != nil {
+		notes = append(notes, fmt.Sprintf("min %d items", *prop.MinItems))
+	}
+	if prop.MaxItems != nil {
+		notes = append(notes, fmt.Sprintf("max %d items", *prop.MaxItems))
+	}
+	return strings.Join(notes, "; ")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
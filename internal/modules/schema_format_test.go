@@ -0,0 +1,108 @@
+package modules
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func moduleSchema(t *testing.T, module string, opts SchemaOptions) *ToolCallResult {
+	t.Helper()
+	result, err := newTestRegistry(t, schemaModule, typedToolModule).GetModuleSchema(module, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestGetModuleSchema_JSONSchemaKeywords(t *testing.T) {
+
+	var schema struct {
+		Tools []struct {
+			InputSchema struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	text := moduleSchema(t, "schema", SchemaOptions{}).Content[0].Text
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	props := schema.Tools[0].InputSchema.Properties
+
+	if enum, _ := props["state"]["enum"].([]interface{}); len(enum) != 2 || props["state"]["default"] != "open" {
+		t.Errorf("state = %v", props["state"])
+	}
+	if props["limit"]["minimum"] != 1.0 || props["limit"]["maximum"] != 100.0 || props["limit"]["default"] != 30.0 {
+		t.Errorf("limit = %v", props["limit"])
+	}
+	if props["since"]["format"] != "date-time" {
+		t.Errorf("since = %v", props["since"])
+	}
+	if items, _ := props["labels"]["items"].(map[string]interface{}); items["type"] != "string" || props["labels"]["minItems"] != 1.0 {
+		t.Errorf("labels = %v", props["labels"])
+	}
+
+	items, _ := props["sort"]["items"].(map[string]interface{})
+	nested, _ := items["properties"].(map[string]interface{})
+	desc, _ := nested["desc"].(map[string]interface{})
+	if desc["default"] != false {
+		t.Errorf("false default must survive omitempty: %v", desc)
+	}
+	if required, _ := items["required"].([]interface{}); len(required) != 1 {
+		t.Errorf("sort items = %v", items)
+	}
+	if _, ok := props["since"]["description"]; ok {
+		t.Errorf("empty description should be omitted: %v", props["since"])
+	}
+}
+
+func TestGetModuleSchema_TypeScript(t *testing.T) {
+	text := moduleSchema(t, "schema", SchemaOptions{Format: SchemaFormatTypeScript}).Content[0].Text
+
+	for _, want := range []string{
+		"// Module schema: Schema test module",
+		"/** List items */\nfunction list(params: {",
+		`  state?: "open" | "closed"; // default "open"`,
+		"  limit?: number; // default 30; 1..100",
+		"  labels?: string[]; // min 1 items",
+		"  sort?: {\n    desc?: boolean; // default false\n    field: string;\n  }[];",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestGetModuleSchema_Markdown(t *testing.T) {
+	text := moduleSchema(t, "schema", SchemaOptions{Format: SchemaFormatMarkdown}).Content[0].Text
+
+	for _, want := range []string{
+		"# schema\n\nSchema test module\n",
+		"## list\n",
+		"| state | open\\|closed |  | default \"open\" |",
+		"| sort[].field | string | yes |  |",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestGetModuleSchema_JSONFormatsAndToolFilter(t *testing.T) {
+	full := moduleSchema(t, "typedtool", SchemaOptions{Format: SchemaFormatJSON}).Content[0].Text
+	minified := moduleSchema(t, "typedtool", SchemaOptions{Format: SchemaFormatJSONMin}).Content[0].Text
+	if strings.Contains(minified, "\n") || len(minified) >= len(full) {
+		t.Errorf("json_min is not minified:\n%s", minified)
+	}
+
+	filtered := moduleSchema(t, "typedtool", SchemaOptions{Format: SchemaFormatJSONMin, Tools: []string{"echo"}}).Content[0].Text
+	if !strings.Contains(filtered, `"name":"echo"`) || strings.Contains(filtered, `"name":"greet"`) {
+		t.Errorf("tools filter not applied:\n%s", filtered)
+	}
+
+	result := moduleSchema(t, "typedtool", SchemaOptions{Format: SchemaFormatJSON, Tools: []string{"nope"}})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "available: greet, echo, fail") {
+		t.Errorf("unknown tool result = %+v", result)
+	}
+}
@@ -0,0 +1,250 @@
+package modules
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ToolMatch is a tool ranked by SearchTools
+type ToolMatch struct {
+	Module string
+	Tool   Tool
+	Score  float64
+}
+
+// BM25 parameters: k1 saturates repeated terms, b normalizes document length
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights, applied by repeating the field's terms in the document
+const (
+	weightToolName   = 3
+	weightModuleName = 2
+	weightText       = 1
+)
+
+// DefaultSearchLimit and MaxSearchLimit bound the matches SearchTools returns
+const (
+	DefaultSearchLimit = 5
+	MaxSearchLimit     = 20
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "by": true,
+	"for": true, "from": true, "i": true, "in": true, "is": true, "it": true, "me": true,
+	"my": true, "of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+type searchDoc struct {
+	module string
+	tool   Tool
+	terms  map[string]int
+	length int
+}
+
+// searchIndex is a BM25 index over tool names, descriptions and property
+// names and descriptions
+type searchIndex struct {
+	docs      []searchDoc
+	docFreq   map[string]int
+	avgLength float64
+}
+
+func buildSearchIndex(list []ModuleDefinition) *searchIndex {
+	idx := &searchIndex{docFreq: map[string]int{}}
+	total := 0
+
+	for _, module := range list {
+		for _, tool := range module.Tools {
+			doc := searchDoc{module: module.Name, tool: tool, terms: map[string]int{}}
+			add := func(text string, weight int) {
+				for _, term := range tokenize(text) {
+					doc.terms[term] += weight
+					doc.length += weight
+				}
+			}
+			add(tool.Name, weightToolName)
+			add(module.Name, weightModuleName)
+			add(tool.Description, weightText)
+			addProperties(add, tool.InputSchema.Properties)
+
+			for term := range doc.terms {
+				idx.docFreq[term]++
+			}
+			total += doc.length
+			idx.docs = append(idx.docs, doc)
+		}
+	}
+
+	if len(idx.docs) > 0 {
+		idx.avgLength = float64(total) / float64(len(idx.docs))
+	}
+	return idx
+}
+
+func addProperties(add func(string, int), props map[string]Property) {
+	for name, prop := range props {
+		add(name, weightText)
+		add(prop.Description, weightText)
+		if prop.Items != nil {
+			addProperties(add, prop.Items.Properties)
+		}
+		addProperties(add, prop.Properties)
+	}
+}
+
+// search ranks the tools against query, best first. Tools sharing no term
+// with the query are left out. module, when set, restricts the search.
+func (idx *searchIndex) search(query, module string, limit int) []ToolMatch {
+	terms := tokenize(query)
+	n := float64(len(idx.docs))
+
+	var matches []ToolMatch
+	for _, doc := range idx.docs {
+		if module != "" && doc.module != module {
+			continue
+		}
+		score := 0.0
+		for _, term := range terms {
+			tf := float64(doc.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(idx.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(doc.length)/idx.avgLength
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 {
+			matches = append(matches, ToolMatch{Module: doc.module, Tool: doc.tool, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Module != matches[j].Module {
+			return matches[i].Module < matches[j].Module
+		}
+		return matches[i].Tool.Name < matches[j].Tool.Name
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// tokenize lowercases text and splits it into terms. Words are split on
+// anything but letters and digits, so snake_case names break apart, and
+// plural "s" is dropped. Japanese runs become character bigrams, since they
+// have no spaces between words.
+func tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			if term := stem(string(word)); !stopWords[term] {
+				terms = append(terms, term)
+			}
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// CompactSchema summarizes a tool's parameters in one line each, e.g.
+// "state": "open|closed|all, default open: Issue state". It is much smaller
+// than the full input schema and is what search results return.
+func CompactSchema(schema InputSchema) map[string]string {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	params := make(map[string]string, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		parts := []string{compactType(prop)}
+		if required[name] {
+			parts = append(parts, "required")
+		}
+		if prop.Default != nil {
+			parts = append(parts, fmt.Sprintf("default %v", normalizeDefault(prop.Default)))
+		}
+		line := strings.Join(parts, ", ")
+		if desc := firstSentence(prop.Description); desc != "" {
+			line += ": " + desc
+		}
+		params[name] = line
+	}
+	return params
+}
+
+func compactType(prop Property) string {
+	switch {
+	case len(prop.Enum) > 0:
+		return strings.Join(prop.Enum, "|")
+	case prop.Type == "array" && prop.Items != nil && prop.Items.Type != "":
+		return "array of " + prop.Items.Type
+	case prop.Type == "":
+		return "any"
+	}
+	return prop.Type
+}
+
+// firstSentence trims a description to its first sentence, at most 120 runes
+func firstSentence(desc string) string {
+	if i := strings.Index(desc, ". "); i >= 0 {
+		desc = desc[:i]
+	}
+	if runes := []rune(desc); len(runes) > 120 {
+		desc = string(runes[:117]) + "..."
+	}
+	return desc
+}
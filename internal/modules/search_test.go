@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"context"
+	"testing"
+)
+
+// notesModule has Japanese descriptions to exercise bigram search
+var notesModule = ModuleDefinition{
+	Name:        "notes",
+	Description: "メモ管理",
+	Tools: []Tool{
+		{
+			Name:        "add_note",
+			Description: "新しいメモを作成する",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"body": {Type: "string", Description: "メモの本文"},
+				},
+				Required: []string{"body"},
+			},
+		},
+	},
+	Handlers: map[string]ToolHandler{
+		"add_note": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return "ok", nil
+		},
+	},
+}
+
+func TestSearchTools_RanksByRelevance(t *testing.T) {
+	registry := newTestRegistry(t, echoModule, schemaModule, typedToolModule)
+
+	matches := registry.SearchTools("list items by state", "", 0)
+	if len(matches) == 0 || matches[0].Module != "schema" || matches[0].Tool.Name != "list" {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if got := CompactSchema(matches[0].Tool.InputSchema)["state"]; got != "open|closed, default open" {
+		t.Errorf("state param = %q", got)
+	}
+}
+
+func TestSearchTools_ModuleFilterAndLimit(t *testing.T) {
+	registry := newTestRegistry(t, echoModule, schemaModule, typedToolModule)
+
+	matches := registry.SearchTools("echo", "typedtool", 0)
+	for _, match := range matches {
+		if match.Module != "typedtool" {
+			t.Errorf("match outside the module filter: %+v", match)
+		}
+	}
+	if len(matches) == 0 {
+		t.Error("expected typedtool echo to match")
+	}
+
+	if matches := registry.SearchTools("echo", "", 1); len(matches) != 1 {
+		t.Errorf("limit 1 returned %d matches", len(matches))
+	}
+	if matches := registry.SearchTools("zzz nothing matches", "", 0); len(matches) != 0 {
+		t.Errorf("no-match query returned %+v", matches)
+	}
+}
+
+func TestSearchTools_FollowsRegistryChanges(t *testing.T) {
+	registry := newTestRegistry(t, echoModule)
+
+	if matches := registry.SearchTools("メモを作成", "", 0); len(matches) != 0 {
+		t.Fatalf("matches before register = %+v", matches)
+	}
+	if err := registry.Register(notesModule); err != nil {
+		t.Fatal(err)
+	}
+	matches := registry.SearchTools("メモを作成", "", 0)
+	if len(matches) != 1 || matches[0].Tool.Name != "add_note" {
+		t.Errorf("matches after register = %+v", matches)
+	}
+	if got := CompactSchema(matches[0].Tool.InputSchema)["body"]; got != "string, required: メモの本文" {
+		t.Errorf("body param = %q", got)
+	}
+
+	registry.Unregister("notes")
+	if matches := registry.SearchTools("メモを作成", "", 0); len(matches) != 0 {
+		t.Errorf("matches after unregister = %+v", matches)
+	}
+}
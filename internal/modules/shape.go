@@ -0,0 +1,508 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reserved params shape a tool's text result after the handler ran. They are
+// stripped before validation, so handlers never see them, and tool schemas
+// must not declare parameters starting with "_".
+const (
+	ParamFields   = "_fields"
+	ParamMaxChars = "_max_chars"
+	ParamOffset   = "_offset"
+	ParamFormat   = "_format"
+	ParamView     = "_view"
+)
+
+// ResultFormats are the accepted _format values
+var ResultFormats = []string{"json", "yaml", "markdown", "csv"}
+
+// ShapeSchema describes the reserved params; ValidateParams checks them
+// against it
+var ShapeSchema = InputSchema{
+	Type: "object",
+	Properties: map[string]Property{
+		ParamFields: {
+			Type:        "string",
+			Description: "残すパスをカンマ区切りで指定（例: \"number,title,user.login\"）。配列は要素ごとに射影",
+		},
+		ParamMaxChars: {
+			Type:        "integer",
+			Description: "結果をこの文字数で切り、続きの取得方法を添える",
+			Minimum:     Min(1),
+		},
+		ParamOffset: {
+			Type:        "integer",
+			Description: "切られた結果の続きを読む開始位置（文字数）",
+			Default:     0,
+			Minimum:     Min(0),
+		},
+		ParamFormat: {
+			Type:        "string",
+			Description: "JSON結果の出力形式",
+			Enum:        ResultFormats,
+			Default:     "json",
+		},
+		ParamView: {
+			Type:        "string",
+			Description: "compact: 対応ツールでは結果を要約、raw: APIの応答をそのまま返す",
+			Enum:        []string{"compact", "raw"},
+		},
+	},
+}
+
+type responseShape struct {
+	fields   [][]string
+	format   string
+	maxChars int
+	offset   int
+	view     string
+}
+
+// compacts reports whether a tool's compactor should run. Without an explicit
+// _view, _fields and _format ask for the raw JSON.
+func (s *responseShape) compacts() bool {
+	if s.view != "" {
+		return s.view == "compact"
+	}
+	return len(s.fields) == 0 && s.format == "json"
+}
+
+// splitShapeParams removes the reserved params from params and parses them
+func splitShapeParams(params map[string]interface{}) (map[string]interface{}, *responseShape, error) {
+	reserved := map[string]interface{}{}
+	rest := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if _, ok := ShapeSchema.Properties[name]; ok {
+			reserved[name] = value
+		} else {
+			rest[name] = value
+		}
+	}
+	if len(reserved) == 0 {
+		return params, &responseShape{format: "json"}, nil
+	}
+
+	validated, err := ValidateParams(ShapeSchema, reserved)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shape := &responseShape{format: validated[ParamFormat].(string)}
+	if fields, _ := validated[ParamFields].(string); fields != "" {
+		for _, path := range strings.Split(fields, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				shape.fields = append(shape.fields, strings.Split(path, "."))
+			}
+		}
+	}
+	if n, ok := validated[ParamMaxChars].(float64); ok {
+		shape.maxChars = int(n)
+	}
+	shape.offset = int(validated[ParamOffset].(float64))
+	shape.view, _ = validated[ParamView].(string)
+	return rest, shape, nil
+}
+
+// apply rewrites a successful single-text result, summarizing it with
+// compact when that is set. StructuredContent gets the same _fields
+// projection, and is dropped when the text became a compact summary or was
+// cut, since it would carry everything the shaping left out. Tools with an
+// outputSchema must return conforming structuredContent, so shaping only
+// rewrites their text and leaves it untouched.
+func (s *responseShape) apply(ctx context.Context, result *ToolCallResult, outputSchema *InputSchema, compact Compactor, params map[string]interface{}) {
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Type != "text" {
+		return
+	}
+	if len(s.fields) > 0 && result.StructuredContent != nil && outputSchema == nil {
+		result.StructuredContent = projectStructured(result.StructuredContent, s.fields)
+	}
+	text := result.Content[0].Text
+	var notes []string
+
+	if compact != nil && s.compacts() {
+		if summary, err := compact(ctx, params, text); err != nil {
+			notes = append(notes, fmt.Sprintf("Compact view failed (%v); showing the raw result.", err))
+		} else {
+			text = summary
+			if outputSchema == nil {
+				result.StructuredContent = nil
+			}
+			notes = append(notes, "Compact view; pass _view=raw for the full API response.")
+		}
+	}
+
+	if len(s.fields) > 0 || s.format != "json" {
+		var value interface{}
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			notes = append(notes, "_fields and _format apply to JSON results only; the text is unchanged.")
+		} else {
+			if len(s.fields) > 0 {
+				value = project(value, s.fields)
+			}
+			formatted, note := formatResult(value, s.format)
+			text = formatted
+			if note != "" {
+				notes = append(notes, note)
+			}
+		}
+	}
+
+	text, note := s.truncate(text)
+	if note != "" {
+		notes = append(notes, note)
+		if outputSchema == nil {
+			result.StructuredContent = nil
+		}
+	}
+
+	result.Content[0].Text = text
+	for _, note := range notes {
+		result.Content = append(result.Content, ContentBlock{Type: "text", Text: note})
+	}
+}
+
+// truncate cuts text to the window [offset, offset+maxChars), counted in
+// characters, and describes how to read on
+func (s *responseShape) truncate(text string) (string, string) {
+	if s.maxChars == 0 && s.offset == 0 {
+		return text, ""
+	}
+
+	runes := []rune(text)
+	total := len(runes)
+	if s.offset >= total && total > 0 {
+		return "", fmt.Sprintf("_offset %d is past the end of the result (%d characters).", s.offset, total)
+	}
+	end := total
+	if s.maxChars > 0 && s.offset+s.maxChars < total {
+		end = s.offset + s.maxChars
+	}
+	if s.offset == 0 && end == total {
+		return text, ""
+	}
+
+	window := string(runes[s.offset:end])
+	if end == total {
+		return window, fmt.Sprintf("Showing characters %d-%d of %d (end of result).", s.offset, end, total)
+	}
+	return window, fmt.Sprintf("Output truncated: showing characters %d-%d of %d. Call again with the same params and _offset=%d to continue, or select less with _fields.",
+		s.offset, end, total, end)
+}
+
+// projectStructured projects structuredContent, whatever Go value the
+// handler built it from
+func projectStructured(structured interface{}, paths [][]string) interface{} {
+	data, err := json.Marshal(structured)
+	if err != nil {
+		return nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	return project(value, paths)
+}
+
+// project keeps only the given paths of value. Objects keep the named keys,
+// arrays apply the paths to every element, and paths that do not exist are
+// dropped.
+func project(value interface{}, paths [][]string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			out = append(out, project(item, paths))
+		}
+		return out
+	case map[string]interface{}:
+		whole := map[string]bool{}
+		nested := map[string][][]string{}
+		for _, path := range paths {
+			if _, ok := v[path[0]]; !ok {
+				continue
+			}
+			if len(path) == 1 {
+				whole[path[0]] = true
+			} else {
+				nested[path[0]] = append(nested[path[0]], path[1:])
+			}
+		}
+
+		out := make(map[string]interface{}, len(whole)+len(nested))
+		for key := range whole {
+			out[key] = v[key]
+		}
+		for key, rest := range nested {
+			if whole[key] {
+				continue
+			}
+			switch child := v[key].(type) {
+			case map[string]interface{}, []interface{}:
+				out[key] = project(child, rest)
+			}
+		}
+		return out
+	}
+	return value
+}
+
+// formatResult renders value in format. The note explains a fallback to JSON.
+func formatResult(value interface{}, format string) (string, string) {
+	switch format {
+	case "yaml":
+		var b strings.Builder
+		writeYAML(&b, value, "")
+		return strings.TrimSuffix(b.String(), "\n"), ""
+	case "markdown":
+		return strings.TrimSuffix(markdownValue(value), "\n"), ""
+	case "csv":
+		if rows, ok := tableRows(value); ok {
+			return csvTable(rows), ""
+		}
+		return indentJSON(value), "_format=csv needs an array of objects; returned JSON instead."
+	}
+	return indentJSON(value), ""
+}
+
+func indentJSON(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// scalarText renders a JSON scalar for a table cell; containers become
+// compact JSON
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return compactJSON(value)
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/.][A-Za-z0-9 _./:+-]*$`)
+
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if !yamlPlain.MatchString(s) || strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(v)
+	}
+	return scalarText(value)
+}
+
+func writeYAML(b *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			b.WriteString(indent + "{}\n")
+			return
+		}
+		for _, key := range sortedKeys(v) {
+			b.WriteString(indent + yamlString(key) + ":")
+			writeYAMLChild(b, v[key], indent+"  ")
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString(indent + "[]\n")
+			return
+		}
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				// Render the item one level deeper, then hang its first line on the dash
+				var child strings.Builder
+				writeYAML(&child, item, indent+"  ")
+				b.WriteString(indent + "- " + strings.TrimPrefix(child.String(), indent+"  "))
+			default:
+				b.WriteString(indent + "- " + yamlScalar(item) + "\n")
+			}
+		}
+	default:
+		b.WriteString(indent + yamlScalar(value) + "\n")
+	}
+}
+
+func writeYAMLChild(b *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+	default:
+		b.WriteString(" " + yamlScalar(value) + "\n")
+		return
+	}
+	b.WriteString("\n")
+	writeYAML(b, value, indent)
+}
+
+// tableRows returns the rows of a tabular value: an array of objects, or an
+// object whose only array-of-objects field holds them
+func tableRows(value interface{}) ([]map[string]interface{}, bool) {
+	if rows, ok := objectArray(value); ok {
+		return rows, true
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	var found []map[string]interface{}
+	count := 0
+	for _, field := range obj {
+		if rows, ok := objectArray(field); ok {
+			found = rows
+			count++
+		}
+	}
+	return found, count == 1
+}
+
+func objectArray(value interface{}) ([]map[string]interface{}, bool) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, false
+	}
+	rows := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		if rows[i], ok = item.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return rows, true
+}
+
+// tableColumns is the union of the rows' keys in order of first appearance
+func tableColumns(rows []map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for _, key := range sortedKeys(row) {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns
+}
+
+func csvTable(rows []map[string]interface{}) string {
+	columns := tableColumns(rows)
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(columns)
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = scalarText(row[column])
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func markdownTable(rows []map[string]interface{}) string {
+	columns := tableColumns(rows)
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, len(columns))
+		for j, column := range columns {
+			cells[i][j] = scalarText(row[column])
+		}
+	}
+	return MarkdownTable(columns, cells)
+}
+
+// MarkdownTable renders rows as a Markdown table. Cells are flattened to one
+// line and pipes are escaped.
+func MarkdownTable(columns []string, rows [][]string) string {
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("---|", len(columns)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = markdownCell(oneLine(cell))
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return b.String()
+}
+
+// markdownValue renders arrays of objects as tables, other arrays as lists
+// and objects as a list of fields followed by a table per array-of-objects
+// field
+func markdownValue(value interface{}) string {
+	if rows, ok := objectArray(value); ok {
+		return markdownTable(rows)
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		var b strings.Builder
+		for _, item := range v {
+			b.WriteString("- " + oneLine(scalarText(item)) + "\n")
+		}
+		return b.String()
+	case map[string]interface{}:
+		var fields, tables strings.Builder
+		for _, key := range sortedKeys(v) {
+			if rows, ok := objectArray(v[key]); ok {
+				fmt.Fprintf(&tables, "\n### %s\n\n%s", key, markdownTable(rows))
+				continue
+			}
+			fmt.Fprintf(&fields, "- **%s**: %s\n", key, oneLine(scalarText(v[key])))
+		}
+		return strings.TrimPrefix(fields.String()+tables.String(), "\n")
+	}
+	return scalarText(value) + "\n"
+}
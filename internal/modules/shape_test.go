@@ -0,0 +1,277 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const issuesJSON = `{
+  "total": 2,
+  "items": [
+    {"number": 1, "title": "First", "state": "open", "user": {"login": "ann", "url": "https://example.com/ann"}},
+    {"number": 2, "title": "Second, with comma", "state": "closed", "user": {"login": "bob", "url": "https://example.com/bob"}}
+  ]
+}`
+
+const issueJSON = `{"number": 1, "title": "First", "state": "open", "user": {"login": "ann", "url": "https://example.com/ann"}}`
+
+// shapeModule returns upstream-like payloads for response shaping tests
+var shapeModule = ModuleDefinition{
+	Name:        "shape",
+	Description: "Response shaping test module",
+	Tools: []Tool{
+		{
+			Name:        "issues",
+			Description: "List issues",
+			InputSchema: InputSchema{Type: "object"},
+		},
+		{
+			Name:        "issue",
+			Description: "Get an issue",
+			InputSchema: InputSchema{Type: "object"},
+			OutputSchema: &InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"number": {Type: "number"},
+					"title":  {Type: "string"},
+					"user":   {Type: "object"},
+				},
+			},
+		},
+		{
+			Name:        "record",
+			Description: "Get an issue with structuredContent but no outputSchema",
+			InputSchema: InputSchema{Type: "object"},
+		},
+		{
+			Name:        "plain",
+			Description: "Return plain text",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"text": {Type: "string"},
+				},
+			},
+		},
+	},
+	Handlers: map[string]ToolHandler{
+		"issue": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return issueJSON, nil
+		},
+		"issues": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			return issuesJSON, nil
+		},
+		"plain": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			if len(params) != 1 {
+				return "", nil
+			}
+			text, _ := params["text"].(string)
+			return text, nil
+		},
+	},
+	ResultHandlers: map[string]ResultHandler{
+		"record": func(ctx context.Context, params map[string]interface{}) (*ToolCallResult, error) {
+			return &ToolCallResult{Content: []ContentBlock{TextContent(issueJSON)}, StructuredContent: json.RawMessage(issueJSON)}, nil
+		},
+	},
+	Compactors: map[string]Compactor{
+		"issue": func(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+			return "#1 First (open)", nil
+		},
+		"issues": func(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+			return "2 issues: #1 First, #2 Second", nil
+		},
+		"plain": func(ctx context.Context, params map[string]interface{}, raw string) (string, error) {
+			return "", fmt.Errorf("not a payload")
+		},
+	},
+}
+
+func TestShape_FieldProjection(t *testing.T) {
+	result := callTool(t, shapeModule, "issues", map[string]interface{}{"_fields": "items.number, items.user.login"})
+
+	want := `{
+  "items": [
+    {
+      "number": 1,
+      "user": {
+        "login": "ann"
+      }
+    },
+    {
+      "number": 2,
+      "user": {
+        "login": "bob"
+      }
+    }
+  ]
+}`
+	if len(result.Content) != 1 || result.Content[0].Text != want {
+		t.Errorf("projected = %+v", result.Content)
+	}
+}
+
+func TestShape_StructuredContentFollowsTheText(t *testing.T) {
+	projected := callTool(t, shapeModule, "record", map[string]interface{}{"_fields": "number,user.login"})
+	structured, err := json.Marshal(projected.StructuredContent)
+	if err != nil || string(structured) != `{"number":1,"user":{"login":"ann"}}` {
+		t.Errorf("projected structuredContent = %s (%v)", structured, err)
+	}
+
+	truncated := callTool(t, shapeModule, "record", map[string]interface{}{"_max_chars": 10})
+	if truncated.StructuredContent != nil {
+		t.Errorf("truncated result kept structuredContent: %+v", truncated.StructuredContent)
+	}
+
+	whole := callTool(t, shapeModule, "issue", map[string]interface{}{"_view": "raw"})
+	if whole.StructuredContent == nil {
+		t.Error("unshaped result lost its structuredContent")
+	}
+
+	// A tool with an outputSchema keeps its structuredContent next to the
+	// compact summary
+	compact := callTool(t, shapeModule, "issue", nil)
+	if compact.Content[0].Text != "#1 First (open)" || compact.StructuredContent == nil {
+		t.Errorf("compact view = %+v", compact)
+	}
+}
+
+// Shaping a tool with an outputSchema rewrites only its text, so its
+// structuredContent still conforms to the schema
+func TestShape_OutputSchemaKeepsStructuredContent(t *testing.T) {
+	want := callTool(t, shapeModule, "issue", map[string]interface{}{"_view": "raw"}).StructuredContent
+
+	projected := callTool(t, shapeModule, "issue", map[string]interface{}{"_fields": "number"})
+	if projected.Content[0].Text != "{\n  \"number\": 1\n}" {
+		t.Errorf("projected text = %q", projected.Content[0].Text)
+	}
+	if !reflect.DeepEqual(projected.StructuredContent, want) {
+		t.Errorf("projected structuredContent = %+v", projected.StructuredContent)
+	}
+
+	truncated := callTool(t, shapeModule, "issue", map[string]interface{}{"_view": "raw", "_max_chars": 10})
+	if len([]rune(truncated.Content[0].Text)) != 10 {
+		t.Errorf("truncated text = %q", truncated.Content[0].Text)
+	}
+	if !reflect.DeepEqual(truncated.StructuredContent, want) {
+		t.Errorf("truncated structuredContent = %+v", truncated.StructuredContent)
+	}
+}
+
+func TestShape_Formats(t *testing.T) {
+	csv := callTool(t, shapeModule, "issues", map[string]interface{}{"_format": "csv", "_fields": "items.number,items.title"})
+	if got := csv.Content[0].Text; got != "number,title\n1,First\n2,\"Second, with comma\"" {
+		t.Errorf("csv = %q", got)
+	}
+
+	yaml := callTool(t, shapeModule, "issues", map[string]interface{}{"_format": "yaml", "_fields": "total,items.title,items.user.url"})
+	want := `items:
+  - title: First
+    user:
+      url: https://example.com/ann
+  - title: "Second, with comma"
+    user:
+      url: https://example.com/bob
+total: 2`
+	if got := yaml.Content[0].Text; got != want {
+		t.Errorf("yaml =\n%s", got)
+	}
+
+	markdown := callTool(t, shapeModule, "issues", map[string]interface{}{"_format": "markdown", "_fields": "total,items.number,items.state"})
+	want = "- **total**: 2\n\n### items\n\n| number | state |\n|---|---|\n| 1 | open |\n| 2 | closed |"
+	if got := markdown.Content[0].Text; got != want {
+		t.Errorf("markdown =\n%s", got)
+	}
+
+	fallback := callTool(t, shapeModule, "issues", map[string]interface{}{"_format": "csv", "_fields": "total"})
+	if len(fallback.Content) != 2 || !strings.Contains(fallback.Content[1].Text, "needs an array of objects") {
+		t.Errorf("csv fallback = %+v", fallback.Content)
+	}
+}
+
+func TestShape_TruncationAndContinuation(t *testing.T) {
+	first := callTool(t, shapeModule, "plain", map[string]interface{}{"text": "あいうえおかきくけこ", "_max_chars": 4, "_view": "raw"})
+	if first.Content[0].Text != "あいうえ" || len(first.Content) != 2 || !strings.Contains(first.Content[1].Text, "_offset=4") {
+		t.Fatalf("first window = %+v", first.Content)
+	}
+
+	next := callTool(t, shapeModule, "plain", map[string]interface{}{"text": "あいうえおかきくけこ", "_max_chars": "4", "_offset": 8, "_view": "raw"})
+	if next.Content[0].Text != "けこ" || !strings.Contains(next.Content[1].Text, "end of result") {
+		t.Errorf("last window = %+v", next.Content)
+	}
+
+	whole := callTool(t, shapeModule, "plain", map[string]interface{}{"text": "short", "_max_chars": 100, "_view": "raw"})
+	if len(whole.Content) != 1 || whole.Content[0].Text != "short" {
+		t.Errorf("untruncated = %+v", whole.Content)
+	}
+}
+
+func TestShape_ReservedParamsAreStrippedAndValidated(t *testing.T) {
+	// plain returns "" unless it sees exactly its own param
+	result := callTool(t, shapeModule, "plain", map[string]interface{}{"text": "hello", "_format": "yaml", "_view": "raw"})
+	if result.Content[0].Text != "hello" || !strings.Contains(result.Content[1].Text, "JSON results only") {
+		t.Errorf("plain = %+v", result.Content)
+	}
+
+	result = callTool(t, shapeModule, "issues", map[string]interface{}{"_format": "xml", "_max_chars": 0})
+	if !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+	verr := result.StructuredContent.(*ValidationError)
+	if len(verr.Errors) != 2 || verr.Errors[0].Field != "_format" || verr.Errors[1].Field != "_max_chars" {
+		t.Errorf("errors = %+v", verr.Errors)
+	}
+}
+
+func TestShape_CompactView(t *testing.T) {
+	compact := callTool(t, shapeModule, "issues", nil)
+	if compact.Content[0].Text != "2 issues: #1 First, #2 Second" || !strings.Contains(compact.Content[1].Text, "_view=raw") {
+		t.Errorf("default view = %+v", compact.Content)
+	}
+
+	raw := callTool(t, shapeModule, "issues", map[string]interface{}{"_view": "raw"})
+	if len(raw.Content) != 1 || raw.Content[0].Text != issuesJSON {
+		t.Errorf("raw view = %+v", raw.Content)
+	}
+
+	// _fields works on the raw JSON unless the compact view is asked for
+	projected := callTool(t, shapeModule, "issues", map[string]interface{}{"_fields": "total"})
+	if projected.Content[0].Text != "{\n  \"total\": 2\n}" {
+		t.Errorf("projected = %+v", projected.Content)
+	}
+	truncated := callTool(t, shapeModule, "issues", map[string]interface{}{"_view": "compact", "_max_chars": 8})
+	if truncated.Content[0].Text != "2 issues" || len(truncated.Content) != 3 {
+		t.Errorf("truncated compact = %+v", truncated.Content)
+	}
+
+	failed := callTool(t, shapeModule, "plain", map[string]interface{}{"text": "hello"})
+	if failed.IsError || failed.Content[0].Text != "hello" || !strings.Contains(failed.Content[1].Text, "not a payload") {
+		t.Errorf("failed compactor = %+v", failed.Content)
+	}
+}
+
+func TestCheckModule_RejectsReservedParamNames(t *testing.T) {
+	module := ModuleDefinition{
+		Name:        "reserved",
+		Description: "Declares a reserved param",
+		Tools: []Tool{{
+			Name:        "t",
+			Description: "Tool",
+			InputSchema: InputSchema{
+				Type:       "object",
+				Properties: map[string]Property{"_fields": {Type: "string"}},
+			},
+		}},
+		Handlers: map[string]ToolHandler{
+			"t": func(ctx context.Context, params map[string]interface{}) (string, error) { return "", nil },
+		},
+	}
+	err := CheckModule(module)
+	if err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("CheckModule = %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package supabase
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -12,7 +13,10 @@ import (
 
 const supabaseAPIBase = "https://api.supabase.com/v1"
 
-var client = httpclient.New()
+var client = httpclient.New(httpclient.WithUpstream("supabase"))
+
+// rateLimit follows the Management API's 120 requests per minute per user
+var rateLimit = httpclient.RateLimit{Rate: 2, Burst: 5}
 
 func getAccessToken() string {
 	return os.Getenv("SUPABASE_ACCESS_TOKEN")
@@ -27,310 +31,111 @@ func headers() map[string]string {
 // Module returns the Supabase module definition
 func Module() modules.ModuleDefinition {
 	return modules.ModuleDefinition{
-		Name:        "supabase",
-		Description: "Supabase Management API - プロジェクト管理、DB操作、マイグレーション、ログ、ストレージ",
-		APIVersion:  "v1",
-		TestedAt:    "2026-01-10",
-		Tools:       tools,
-		Handlers:    handlers,
-	}
+		Name:              "supabase",
+		Description:       "Supabase Management API - プロジェクト管理、DB操作、マイグレーション、ログ、ストレージ",
+		APIVersion:        "v1",
+		TestedAt:          "2026-01-10",
+		RateLimit:         &rateLimit,
+		ResourceTemplates: resourceTemplates,
+		ResourceHandlers:  resourceHandlers,
+		Completers:        completers,
+	}.WithTools(tools...)
 }
 
-var tools = []modules.Tool{
+var tools = []modules.TypedTool{
 	// Account Tools
-	{
-		Name:        "list_organizations",
-		Description: "List all organizations you have access to.",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
-	},
-	{
-		Name:        "list_projects",
-		Description: "List all Supabase projects you have access to. Use this first to get project_ref for other operations.",
-		InputSchema: modules.InputSchema{
-			Type:       "object",
-			Properties: map[string]modules.Property{},
-		},
-	},
-	{
-		Name:        "get_project",
-		Description: "Get details of a specific project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference (e.g., 'abcdefghijk'). Get from list_projects.",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
+	modules.NewTool("list_organizations", "List all organizations you have access to.", listOrganizations),
+	modules.NewTool("list_projects", "List all Supabase projects you have access to. Use this first to get project_ref for other operations.", listProjects),
+	modules.NewTool("get_project", "Get details of a specific project.", getProject),
 	// Database Tools
-	{
-		Name:        "list_tables",
-		Description: "List all tables in the database with their schemas. Returns table names and column counts.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-				"schemas": {
-					Type:        "array",
-					Description: "Schemas to include (default: ['public'])",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "run_query",
-		Description: "Execute a SQL query against the database. Supports both read and write operations.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-				"query": {
-					Type:        "string",
-					Description: "SQL query to execute",
-				},
-			},
-			Required: []string{"project_ref", "query"},
-		},
-	},
-	{
-		Name:        "list_migrations",
-		Description: "List all database migrations that have been applied.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "apply_migration",
-		Description: "Apply a new database migration. Use for DDL operations like CREATE TABLE, ALTER TABLE, etc.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-				"name": {
-					Type:        "string",
-					Description: "Migration name in snake_case (e.g., add_users_table)",
-				},
-				"query": {
-					Type:        "string",
-					Description: "SQL DDL statements to apply",
-				},
-			},
-			Required: []string{"project_ref", "name", "query"},
-		},
-	},
+	modules.NewTool("list_tables", "List all tables in the database with their schemas. Returns table names and column counts.", listTables),
+	modules.NewTool("run_query", "Execute a SQL query against the database. Supports both read and write operations.", runQuery),
+	modules.NewTool("list_migrations", "List all database migrations that have been applied.", listMigrations),
+	modules.NewTool("apply_migration", "Apply a new database migration. Use for DDL operations like CREATE TABLE, ALTER TABLE, etc.", applyMigration),
 	// Debugging Tools
-	{
-		Name:        "get_logs",
-		Description: "Get logs for a specific service. Available services: api, postgres, edge-function, auth, storage, realtime.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-				"service": {
-					Type:        "string",
-					Description: "Service to get logs for: api, postgres, edge-function, auth, storage, realtime",
-				},
-				"start_time": {
-					Type:        "string",
-					Description: "ISO timestamp for start of log range (optional)",
-				},
-				"end_time": {
-					Type:        "string",
-					Description: "ISO timestamp for end of log range (optional)",
-				},
-			},
-			Required: []string{"project_ref", "service"},
-		},
-	},
-	{
-		Name:        "get_security_advisors",
-		Description: "Get security recommendations and potential issues for the project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "get_performance_advisors",
-		Description: "Get performance recommendations and potential issues for the project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
+	modules.NewTool("get_logs", "Get logs for a specific service. Available services: api, postgres, edge-function, auth, storage, realtime.", getLogs),
+	modules.NewTool("get_security_advisors", "Get security recommendations and potential issues for the project.", getSecurityAdvisors),
+	modules.NewTool("get_performance_advisors", "Get performance recommendations and potential issues for the project.", getPerformanceAdvisors),
 	// Development Tools
-	{
-		Name:        "get_project_url",
-		Description: "Get the base URL for a Supabase project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "get_api_keys",
-		Description: "Get the API keys for the project (anon key and service role key).",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "generate_typescript_types",
-		Description: "Generate TypeScript type definitions from the database schema.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
+	modules.NewTool("get_project_url", "Get the base URL for a Supabase project.", getProjectURL),
+	modules.NewTool("get_api_keys", "Get the API keys for the project (anon key and service role key).", getAPIKeys),
+	modules.NewTool("generate_typescript_types", "Generate TypeScript type definitions from the database schema.", generateTypescriptTypes),
 	// Edge Function Tools
-	{
-		Name:        "list_edge_functions",
-		Description: "List all Edge Functions deployed in the project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "get_edge_function",
-		Description: "Get details of a specific Edge Function.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-				"slug": {
-					Type:        "string",
-					Description: "The slug/name of the Edge Function",
-				},
-			},
-			Required: []string{"project_ref", "slug"},
-		},
-	},
+	modules.NewTool("list_edge_functions", "List all Edge Functions deployed in the project.", listEdgeFunctions),
+	modules.NewTool("get_edge_function", "Get details of a specific Edge Function.", getEdgeFunction),
 	// Storage Tools
+	modules.NewTool("list_storage_buckets", "List all storage buckets in the project.", listStorageBuckets),
+	modules.NewTool("get_storage_config", "Get storage configuration for the project including file size limits and features.", getStorageConfig),
+}
+
+var resourceTemplates = []modules.ResourceTemplate{
 	{
-		Name:        "list_storage_buckets",
-		Description: "List all storage buckets in the project.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
-	},
-	{
-		Name:        "get_storage_config",
-		Description: "Get storage configuration for the project including file size limits and features.",
-		InputSchema: modules.InputSchema{
-			Type: "object",
-			Properties: map[string]modules.Property{
-				"project_ref": {
-					Type:        "string",
-					Description: "Project reference",
-				},
-			},
-			Required: []string{"project_ref"},
-		},
+		URITemplate: "supabase://{project_ref}/types",
+		Name:        "types",
+		Description: "TypeScript type definitions generated from the database schema",
+		MimeType:    "application/typescript",
 	},
 }
 
-var handlers = map[string]modules.ToolHandler{
-	"list_organizations":        listOrganizations,
-	"list_projects":             listProjects,
-	"get_project":               getProject,
-	"list_tables":               listTables,
-	"run_query":                 runQuery,
-	"list_migrations":           listMigrations,
-	"apply_migration":           applyMigration,
-	"get_logs":                  getLogs,
-	"get_security_advisors":     getSecurityAdvisors,
-	"get_performance_advisors":  getPerformanceAdvisors,
-	"get_project_url":           getProjectURL,
-	"get_api_keys":              getAPIKeys,
-	"generate_typescript_types": generateTypescriptTypes,
-	"list_edge_functions":       listEdgeFunctions,
-	"get_edge_function":         getEdgeFunction,
-	"list_storage_buckets":      listStorageBuckets,
-	"get_storage_config":        getStorageConfig,
+var resourceHandlers = map[string]modules.ResourceHandler{
+	"types": readTypesResource,
+}
+
+var completers = map[string]modules.Completer{
+	"project_ref": completeProjectRef,
+}
+
+// =============================================================================
+// Params
+// =============================================================================
+
+type noParams struct{}
+
+// projectParams identifies a project; it is embedded in every project tool
+type projectParams struct {
+	ProjectRef string `json:"project_ref" jsonschema:"required" description:"Project reference"`
+}
+
+type getProjectParams struct {
+	ProjectRef string `json:"project_ref" jsonschema:"required" description:"Project reference (e.g., 'abcdefghijk'). Get from list_projects."`
+}
+
+type listTablesParams struct {
+	projectParams
+	Schemas []string `json:"schemas,omitempty" jsonschema:"default=public" description:"Schemas to include (default: ['public'])"`
+}
+
+type runQueryParams struct {
+	projectParams
+	Query string `json:"query" jsonschema:"required" description:"SQL query to execute"`
+}
+
+type applyMigrationParams struct {
+	projectParams
+	Name  string `json:"name" jsonschema:"required" description:"Migration name in snake_case (e.g., add_users_table)"`
+	Query string `json:"query" jsonschema:"required" description:"SQL DDL statements to apply"`
+}
+
+type getLogsParams struct {
+	projectParams
+	Service   string `json:"service" jsonschema:"required,enum=api|postgres|edge-function|auth|storage|realtime" description:"Service to get logs for: api, postgres, edge-function, auth, storage, realtime"`
+	StartTime string `json:"start_time,omitempty" jsonschema:"format=date-time" description:"ISO timestamp for start of log range (optional)"`
+	EndTime   string `json:"end_time,omitempty" jsonschema:"format=date-time" description:"ISO timestamp for end of log range (optional)"`
+}
+
+type edgeFunctionParams struct {
+	projectParams
+	Slug string `json:"slug" jsonschema:"required" description:"The slug/name of the Edge Function"`
 }
 
 // =============================================================================
 // Account Tools
 // =============================================================================
 
-func listOrganizations(params map[string]interface{}) (string, error) {
+func listOrganizations(ctx context.Context, params noParams) (string, error) {
 	endpoint := supabaseAPIBase + "/organizations"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -338,10 +143,10 @@ func listOrganizations(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listProjects(params map[string]interface{}) (string, error) {
+func listProjects(ctx context.Context, params noParams) (string, error) {
 	endpoint := supabaseAPIBase + "/projects"
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -349,15 +154,10 @@ func listProjects(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getProject(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s", supabaseAPIBase, projectRef)
+func getProject(ctx context.Context, params getProjectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s", supabaseAPIBase, params.ProjectRef)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -369,21 +169,10 @@ func getProject(params map[string]interface{}) (string, error) {
 // Database Tools
 // =============================================================================
 
-func listTables(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	// Default schemas
-	schemas := []string{"public"}
-	if s, ok := params["schemas"].([]interface{}); ok && len(s) > 0 {
-		schemas = make([]string, 0, len(s))
-		for _, schema := range s {
-			if str, ok := schema.(string); ok {
-				schemas = append(schemas, str)
-			}
-		}
+func listTables(ctx context.Context, params listTablesParams) (string, error) {
+	schemas := params.Schemas
+	if len(schemas) == 0 {
+		schemas = []string{"public"}
 	}
 
 	// Build query to list tables
@@ -403,29 +192,19 @@ func listTables(params map[string]interface{}) (string, error) {
 		ORDER BY schemaname, tablename
 	`, strings.Join(schemaList, ","))
 
-	return executeQuery(projectRef, query)
+	return executeQuery(ctx, params.ProjectRef, query)
 }
 
-func runQuery(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	query, ok := params["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query must be a string")
-	}
-
-	return executeQuery(projectRef, query)
+func runQuery(ctx context.Context, params runQueryParams) (string, error) {
+	return executeQuery(ctx, params.ProjectRef, params.Query)
 }
 
-func executeQuery(projectRef, query string) (string, error) {
+func executeQuery(ctx context.Context, projectRef, query string) (string, error) {
 	endpoint := fmt.Sprintf("%s/projects/%s/database/query", supabaseAPIBase, projectRef)
 
 	payload := map[string]string{"query": query}
 
-	respBody, err := client.DoJSON("POST", endpoint, headers(), payload)
+	respBody, err := client.DoJSON(ctx, "POST", endpoint, headers(), payload)
 	if err != nil {
 		return "", err
 	}
@@ -433,12 +212,7 @@ func executeQuery(projectRef, query string) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func listMigrations(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
+func listMigrations(ctx context.Context, params projectParams) (string, error) {
 	// Query to list migrations from supabase_migrations schema
 	query := `
 		SELECT version, name, executed_at
@@ -446,49 +220,24 @@ func listMigrations(params map[string]interface{}) (string, error) {
 		ORDER BY version DESC
 	`
 
-	return executeQuery(projectRef, query)
+	return executeQuery(ctx, params.ProjectRef, query)
 }
 
-func applyMigration(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	name, ok := params["name"].(string)
-	if !ok {
-		return "", fmt.Errorf("name must be a string")
-	}
-
-	query, ok := params["query"].(string)
-	if !ok {
-		return "", fmt.Errorf("query must be a string")
-	}
-
+func applyMigration(ctx context.Context, params applyMigrationParams) (string, error) {
 	// Execute the migration
-	_, err := executeQuery(projectRef, query)
+	_, err := executeQuery(ctx, params.ProjectRef, params.Query)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf(`{"success": true, "migration": "%s"}`, name), nil
+	return fmt.Sprintf(`{"success": true, "migration": "%s"}`, params.Name), nil
 }
 
 // =============================================================================
 // Debugging Tools
 // =============================================================================
 
-func getLogs(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	service, ok := params["service"].(string)
-	if !ok {
-		return "", fmt.Errorf("service must be a string")
-	}
-
+func getLogs(ctx context.Context, params getLogsParams) (string, error) {
 	// Map service names to log collection names
 	serviceMap := map[string]string{
 		"api":           "api_logs",
@@ -499,25 +248,25 @@ func getLogs(params map[string]interface{}) (string, error) {
 		"realtime":      "realtime_logs",
 	}
 
-	collection, exists := serviceMap[service]
+	collection, exists := serviceMap[params.Service]
 	if !exists {
-		return "", fmt.Errorf("invalid service: %s. Valid services: api, postgres, edge-function, auth, storage, realtime", service)
+		return "", fmt.Errorf("invalid service: %s. Valid services: api, postgres, edge-function, auth, storage, realtime", params.Service)
 	}
 
 	query := url.Values{}
 	query.Set("collection", collection)
 
-	if startTime, ok := params["start_time"].(string); ok && startTime != "" {
-		query.Set("start", startTime)
+	if params.StartTime != "" {
+		query.Set("start", params.StartTime)
 	}
 
-	if endTime, ok := params["end_time"].(string); ok && endTime != "" {
-		query.Set("end", endTime)
+	if params.EndTime != "" {
+		query.Set("end", params.EndTime)
 	}
 
-	endpoint := fmt.Sprintf("%s/projects/%s/analytics/endpoints/logs.all?%s", supabaseAPIBase, projectRef, query.Encode())
+	endpoint := fmt.Sprintf("%s/projects/%s/analytics/endpoints/logs.all?%s", supabaseAPIBase, params.ProjectRef, query.Encode())
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -525,15 +274,10 @@ func getLogs(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getSecurityAdvisors(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
+func getSecurityAdvisors(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/advisors/security", supabaseAPIBase, params.ProjectRef)
 
-	endpoint := fmt.Sprintf("%s/projects/%s/advisors/security", supabaseAPIBase, projectRef)
-
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -541,15 +285,10 @@ func getSecurityAdvisors(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getPerformanceAdvisors(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/advisors/performance", supabaseAPIBase, projectRef)
+func getPerformanceAdvisors(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/advisors/performance", supabaseAPIBase, params.ProjectRef)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -561,26 +300,16 @@ func getPerformanceAdvisors(params map[string]interface{}) (string, error) {
 // Development Tools
 // =============================================================================
 
-func getProjectURL(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	projectURL := fmt.Sprintf("https://%s.supabase.co", projectRef)
+func getProjectURL(ctx context.Context, params projectParams) (string, error) {
+	projectURL := fmt.Sprintf("https://%s.supabase.co", params.ProjectRef)
 
 	return fmt.Sprintf(`{"url": "%s"}`, projectURL), nil
 }
 
-func getAPIKeys(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/api-keys", supabaseAPIBase, projectRef)
+func getAPIKeys(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/api-keys", supabaseAPIBase, params.ProjectRef)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -588,18 +317,16 @@ func getAPIKeys(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func generateTypescriptTypes(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/types/typescript", supabaseAPIBase, projectRef)
+func generateTypescriptTypes(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/types/typescript", supabaseAPIBase, params.ProjectRef)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	// Type generation introspects the whole schema and can take a while
+	modules.ReportProgress(ctx, 0, 1, "Generating TypeScript types")
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
+	modules.ReportProgress(ctx, 1, 1, "TypeScript types generated")
 
 	return httpclient.PrettyJSON(respBody), nil
 }
@@ -608,15 +335,10 @@ func generateTypescriptTypes(params map[string]interface{}) (string, error) {
 // Edge Function Tools
 // =============================================================================
 
-func listEdgeFunctions(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/functions", supabaseAPIBase, projectRef)
+func listEdgeFunctions(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/functions", supabaseAPIBase, params.ProjectRef)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -624,20 +346,10 @@ func listEdgeFunctions(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getEdgeFunction(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
-	}
-
-	slug, ok := params["slug"].(string)
-	if !ok {
-		return "", fmt.Errorf("slug must be a string")
-	}
-
-	endpoint := fmt.Sprintf("%s/projects/%s/functions/%s", supabaseAPIBase, projectRef, slug)
+func getEdgeFunction(ctx context.Context, params edgeFunctionParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/functions/%s", supabaseAPIBase, params.ProjectRef, params.Slug)
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -649,15 +361,21 @@ func getEdgeFunction(params map[string]interface{}) (string, error) {
 // Storage Tools
 // =============================================================================
 
-func listStorageBuckets(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
+func listStorageBuckets(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/storage/buckets", supabaseAPIBase, params.ProjectRef)
+
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
+	if err != nil {
+		return "", err
 	}
 
-	endpoint := fmt.Sprintf("%s/projects/%s/storage/buckets", supabaseAPIBase, projectRef)
+	return httpclient.PrettyJSON(respBody), nil
+}
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+func getStorageConfig(ctx context.Context, params projectParams) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/config/storage", supabaseAPIBase, params.ProjectRef)
+
+	respBody, err := client.DoJSON(ctx, "GET", endpoint, headers(), nil)
 	if err != nil {
 		return "", err
 	}
@@ -665,18 +383,56 @@ func listStorageBuckets(params map[string]interface{}) (string, error) {
 	return httpclient.PrettyJSON(respBody), nil
 }
 
-func getStorageConfig(params map[string]interface{}) (string, error) {
-	projectRef, ok := params["project_ref"].(string)
-	if !ok {
-		return "", fmt.Errorf("project_ref must be a string")
+// =============================================================================
+// Resources
+// =============================================================================
+
+func readTypesResource(ctx context.Context, vars map[string]string) ([]modules.ResourceContents, error) {
+	text, err := generateTypescriptTypes(ctx, projectParams{ProjectRef: vars["project_ref"]})
+	if err != nil {
+		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("%s/projects/%s/config/storage", supabaseAPIBase, projectRef)
+	var result struct {
+		Types string `json:"types"`
+	}
+	if err := httpclient.UnmarshalJSON([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse types: %w", err)
+	}
+
+	return []modules.ResourceContents{{
+		URI:      "supabase://" + vars["project_ref"] + "/types",
+		MimeType: "application/typescript",
+		Text:     result.Types,
+	}}, nil
+}
+
+// =============================================================================
+// Completion
+// =============================================================================
 
-	respBody, err := client.DoJSON("GET", endpoint, headers(), nil)
+func completeProjectRef(ctx context.Context, args map[string]string) ([]string, error) {
+	text, err := listProjects(ctx, noParams{})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return httpclient.PrettyJSON(respBody), nil
+	var projects []struct {
+		ID  string `json:"id"`
+		Ref string `json:"ref"`
+	}
+	if err := httpclient.UnmarshalJSON([]byte(text), &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+
+	refs := make([]string, 0, len(projects))
+	for _, project := range projects {
+		// Older responses only carry the ref as id
+		if project.Ref != "" {
+			refs = append(refs, project.Ref)
+		} else {
+			refs = append(refs, project.ID)
+		}
+	}
+	return refs, nil
 }
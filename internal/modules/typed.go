@@ -0,0 +1,240 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedTool is a tool definition paired with its handler, built by NewTool
+type TypedTool struct {
+	Tool    Tool
+	Handler ResultHandler
+}
+
+// NewTool builds a tool whose input schema is derived from the fields of P
+// and whose handler receives params already decoded into P.
+//
+// Each exported field of P becomes a property named by its json tag. The
+// description tag sets the property description and the jsonschema tag holds
+// comma-separated keywords:
+//
+//	type listParams struct {
+//		Owner   string `json:"owner" jsonschema:"required" description:"Repository owner"`
+//		State   string `json:"state,omitempty" jsonschema:"enum=open|closed,default=open"`
+//		PerPage int    `json:"per_page,omitempty" jsonschema:"default=30,minimum=1,maximum=100"`
+//	}
+//
+// Supported keywords are required, enum, default, format, minimum, maximum,
+// minItems and maxItems. Enum values and array defaults are separated by |.
+//
+// The result R is returned as JSON text and, when it encodes to a JSON
+// object, as structuredContent too. A struct R also sets the tool's
+// OutputSchema. A string R is returned as plain text and a *ToolCallResult R
+// is returned as is.
+//
+// NewTool panics on malformed tags, since they are programming errors found
+// at registration.
+func NewTool[P, R any](name, description string, fn func(ctx context.Context, params P) (R, error)) TypedTool {
+	input := schemaFor(reflect.TypeOf((*P)(nil)).Elem())
+	tool := Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: InputSchema{Type: "object", Properties: input.Properties, Required: input.Required},
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]Property{}
+	}
+
+	if out := indirect(reflect.TypeOf((*R)(nil)).Elem()); out.Kind() == reflect.Struct && out != reflect.TypeOf(ToolCallResult{}) && out != timeType {
+		output := schemaFor(out)
+		tool.OutputSchema = &InputSchema{Type: "object", Properties: output.Properties, Required: output.Required}
+	}
+
+	handler := func(ctx context.Context, raw map[string]interface{}) (*ToolCallResult, error) {
+		var params P
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+
+		result, err := fn(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return typedResult(result)
+	}
+
+	return TypedTool{Tool: tool, Handler: handler}
+}
+
+// WithTools returns a copy of the module with the typed tools added to its
+// Tools and ResultHandlers
+func (m ModuleDefinition) WithTools(tools ...TypedTool) ModuleDefinition {
+	resultHandlers := make(map[string]ResultHandler, len(m.ResultHandlers)+len(tools))
+	for name, handler := range m.ResultHandlers {
+		resultHandlers[name] = handler
+	}
+	defs := make([]Tool, 0, len(m.Tools)+len(tools))
+	defs = append(defs, m.Tools...)
+	for _, t := range tools {
+		defs = append(defs, t.Tool)
+		resultHandlers[t.Tool.Name] = t.Handler
+	}
+	m.Tools = defs
+	m.ResultHandlers = resultHandlers
+	return m
+}
+
+func typedResult(result interface{}) (*ToolCallResult, error) {
+	switch r := result.(type) {
+	case *ToolCallResult:
+		return r, nil
+	case string:
+		return &ToolCallResult{Content: []ContentBlock{TextContent(r)}}, nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	out := &ToolCallResult{Content: []ContentBlock{TextContent(string(data))}}
+	if len(data) > 0 && data[0] == '{' {
+		out.StructuredContent = json.RawMessage(data)
+	}
+	return out, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// schemaFor derives the property describing values of type t
+func schemaFor(t reflect.Type) Property {
+	t = indirect(t)
+	if t == timeType {
+		return Property{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Property{Type: "string"}
+	case reflect.Bool:
+		return Property{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Property{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Property{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte and json.RawMessage hold arbitrary JSON or base64
+			return Property{}
+		}
+		items := schemaFor(t.Elem())
+		return Property{Type: "array", Items: &items}
+	case reflect.Map:
+		return Property{Type: "object"}
+	case reflect.Struct:
+		prop := Property{Type: "object", Properties: map[string]Property{}}
+		addFields(&prop, t)
+		return prop
+	}
+	return Property{}
+}
+
+func addFields(prop *Property, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			addFields(prop, indirect(field.Type))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		p := schemaFor(field.Type)
+		p.Description = field.Tag.Get("description")
+		if applySchemaTag(&p, field.Tag.Get("jsonschema")) {
+			prop.Required = append(prop.Required, name)
+		}
+		prop.Properties[name] = p
+	}
+}
+
+// applySchemaTag applies the jsonschema tag keywords to p and reports
+// whether the field is required
+func applySchemaTag(p *Property, tag string) bool {
+	required := false
+	if tag == "" {
+		return false
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "required":
+			required = true
+		case "enum":
+			p.Enum = strings.Split(value, "|")
+		case "format":
+			p.Format = value
+		case "default":
+			p.Default = parseTagValue(p.Type, value)
+		case "minimum":
+			p.Minimum = Min(parseTagFloat(key, value))
+		case "maximum":
+			p.Maximum = Max(parseTagFloat(key, value))
+		case "minItems":
+			p.MinItems = Count(int(parseTagFloat(key, value)))
+		case "maxItems":
+			p.MaxItems = Count(int(parseTagFloat(key, value)))
+		default:
+			panic(fmt.Sprintf("modules: unknown jsonschema keyword %q", key))
+		}
+	}
+	return required
+}
+
+func parseTagValue(typ, value string) interface{} {
+	switch typ {
+	case "integer", "number":
+		return parseTagFloat("default", value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			panic(fmt.Sprintf("modules: invalid boolean default %q", value))
+		}
+		return b
+	case "array":
+		return strings.Split(value, "|")
+	}
+	return value
+}
+
+func parseTagFloat(key, value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("modules: invalid %s %q", key, value))
+	}
+	return f
+}
@@ -0,0 +1,143 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type pageParams struct {
+	Page int `json:"page,omitempty" jsonschema:"default=1,minimum=1"`
+}
+
+type greetParams struct {
+	pageParams
+	Name  string   `json:"name" jsonschema:"required" description:"Who to greet"`
+	Tone  string   `json:"tone,omitempty" jsonschema:"enum=plain|loud,default=plain"`
+	Times int      `json:"times,omitempty" jsonschema:"default=1,maximum=3"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"maxItems=2,default=hi|there"`
+	Skip  string   `json:"-"`
+}
+
+type greetResult struct {
+	Greeting string `json:"greeting" jsonschema:"required" description:"The greeting"`
+	Page     int    `json:"page"`
+}
+
+// typedToolModule is built from typed tools
+var typedToolModule = ModuleDefinition{
+	Name:        "typedtool",
+	Description: "Typed tool test module",
+}.WithTools(
+	NewTool("greet", "Greet someone", func(ctx context.Context, p greetParams) (greetResult, error) {
+		greeting := "hello " + p.Name
+		if p.Tone == "loud" {
+			greeting += "!"
+		}
+		return greetResult{Greeting: fmt.Sprintf("%s x%d", greeting, p.Times), Page: p.Page}, nil
+	}),
+	NewTool("echo", "Echo text", func(ctx context.Context, p struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return p.Text, nil
+	}),
+	NewTool("fail", "Always fails", func(ctx context.Context, p struct{}) (*ToolCallResult, error) {
+		return nil, fmt.Errorf("boom")
+	}),
+	NewTool("none", "Returns no result", func(ctx context.Context, p struct{}) (*ToolCallResult, error) {
+		return nil, nil
+	}),
+)
+
+func TestNewTool_DerivesSchemaFromTags(t *testing.T) {
+	tool := typedToolModule.Tools[0]
+	props := tool.InputSchema.Properties
+
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "name" {
+		t.Errorf("required = %v", tool.InputSchema.Required)
+	}
+	if props["name"].Type != "string" || props["name"].Description != "Who to greet" {
+		t.Errorf("name = %+v", props["name"])
+	}
+	if len(props["tone"].Enum) != 2 || props["tone"].Default != "plain" {
+		t.Errorf("tone = %+v", props["tone"])
+	}
+	if props["times"].Type != "integer" || *props["times"].Maximum != 3 {
+		t.Errorf("times = %+v", props["times"])
+	}
+	if props["tags"].Items == nil || props["tags"].Items.Type != "string" || *props["tags"].MaxItems != 2 ||
+		fmt.Sprint(props["tags"].Default) != "[hi there]" {
+		t.Errorf("tags = %+v", props["tags"])
+	}
+	if _, ok := props["page"]; !ok {
+		t.Error("embedded struct fields should be flattened")
+	}
+	if _, ok := props["Skip"]; ok {
+		t.Error(`json:"-" fields should be skipped`)
+	}
+	if tool.OutputSchema == nil || tool.OutputSchema.Properties["greeting"].Type != "string" {
+		t.Errorf("output schema = %+v", tool.OutputSchema)
+	}
+}
+
+func TestNewTool_DecodesParamsAndReturnsStructuredContent(t *testing.T) {
+	result := callTool(t, typedToolModule, "greet", map[string]interface{}{"name": "ann", "times": "2"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.Content[0].Text)
+	}
+
+	var got greetResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatalf("text is not JSON: %v", err)
+	}
+	if got.Greeting != "hello ann x2" || got.Page != 1 {
+		t.Errorf("result = %+v", got)
+	}
+
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil || string(structured) != `{"greeting":"hello ann x2","page":1}` {
+		t.Errorf("structuredContent = %s (%v)", structured, err)
+	}
+}
+
+func TestNewTool_ValidatesAgainstDerivedSchema(t *testing.T) {
+	result := callTool(t, typedToolModule, "greet", map[string]interface{}{"tone": "quiet", "times": 5})
+	if !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+	verr := result.StructuredContent.(*ValidationError)
+	if len(verr.Errors) != 3 {
+		t.Errorf("errors = %+v", verr.Errors)
+	}
+}
+
+func TestNewTool_StringAndErrorResults(t *testing.T) {
+	result := callTool(t, typedToolModule, "echo", map[string]interface{}{"text": "hi"})
+	if result.Content[0].Text != "hi" || result.StructuredContent != nil {
+		t.Errorf("echo = %+v", result)
+	}
+
+	result = callTool(t, typedToolModule, "fail", nil)
+	if !result.IsError || result.Content[0].Text != "boom" {
+		t.Errorf("fail = %+v", result)
+	}
+
+	result = callTool(t, typedToolModule, "none", nil)
+	if !result.IsError || result.Content[0].Text != "tool none returned no result" {
+		t.Errorf("none = %+v", result)
+	}
+}
+
+func TestNewTool_PanicsOnUnknownKeyword(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	NewTool("bad", "Bad tag", func(ctx context.Context, p struct {
+		X string `json:"x" jsonschema:"pattern=^a"`
+	}) (string, error) {
+		return "", nil
+	})
+}
@@ -1,10 +1,29 @@
 package modules
 
+import (
+	"context"
+
+	"github.com/shibaleo/go-mcp-dev/internal/httpclient"
+)
+
 // Tool represents an MCP tool definition
 type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
 	InputSchema InputSchema `json:"inputSchema"`
+	// OutputSchema describes the structuredContent of the tool's result (MCP 2025-06-18+)
+	OutputSchema *InputSchema     `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are behavioral hints about a tool (MCP 2025-03-26+).
+// Nil hints are omitted so clients fall back to the spec defaults.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 // InputSchema defines the input parameters for a tool
@@ -14,10 +33,40 @@ type InputSchema struct {
 	Required   []string            `json:"required,omitempty"`
 }
 
-// Property defines a single property in the input schema
+// Property defines a single property in the input schema. It covers the
+// JSON Schema subset MCP clients understand: enums, formats, defaults,
+// numeric bounds, array items and nested objects.
 type Property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string   `json:"type,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	// Format is a JSON Schema format such as "date-time", "date" or "uri"
+	Format   string      `json:"format,omitempty"`
+	Default  interface{} `json:"default,omitempty"`
+	Minimum  *float64    `json:"minimum,omitempty"`
+	Maximum  *float64    `json:"maximum,omitempty"`
+	MinItems *int        `json:"minItems,omitempty"`
+	MaxItems *int        `json:"maxItems,omitempty"`
+	// Items describes the elements of an array
+	Items *Property `json:"items,omitempty"`
+	// Properties and Required describe the fields of an object
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Min returns a pointer for Property.Minimum
+func Min(v float64) *float64 {
+	return &v
+}
+
+// Max returns a pointer for Property.Maximum
+func Max(v float64) *float64 {
+	return &v
+}
+
+// Count returns a pointer for Property.MinItems and Property.MaxItems
+func Count(n int) *int {
+	return &n
 }
 
 // ModuleDefinition defines a module with its tools and handlers.
@@ -39,19 +88,79 @@ type ModuleDefinition struct {
 	TestedAt    string
 	Tools       []Tool
 	Handlers    map[string]ToolHandler
+	// ResultHandlers serve tools that return more than text, such as images
+	// or embedded resources. A tool has either a Handler or a ResultHandler.
+	ResultHandlers map[string]ResultHandler
+	// Compactors turn a tool's raw result into a dense summary, keyed by tool
+	// name. They run unless the caller passes _view=raw.
+	Compactors map[string]Compactor
+	// RateLimit caps the requests of all sessions to the module's upstream.
+	// Register installs it for clients created with
+	// httpclient.WithUpstream(Name), and Unregister drops it once no
+	// registry holds the module.
+	RateLimit *httpclient.RateLimit
+	// Paginated marks tools that accept _all and _max_items, keyed by tool
+	// name; set it with WithPagination
+	Paginated map[string]bool
+
+	// ResourceTemplates expose module entities as resources. URIs use the
+	// module name as scheme, e.g. "jira://issue/{key}".
+	ResourceTemplates []ResourceTemplate
+	// ResourceHandlers read resources, keyed by ResourceTemplate.Name
+	ResourceHandlers map[string]ResourceHandler
+	// ListResources optionally lists concrete resources, such as recent documents
+	ListResources func(ctx context.Context) ([]Resource, error)
+
+	// Prompts are parameterized workflows offered to the user
+	Prompts []Prompt
+	// PromptHandlers render prompts, keyed by Prompt.Name
+	PromptHandlers map[string]PromptHandler
+	// Completers suggest values for prompt and resource template arguments,
+	// keyed by argument name
+	Completers map[string]Completer
 }
 
-// ToolHandler executes a tool with given parameters
-type ToolHandler func(params map[string]interface{}) (string, error)
+// ToolHandler executes a tool with given parameters.
+// ctx is cancelled when the client cancels the request or disconnects.
+type ToolHandler func(ctx context.Context, params map[string]interface{}) (string, error)
+
+// ResultHandler executes a tool and builds its full result
+type ResultHandler func(ctx context.Context, params map[string]interface{}) (*ToolCallResult, error)
+
+// Compactor summarizes raw, the text a tool's handler returned, for the
+// model. params are the validated params of the call; a compactor may use
+// them and ctx to fetch related data. On error the raw text is returned.
+type Compactor func(ctx context.Context, params map[string]interface{}, raw string) (string, error)
 
 // ToolCallResult represents the result of a tool call
 type ToolCallResult struct {
 	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
+	// StructuredContent is the machine-readable result matching Tool.OutputSchema
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
 }
 
-// ContentBlock represents a content block in the result
+// ContentBlock represents a content block in the result.
+// Which fields are set depends on Type:
+//   - "text": Text
+//   - "image": Data (base64) and MimeType
+//   - "resource": Resource (embedded resource contents)
+//   - "resource_link": URI, Name, and optionally Description and MimeType
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type        string            `json:"type"`
+	Text        string            `json:"text,omitempty"`
+	Data        string            `json:"data,omitempty"`
+	MimeType    string            `json:"mimeType,omitempty"`
+	Resource    *ResourceContents `json:"resource,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// ResourceContents is the content of a resource, as text or base64 blob
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
 }
@@ -0,0 +1,287 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a single parameter that failed schema validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every parameter that failed schema validation
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, "Invalid params:")
+	for _, fe := range e.Errors {
+		lines = append(lines, fmt.Sprintf("- %s: %s", fe.Field, fe.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validationErrorResult reports a validation failure as a tool error so the
+// model can correct its call. The field errors are also returned as
+// structuredContent for clients that want to highlight them.
+func validationErrorResult(err error) *ToolCallResult {
+	result := &ToolCallResult{
+		Content: []ContentBlock{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+	if verr, ok := err.(*ValidationError); ok {
+		result.StructuredContent = verr
+	}
+	return result
+}
+
+// dateTimeLayouts are the ISO 8601 forms accepted for format "date-time".
+// Jira documents offsets without a colon, so those are accepted too.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05-0700",
+}
+
+// ValidateParams checks params against schema and returns a copy with
+// defaults applied and safe coercions done: numeric and boolean strings
+// become numbers and booleans. Numbers are float64, as if decoded from JSON,
+// so handlers keep working with their usual type assertions. Parameters the
+// schema does not declare are passed through untouched.
+func ValidateParams(schema InputSchema, params map[string]interface{}) (map[string]interface{}, error) {
+	v := &validator{}
+	out := v.object("", schema.Properties, schema.Required, params)
+	if len(v.errors) > 0 {
+		return nil, &ValidationError{Errors: v.errors}
+	}
+	return out, nil
+}
+
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) fail(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) object(path string, props map[string]Property, required []string, obj map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for name, value := range obj {
+		out[name] = value
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := props[name]
+		value, ok := out[name]
+		if !ok || value == nil {
+			if prop.Default != nil {
+				out[name] = normalizeDefault(prop.Default)
+			}
+			continue
+		}
+		out[name] = v.value(joinField(path, name), prop, value)
+	}
+
+	for _, name := range required {
+		if value, ok := out[name]; !ok || value == nil {
+			v.fail(joinField(path, name), "is required")
+		}
+	}
+	return out
+}
+
+func (v *validator) value(field string, prop Property, value interface{}) interface{} {
+	switch prop.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(field, "must be a string")
+			return value
+		}
+		// An empty string means "not set" to the handlers, so it is not
+		// held to the enum or format
+		if s == "" {
+			return s
+		}
+		if len(prop.Enum) > 0 && !contains(prop.Enum, s) {
+			v.fail(field, "must be one of: %s", strings.Join(prop.Enum, ", "))
+		}
+		if msg := checkFormat(prop.Format, s); msg != "" {
+			v.fail(field, "%s", msg)
+		}
+		return s
+
+	case "integer", "number":
+		n, ok := toNumber(value)
+		if !ok {
+			v.fail(field, "must be a %s", prop.Type)
+			return value
+		}
+		if prop.Type == "integer" && n != math.Trunc(n) {
+			v.fail(field, "must be an integer")
+			return value
+		}
+		if prop.Minimum != nil && n < *prop.Minimum {
+			v.fail(field, "must be at least %s", formatNumber(*prop.Minimum))
+		}
+		if prop.Maximum != nil && n > *prop.Maximum {
+			v.fail(field, "must be at most %s", formatNumber(*prop.Maximum))
+		}
+		return n
+
+	case "boolean":
+		switch b := value.(type) {
+		case bool:
+			return b
+		case string:
+			switch strings.ToLower(strings.TrimSpace(b)) {
+			case "true":
+				return true
+			case "false":
+				return false
+			}
+		}
+		v.fail(field, "must be a boolean")
+		return value
+
+	case "array":
+		items, ok := toArray(value)
+		if !ok {
+			v.fail(field, "must be an array")
+			return value
+		}
+		if prop.MinItems != nil && len(items) < *prop.MinItems {
+			v.fail(field, "must contain at least %d items", *prop.MinItems)
+		}
+		if prop.MaxItems != nil && len(items) > *prop.MaxItems {
+			v.fail(field, "must contain at most %d items", *prop.MaxItems)
+		}
+		if prop.Items != nil {
+			for i, item := range items {
+				items[i] = v.value(fmt.Sprintf("%s[%d]", field, i), *prop.Items, item)
+			}
+		}
+		return items
+
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(field, "must be an object")
+			return value
+		}
+		if len(prop.Properties) == 0 && len(prop.Required) == 0 {
+			return obj
+		}
+		return v.object(field, prop.Properties, prop.Required, obj)
+	}
+	return value
+}
+
+func checkFormat(format, s string) string {
+	switch format {
+	case "date-time":
+		for _, layout := range dateTimeLayouts {
+			if _, err := time.Parse(layout, s); err == nil {
+				return ""
+			}
+		}
+		return "must be an ISO 8601 date-time (e.g. 2024-01-15T10:00:00Z)"
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return "must be an absolute URI"
+		}
+	}
+	return ""
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// toArray returns a copy of value as []interface{} so coerced items never
+// write through to the caller's params
+func toArray(value interface{}) ([]interface{}, bool) {
+	switch a := value.(type) {
+	case []interface{}:
+		return append([]interface{}(nil), a...), true
+	case []string:
+		items := make([]interface{}, len(a))
+		for i, s := range a {
+			items[i] = s
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// normalizeDefault turns a Go default such as 30 or []string{"public"} into
+// the shape a handler would see for the same value decoded from JSON
+func normalizeDefault(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
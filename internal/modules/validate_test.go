@@ -0,0 +1,120 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// schemaModule has a tool using the richer JSON Schema keywords
+var schemaModule = ModuleDefinition{
+	Name:        "schema",
+	Description: "Schema test module",
+	Tools: []Tool{
+		{
+			Name:        "list",
+			Description: "List items",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"state": {Type: "string", Enum: []string{"open", "closed"}, Default: "open"},
+					"limit": {Type: "integer", Default: 30, Minimum: Min(1), Maximum: Max(100)},
+					"since": {Type: "string", Format: "date-time"},
+					"labels": {
+						Type:     "array",
+						Items:    &Property{Type: "string"},
+						MinItems: Count(1),
+					},
+					"sort": {
+						Type: "array",
+						Items: &Property{
+							Type: "object",
+							Properties: map[string]Property{
+								"field": {Type: "string"},
+								"desc":  {Type: "boolean", Default: false},
+							},
+							Required: []string{"field"},
+						},
+					},
+				},
+			},
+		},
+	},
+	Handlers: map[string]ToolHandler{
+		"list": func(ctx context.Context, params map[string]interface{}) (string, error) {
+			out, err := json.Marshal(params)
+			return string(out), err
+		},
+	},
+}
+
+func TestCallModuleTool_CoercesAndAppliesDefaults(t *testing.T) {
+	result := callTool(t, schemaModule, "list", map[string]interface{}{
+		"limit": "30",
+		"sort":  []interface{}{map[string]interface{}{"field": "name", "desc": "true"}},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.Content[0].Text)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatalf("handler output: %v", err)
+	}
+	if got["limit"] != 30.0 {
+		t.Errorf("limit = %#v, want 30", got["limit"])
+	}
+	if got["state"] != "open" {
+		t.Errorf("state default not applied: %#v", got["state"])
+	}
+	sort := got["sort"].([]interface{})[0].(map[string]interface{})
+	if sort["desc"] != true {
+		t.Errorf("nested boolean not coerced: %#v", sort["desc"])
+	}
+}
+
+func TestCallModuleTool_FieldLevelErrors(t *testing.T) {
+	result := callTool(t, schemaModule, "list", map[string]interface{}{
+		"state":  "merged",
+		"limit":  500,
+		"since":  "yesterday",
+		"labels": []interface{}{},
+		"sort":   []interface{}{map[string]interface{}{"desc": false}},
+	})
+	if !result.IsError {
+		t.Fatal("expected a validation error")
+	}
+
+	verr, ok := result.StructuredContent.(*ValidationError)
+	if !ok {
+		t.Fatalf("structuredContent = %#v", result.StructuredContent)
+	}
+	fields := map[string]string{}
+	for _, fe := range verr.Errors {
+		fields[fe.Field] = fe.Message
+	}
+	for _, field := range []string{"state", "limit", "since", "labels", "sort[0].field"} {
+		if fields[field] == "" {
+			t.Errorf("missing error for %s in %v", field, verr.Errors)
+		}
+	}
+	if fields["limit"] != "must be at most 100" {
+		t.Errorf("limit message = %q", fields["limit"])
+	}
+}
+
+func TestValidateParams_RejectsNonNumericString(t *testing.T) {
+	schema := InputSchema{
+		Type:       "object",
+		Properties: map[string]Property{"count": {Type: "integer"}},
+		Required:   []string{"name"},
+	}
+	_, err := ValidateParams(schema, map[string]interface{}{"count": "thirty"})
+	verr, ok := err.(*ValidationError)
+	if !ok || len(verr.Errors) != 2 {
+		t.Fatalf("err = %v", err)
+	}
+	if verr.Errors[0].Field != "count" || verr.Errors[1].Message != "is required" {
+		t.Errorf("errors = %+v", verr.Errors)
+	}
+}